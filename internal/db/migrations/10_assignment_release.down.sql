@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_assignments_pending_release;
+ALTER TABLE assignments DROP COLUMN IF EXISTS published_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS is_draft;
@@ -0,0 +1,9 @@
+-- Черновики и отложенная публикация заданий
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+
+-- Существующие задания уже видны студентам — считаем их опубликованными
+UPDATE assignments SET published_at = COALESCE(created_at, NOW()) WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_assignments_pending_release ON assignments(publish_at) WHERE published_at IS NULL;
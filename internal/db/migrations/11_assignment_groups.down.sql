@@ -0,0 +1,8 @@
+ALTER TABLE grades DROP COLUMN IF EXISTS adjustment;
+ALTER TABLE grades DROP COLUMN IF EXISTS group_id;
+DROP INDEX IF EXISTS uniq_assignment_submissions_group;
+ALTER TABLE assignment_submissions DROP COLUMN IF EXISTS group_id;
+DROP TABLE IF EXISTS assignment_group_members;
+DROP TABLE IF EXISTS assignment_groups;
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_group_size;
+ALTER TABLE assignments DROP COLUMN IF EXISTS group_mode;
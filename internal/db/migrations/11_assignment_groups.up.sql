@@ -0,0 +1,32 @@
+-- Групповые задания
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS group_mode VARCHAR(20) NOT NULL DEFAULT 'none';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_group_size INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS assignment_groups (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    name          VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_groups_assignment_id ON assignment_groups(assignment_id);
+
+-- Студент может состоять только в одной группе в рамках задания
+CREATE TABLE IF NOT EXISTS assignment_group_members (
+    group_id      INTEGER NOT NULL REFERENCES assignment_groups(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    joined_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, student_id),
+    UNIQUE (assignment_id, student_id)
+);
+
+-- Общая работа группы: одна строка на группу
+ALTER TABLE assignment_submissions ADD COLUMN IF NOT EXISTS group_id INTEGER REFERENCES assignment_groups(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_assignment_submissions_group
+    ON assignment_submissions(assignment_id, group_id) WHERE group_id IS NOT NULL AND deleted_at IS NULL;
+
+-- Оценка группы раздаётся каждому участнику с индивидуальной поправкой
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS group_id INTEGER REFERENCES assignment_groups(id) ON DELETE SET NULL;
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS adjustment FLOAT NOT NULL DEFAULT 0;
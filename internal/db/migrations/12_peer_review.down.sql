@@ -0,0 +1,3 @@
+ALTER TABLE grades DROP COLUMN IF EXISTS peer_score;
+DROP TABLE IF EXISTS peer_reviews;
+DROP TABLE IF EXISTS peer_review_settings;
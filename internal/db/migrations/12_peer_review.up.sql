@@ -0,0 +1,32 @@
+-- Взаимное рецензирование эссе (peer review)
+CREATE TABLE IF NOT EXISTS peer_review_settings (
+    assignment_id            INTEGER PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    enabled                  BOOLEAN NOT NULL DEFAULT TRUE,
+    reviewers_per_submission INTEGER NOT NULL DEFAULT 2,
+    review_due_date          TIMESTAMP NOT NULL,
+    blend_weight             FLOAT NOT NULL DEFAULT 0, -- доля средней peer-оценки в итоговой (0..1)
+    allocated_at             TIMESTAMP,
+    created_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS peer_reviews (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    submission_id INTEGER NOT NULL REFERENCES assignment_submissions(id) ON DELETE CASCADE,
+    reviewer_id   INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending',
+    scores        TEXT NOT NULL DEFAULT '[]',
+    comment       TEXT NOT NULL DEFAULT '',
+    total_score   FLOAT NOT NULL DEFAULT 0,
+    submitted_at  TIMESTAMP,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (submission_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_peer_reviews_reviewer_id ON peer_reviews(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_peer_reviews_assignment_id ON peer_reviews(assignment_id);
+
+-- Средняя peer-оценка, учтённая в итоговой
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS peer_score FLOAT;
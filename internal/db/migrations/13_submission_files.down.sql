@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_attachments_target;
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_file_size_mb;
+ALTER TABLE assignments DROP COLUMN IF EXISTS allowed_extensions;
+ALTER TABLE assignments DROP COLUMN IF EXISTS allow_attachments;
@@ -0,0 +1,6 @@
+-- Файловые ответы студентов (тип задания "file" и вложения к эссе)
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS allow_attachments BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS allowed_extensions VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_file_size_mb INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_attachments_target ON attachments(target_type, target_id);
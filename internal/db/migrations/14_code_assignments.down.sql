@@ -0,0 +1,4 @@
+ALTER TABLE assignment_submissions DROP COLUMN IF EXISTS test_results;
+ALTER TABLE assignments DROP COLUMN IF EXISTS test_cases;
+ALTER TABLE assignments DROP COLUMN IF EXISTS starter_code;
+ALTER TABLE assignments DROP COLUMN IF EXISTS code_language;
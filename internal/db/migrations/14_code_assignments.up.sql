@@ -0,0 +1,6 @@
+-- Задания с кодом: язык, стартовый код, тест-кейсы; результаты прогона в submission
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS code_language VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS starter_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS test_cases TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE assignment_submissions ADD COLUMN IF NOT EXISTS test_results TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Неизменяемая история версий ответа (каждое сохранение черновика и сдача)
+CREATE TABLE IF NOT EXISTS submission_revisions (
+    id            SERIAL PRIMARY KEY,
+    submission_id INTEGER NOT NULL REFERENCES assignment_submissions(id) ON DELETE CASCADE,
+    number        INTEGER NOT NULL,
+    author_id     INTEGER NOT NULL REFERENCES users(id),
+    kind          VARCHAR(20) NOT NULL, -- 'draft' | 'submit'
+    content       TEXT NOT NULL DEFAULT '',
+    answers       TEXT NOT NULL DEFAULT '',
+    word_count    INTEGER NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (submission_id, number)
+);
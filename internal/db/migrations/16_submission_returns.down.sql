@@ -0,0 +1,4 @@
+ALTER TABLE grades DROP COLUMN IF EXISTS revision_number;
+DROP TABLE IF EXISTS submission_returns;
+ALTER TABLE assignment_submissions DROP COLUMN IF EXISTS return_due_date;
+ALTER TABLE assignment_submissions DROP COLUMN IF EXISTS round;
@@ -0,0 +1,20 @@
+-- Возврат работы на доработку: раунды сдачи и новый срок
+ALTER TABLE assignment_submissions ADD COLUMN IF NOT EXISTS round INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE assignment_submissions ADD COLUMN IF NOT EXISTS return_due_date TIMESTAMP NULL;
+
+CREATE TABLE IF NOT EXISTS submission_returns (
+    id                   SERIAL PRIMARY KEY,
+    submission_id        INTEGER NOT NULL REFERENCES assignment_submissions(id) ON DELETE CASCADE,
+    round                INTEGER NOT NULL,
+    teacher_id           INTEGER NOT NULL REFERENCES users(id),
+    comment              TEXT NOT NULL DEFAULT '',
+    due_date             TIMESTAMP NOT NULL,
+    revision_number      INTEGER NULL,     -- версия, которую вернули
+    resubmitted_revision INTEGER NULL,     -- версия повторной сдачи
+    resubmitted_at       TIMESTAMP NULL,
+    created_at           TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (submission_id, round)
+);
+
+-- Итоговая оценка ссылается на версию, по которой она выставлена
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS revision_number INTEGER NULL;
@@ -0,0 +1,16 @@
+-- Комментарии преподавателя к фрагментам ответа и ответы в ветках
+CREATE TABLE IF NOT EXISTS submission_annotations (
+    id              SERIAL PRIMARY KEY,
+    submission_id   INTEGER NOT NULL REFERENCES assignment_submissions(id) ON DELETE CASCADE,
+    parent_id       INTEGER NULL REFERENCES submission_annotations(id) ON DELETE CASCADE, -- NULL — корень ветки
+    author_id       INTEGER NOT NULL REFERENCES users(id),
+    revision_number INTEGER NULL,           -- версия текста, к которой привязан диапазон
+    start_offset    INTEGER NOT NULL DEFAULT 0,
+    end_offset      INTEGER NOT NULL DEFAULT 0,
+    quote           TEXT NOT NULL DEFAULT '',
+    body            TEXT NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_annotations_submission_id ON submission_annotations(submission_id);
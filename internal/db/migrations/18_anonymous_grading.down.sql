@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS assignment_pseudonyms;
+ALTER TABLE assignments DROP COLUMN IF EXISTS deanonymized_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS anonymous_grading;
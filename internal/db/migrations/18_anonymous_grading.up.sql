@@ -0,0 +1,12 @@
+-- Анонимная проверка: преподаватель видит псевдонимы вместо студентов до снятия анонимности
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS anonymous_grading BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS deanonymized_at TIMESTAMP NULL;
+
+-- Стабильные псевдонимы студентов в рамках задания
+CREATE TABLE IF NOT EXISTS assignment_pseudonyms (
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pseudonym     VARCHAR(32) NOT NULL,
+    PRIMARY KEY (assignment_id, student_id),
+    UNIQUE (assignment_id, pseudonym)
+);
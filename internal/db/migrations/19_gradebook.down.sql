@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS gradebook_exemptions;
+ALTER TABLE assignments DROP COLUMN IF EXISTS gradebook_category_id;
+DROP TABLE IF EXISTS gradebook_categories;
@@ -0,0 +1,24 @@
+-- Журнал курса: категории заданий с весами и правилом отбрасывания худших
+CREATE TABLE IF NOT EXISTS gradebook_categories (
+    id          SERIAL PRIMARY KEY,
+    course_id   INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    name        VARCHAR(100) NOT NULL,
+    weight      FLOAT NOT NULL,
+    drop_lowest INTEGER NOT NULL DEFAULT 0,
+    position    INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (course_id, name)
+);
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS gradebook_category_id INTEGER REFERENCES gradebook_categories(id) ON DELETE SET NULL;
+
+-- Освобождённые ячейки: задание не учитывается в итоговой оценке студента
+CREATE TABLE IF NOT EXISTS gradebook_exemptions (
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason        TEXT NOT NULL DEFAULT '',
+    created_by    INTEGER NOT NULL REFERENCES users(id),
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (assignment_id, student_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE courses DROP COLUMN IF EXISTS grading_scale_id;
+DROP TABLE IF EXISTS grading_scales;
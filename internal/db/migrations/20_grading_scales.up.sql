@@ -0,0 +1,31 @@
+-- Шкалы оценивания: процент → символ (буква, 5-балльная, зачёт)
+-- owner_id NULL — шкала организации (создаёт администратор), иначе — личная шкала преподавателя
+CREATE TABLE IF NOT EXISTS grading_scales (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    kind       VARCHAR(20) NOT NULL DEFAULT 'custom',
+    owner_id   INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    levels     TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_grading_scales_owner_id ON grading_scales(owner_id);
+
+ALTER TABLE courses ADD COLUMN IF NOT EXISTS grading_scale_id INTEGER REFERENCES grading_scales(id) ON DELETE SET NULL;
+
+-- Стандартные шкалы организации
+INSERT INTO grading_scales (name, kind, levels)
+SELECT 'Letter (A–F, GPA)', 'letter',
+       '[{"symbol":"A","min_percent":95,"points":4,"passing":true},{"symbol":"A-","min_percent":90,"points":3.67,"passing":true},{"symbol":"B+","min_percent":85,"points":3.33,"passing":true},{"symbol":"B","min_percent":80,"points":3,"passing":true},{"symbol":"B-","min_percent":75,"points":2.67,"passing":true},{"symbol":"C+","min_percent":70,"points":2.33,"passing":true},{"symbol":"C","min_percent":65,"points":2,"passing":true},{"symbol":"C-","min_percent":60,"points":1.67,"passing":true},{"symbol":"D+","min_percent":55,"points":1.33,"passing":true},{"symbol":"D","min_percent":50,"points":1,"passing":true},{"symbol":"F","min_percent":0,"points":0,"passing":false}]'
+WHERE NOT EXISTS (SELECT 1 FROM grading_scales WHERE owner_id IS NULL AND kind = 'letter');
+
+INSERT INTO grading_scales (name, kind, levels)
+SELECT '5-балльная', 'five_point',
+       '[{"symbol":"5","min_percent":85,"points":5,"passing":true},{"symbol":"4","min_percent":70,"points":4,"passing":true},{"symbol":"3","min_percent":50,"points":3,"passing":true},{"symbol":"2","min_percent":0,"points":2,"passing":false}]'
+WHERE NOT EXISTS (SELECT 1 FROM grading_scales WHERE owner_id IS NULL AND kind = 'five_point');
+
+INSERT INTO grading_scales (name, kind, levels)
+SELECT 'Pass/Fail', 'pass_fail',
+       '[{"symbol":"Pass","min_percent":50,"passing":true},{"symbol":"Fail","min_percent":0,"passing":false}]'
+WHERE NOT EXISTS (SELECT 1 FROM grading_scales WHERE owner_id IS NULL AND kind = 'pass_fail');
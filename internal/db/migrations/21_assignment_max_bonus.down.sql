@@ -0,0 +1 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_bonus;
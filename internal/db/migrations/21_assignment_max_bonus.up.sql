@@ -0,0 +1,2 @@
+-- Бонусные баллы сверх max_score: оценка проверяется в пределах 0..max_score + max_bonus
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS max_bonus FLOAT NOT NULL DEFAULT 0;
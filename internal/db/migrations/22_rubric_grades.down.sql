@@ -0,0 +1,2 @@
+ALTER TABLE assignment_submissions DROP COLUMN IF EXISTS ai_rubric;
+DROP TABLE IF EXISTS grade_criterion_scores;
@@ -0,0 +1,17 @@
+-- Оценка по рубрике: балл, уровень и комментарий по каждому критерию
+CREATE TABLE IF NOT EXISTS grade_criterion_scores (
+    grade_id       INTEGER NOT NULL REFERENCES grades(id) ON DELETE CASCADE,
+    criterion_id   INTEGER NOT NULL,
+    assignment_id  INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    criterion_name VARCHAR(255) NOT NULL DEFAULT '',
+    score          FLOAT NOT NULL,
+    max_score      FLOAT NOT NULL,
+    level          VARCHAR(100) NOT NULL DEFAULT '',
+    comment        TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (grade_id, criterion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_grade_criterion_scores_assignment_id ON grade_criterion_scores(assignment_id);
+
+-- Разбор по критериям от AI (ai_evaluate с submission_id) — подсказка для преподавателя
+ALTER TABLE assignment_submissions ADD COLUMN IF NOT EXISTS ai_rubric TEXT;
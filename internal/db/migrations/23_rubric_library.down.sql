@@ -0,0 +1,4 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS rubric_version;
+ALTER TABLE assignments DROP COLUMN IF EXISTS rubric_id;
+DROP TABLE IF EXISTS rubric_versions;
+DROP TABLE IF EXISTS rubrics;
@@ -0,0 +1,31 @@
+-- Библиотека рубрик: рубрика преподавателя (можно открыть для всей организации) и её версии
+CREATE TABLE IF NOT EXISTS rubrics (
+    id              SERIAL PRIMARY KEY,
+    owner_id        INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title           VARCHAR(200) NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    is_public       BOOLEAN NOT NULL DEFAULT FALSE,
+    current_version INTEGER NOT NULL DEFAULT 1,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at      TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rubrics_owner_id ON rubrics(owner_id);
+CREATE INDEX IF NOT EXISTS idx_rubrics_deleted_at ON rubrics(deleted_at);
+
+-- Версии неизменяемы: задание ссылается на конкретную версию
+CREATE TABLE IF NOT EXISTS rubric_versions (
+    id         SERIAL PRIMARY KEY,
+    rubric_id  INTEGER NOT NULL REFERENCES rubrics(id) ON DELETE CASCADE,
+    version    INTEGER NOT NULL,
+    criteria   TEXT NOT NULL,
+    note       VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (rubric_id, version)
+);
+
+-- Критерии задания остаются копией; rubric_id/rubric_version — откуда они взяты
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rubric_id INTEGER REFERENCES rubrics(id) ON DELETE SET NULL;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rubric_version INTEGER;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_assignments_grades_release_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS grades_released_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS grades_release_at;
+ALTER TABLE assignments DROP COLUMN IF EXISTS grade_release_mode;
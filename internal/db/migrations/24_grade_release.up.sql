@@ -0,0 +1,7 @@
+-- Публикация оценок: immediate — видны сразу, manual — после кнопки «Опубликовать», scheduled — в grades_release_at
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS grade_release_mode VARCHAR(20) NOT NULL DEFAULT 'immediate';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS grades_release_at TIMESTAMP;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS grades_released_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_assignments_grades_release_at ON assignments(grades_release_at)
+    WHERE grade_release_mode = 'scheduled' AND grades_released_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS grade_history_no_change ON grade_history;
+DROP FUNCTION IF EXISTS grade_history_append_only();
+DROP TABLE IF EXISTS grade_history;
@@ -0,0 +1,30 @@
+-- Журнал изменений оценок (append-only): кто, когда, старый/новый балл, причина
+CREATE TABLE IF NOT EXISTS grade_history (
+    id            SERIAL PRIMARY KEY,
+    grade_id      INTEGER NOT NULL,
+    assignment_id INTEGER NOT NULL,
+    student_id    INTEGER NOT NULL,
+    action        VARCHAR(20) NOT NULL,
+    old_score     FLOAT,
+    new_score     FLOAT,
+    old_feedback  TEXT NOT NULL DEFAULT '',
+    new_feedback  TEXT NOT NULL DEFAULT '',
+    reason        TEXT NOT NULL DEFAULT '',
+    changed_by    INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_grade_history_grade_id ON grade_history(grade_id);
+CREATE INDEX IF NOT EXISTS idx_grade_history_assignment_id ON grade_history(assignment_id);
+
+-- Записи журнала нельзя менять или удалять
+CREATE OR REPLACE FUNCTION grade_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'grade_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS grade_history_no_change ON grade_history;
+CREATE TRIGGER grade_history_no_change
+    BEFORE UPDATE OR DELETE ON grade_history
+    FOR EACH ROW EXECUTE FUNCTION grade_history_append_only();
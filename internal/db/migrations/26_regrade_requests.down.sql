@@ -0,0 +1,2 @@
+ALTER TABLE assignments DROP COLUMN IF EXISTS regrade_window_days;
+DROP TABLE IF EXISTS regrade_requests;
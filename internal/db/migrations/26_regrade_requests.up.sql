@@ -0,0 +1,28 @@
+-- Апелляции студентов на оценку (запросы на перепроверку)
+CREATE TABLE IF NOT EXISTS regrade_requests (
+    id            SERIAL PRIMARY KEY,
+    grade_id      INTEGER NOT NULL REFERENCES grades(id) ON DELETE CASCADE,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    criterion_id  INTEGER,
+    question_id   INTEGER,
+    justification TEXT NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reply         TEXT NOT NULL DEFAULT '',
+    old_score     FLOAT NOT NULL DEFAULT 0,
+    new_score     FLOAT,
+    resolved_by   INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at   TIMESTAMP,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_regrade_requests_assignment ON regrade_requests(assignment_id, status);
+CREATE INDEX IF NOT EXISTS idx_regrade_requests_student ON regrade_requests(student_id);
+
+-- Одновременно по оценке может быть только один открытый запрос
+CREATE UNIQUE INDEX IF NOT EXISTS idx_regrade_requests_pending
+    ON regrade_requests(grade_id) WHERE status = 'pending';
+
+-- Сколько дней после публикации оценки студент может подать запрос (0 — запросы закрыты)
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS regrade_window_days INTEGER NOT NULL DEFAULT 7;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS moderation_cases;
+DROP TABLE IF EXISTS moderation_marks;
+DROP TABLE IF EXISTS moderation_settings;
@@ -0,0 +1,41 @@
+-- Двойная проверка: настройки задания, оценки двух проверяющих и решения модератора
+CREATE TABLE IF NOT EXISTS moderation_settings (
+    assignment_id    INTEGER PRIMARY KEY REFERENCES assignments(id) ON DELETE CASCADE,
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    first_marker_id  INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    second_marker_id INTEGER NOT NULL REFERENCES users(id),
+    threshold        FLOAT NOT NULL DEFAULT 10,
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS moderation_marks (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    marker_id     INTEGER NOT NULL REFERENCES users(id),
+    role          VARCHAR(10) NOT NULL,
+    score         FLOAT NOT NULL,
+    feedback      TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (assignment_id, student_id, role)
+);
+
+CREATE TABLE IF NOT EXISTS moderation_cases (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending',
+    difference    FLOAT,
+    final_score   FLOAT,
+    grade_id      INTEGER,
+    moderator_id  INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    note          TEXT NOT NULL DEFAULT '',
+    resolved_at   TIMESTAMP,
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (assignment_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_cases_status ON moderation_cases(assignment_id, status);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ai_quotas;
+DROP TABLE IF EXISTS ai_usage;
@@ -0,0 +1,32 @@
+-- Учёт токенов LLM по пользователю, курсу и функции
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    course_id         INTEGER REFERENCES courses(id) ON DELETE SET NULL,
+    feature           VARCHAR(32) NOT NULL DEFAULT '',
+    provider          VARCHAR(32) NOT NULL DEFAULT '',
+    model             VARCHAR(255) NOT NULL DEFAULT '',
+    prompt_tokens     INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens      INTEGER NOT NULL DEFAULT 0,
+    estimated         BOOLEAN NOT NULL DEFAULT FALSE, -- провайдер не вернул usage, посчитано по длине текста
+    created_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_user ON ai_usage(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_course ON ai_usage(course_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created ON ai_usage(created_at);
+
+-- Лимиты токенов: по роли (user_id IS NULL) или персонально для пользователя. 0 — без лимита
+CREATE TABLE IF NOT EXISTS ai_quotas (
+    id             SERIAL PRIMARY KEY,
+    role           VARCHAR(10),
+    user_id        INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    daily_tokens   BIGINT NOT NULL DEFAULT 0,
+    monthly_tokens BIGINT NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((role IS NULL) <> (user_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_quotas_role ON ai_quotas(role) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_quotas_user ON ai_quotas(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS assignment_extensions;
+ALTER TABLE grades DROP COLUMN IF EXISTS late_penalty;
+ALTER TABLE assignments DROP COLUMN IF EXISTS close_date;
+ALTER TABLE assignments DROP COLUMN IF EXISTS late_penalty;
+ALTER TABLE assignments DROP COLUMN IF EXISTS late_policy;
@@ -0,0 +1,22 @@
+-- Политика опозданий для заданий
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_policy VARCHAR(20) NOT NULL DEFAULT 'none';
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS late_penalty FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS close_date TIMESTAMP;
+
+-- Штраф за опоздание, рассчитанный при выставлении оценки
+ALTER TABLE grades ADD COLUMN IF NOT EXISTS late_penalty FLOAT NOT NULL DEFAULT 0;
+
+-- Индивидуальные продления дедлайна
+CREATE TABLE IF NOT EXISTS assignment_extensions (
+    id            SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    due_date      TIMESTAMP NOT NULL,
+    reason        TEXT NOT NULL DEFAULT '',
+    granted_by    INTEGER NOT NULL REFERENCES users(id),
+    created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (assignment_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_extensions_student_id ON assignment_extensions(student_id);
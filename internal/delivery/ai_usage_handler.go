@@ -0,0 +1,159 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services/aiusage"
+)
+
+// AIUsageHandler — администратору: расход токенов LLM и лимиты по ролям и пользователям.
+type AIUsageHandler struct {
+	svc *aiusage.Service
+}
+
+func NewAIUsageHandler(svc *aiusage.Service) *AIUsageHandler {
+	return &AIUsageHandler{svc: svc}
+}
+
+type aiQuotaInput struct {
+	DailyTokens   int64 `json:"daily_tokens"`   // 0 — без лимита
+	MonthlyTokens int64 `json:"monthly_tokens"` // 0 — без лимита
+}
+
+// GET /api/admin/ai/usage?from=2025-01-01&to=2025-01-31&group_by=user|course|feature|model|day&user_id=&course_id=&feature=
+// По умолчанию — текущий месяц, группировка по пользователям. to включительно.
+func (h *AIUsageHandler) Report(c *gin.Context) {
+	now := time.Now()
+	filter := aiusage.ReportFilter{
+		From:    time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+		GroupBy: c.DefaultQuery("group_by", aiusage.GroupByUser),
+		Feature: c.Query("feature"),
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD"})
+			return
+		}
+		filter.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be YYYY-MM-DD"})
+			return
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	if v := c.Query("course_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course_id"})
+			return
+		}
+		filter.CourseID = uint(id)
+	}
+	rows, err := h.svc.Report(filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":     filter.From,
+		"to":       filter.To,
+		"group_by": filter.GroupBy,
+		"rows":     rows,
+	})
+}
+
+// GET /api/admin/ai/usage/users/:id — лимит, расход за сегодня и за месяц
+func (h *AIUsageHandler) UserUsage(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	summary, err := h.svc.UserUsage(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GET /api/admin/ai/quotas
+func (h *AIUsageHandler) ListQuotas(c *gin.Context) {
+	quotas, err := h.svc.ListQuotas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quotas)
+}
+
+// PUT /api/admin/ai/quotas/roles/:role
+func (h *AIUsageHandler) SetRoleQuota(c *gin.Context) {
+	var req aiQuotaInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quota: " + err.Error()})
+		return
+	}
+	quota, err := h.svc.SetRoleQuota(c.Param("role"), req.DailyTokens, req.MonthlyTokens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quota)
+}
+
+// PUT /api/admin/ai/quotas/users/:id — персональный лимит вместо лимита роли
+func (h *AIUsageHandler) SetUserQuota(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	var req aiQuotaInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quota: " + err.Error()})
+		return
+	}
+	quota, err := h.svc.SetUserQuota(userID, req.DailyTokens, req.MonthlyTokens)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, quota)
+}
+
+// DELETE /api/admin/ai/quotas/users/:id
+func (h *AIUsageHandler) DeleteUserQuota(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUserQuota(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user quota removed"})
+}
+
+func (h *AIUsageHandler) userID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, false
+	}
+	return uint(id), true
+}
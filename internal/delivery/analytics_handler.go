@@ -77,6 +77,24 @@ func (h *AnalyticsHandler) Heatmap(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// GET /api/teacher/analytics/criteria?course_id=&assignment_id=
+// Средние по критериям рубрики — слабые места группы, самые низкие первыми.
+func (h *AnalyticsHandler) Criteria(c *gin.Context) {
+	id, ok := h.teacherID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	courseID, _ := strconv.ParseUint(c.Query("course_id"), 10, 64)
+	assignmentID, _ := strconv.ParseUint(c.Query("assignment_id"), 10, 64)
+	res, err := h.svc.CriteriaStats(id, uint(courseID), uint(assignmentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 // GET /api/teacher/analytics/at-risk?course_id=&threshold=60
 func (h *AnalyticsHandler) AtRisk(c *gin.Context) {
 	id, ok := h.teacherID(c)
@@ -0,0 +1,141 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/db"
+	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
+)
+
+// Deanonymize — POST /api/teacher/assignments/:id/deanonymize
+// Снимает анонимность только после того, как все сданные работы оценены.
+func (h *GradeHandler) Deanonymize(c *gin.Context) {
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	if err := ensureTeacherOwnsAssignment(uint(assignmentID), teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if h.anon == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "anonymous grading disabled"})
+		return
+	}
+
+	if err := h.anon.Deanonymize(uint(assignmentID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// submissionStudent — студент (автор) работы; работа должна относиться к заданию.
+func submissionStudent(submissionID, assignmentID uint) (uint, error) {
+	var row struct {
+		StudentID    uint `gorm:"column:student_id"`
+		AssignmentID uint `gorm:"column:assignment_id"`
+	}
+	err := db.DB.Table("assignment_submissions").
+		Select("student_id, assignment_id").
+		Where("id = ? AND deleted_at IS NULL", submissionID).
+		Limit(1).
+		Scan(&row).Error
+	if err != nil || row.StudentID == 0 || row.AssignmentID != assignmentID {
+		return 0, errors.New("submission not found")
+	}
+	return row.StudentID, nil
+}
+
+// maskGrades — при анонимной проверке заменяет студента в оценках на псевдоним.
+func maskGrades(anon *anonymize.Service, assignmentID uint, grades []models.Grade) {
+	if anon == nil || len(grades) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(grades))
+	for _, g := range grades {
+		ids = append(ids, g.StudentID)
+	}
+	labels, ok := anon.Labels(assignmentID, ids)
+	if !ok {
+		return
+	}
+	for i := range grades {
+		grades[i].Pseudonym = labels[grades[i].StudentID]
+		grades[i].StudentID = 0
+		grades[i].Student = nil
+	}
+}
+
+// submissionLabels — псевдонимы авторов работы (ok=false, если анонимность не действует).
+func (h *AssignmentSubmissionHandler) submissionLabels(submissionID uint, studentIDs []uint) (map[uint]string, bool) {
+	if h.anon == nil {
+		return nil, false
+	}
+	return h.anon.Labels(h.anon.AssignmentOfSubmission(submissionID), studentIDs)
+}
+
+func (h *AssignmentSubmissionHandler) maskRevisions(submissionID uint, revisions []models.SubmissionRevision) {
+	if _, ok := h.submissionLabels(submissionID, nil); !ok {
+		return
+	}
+	for i := range revisions {
+		revisions[i].AuthorID = 0
+	}
+}
+
+func (h *AssignmentSubmissionHandler) maskTimeline(timeline *models.WritingTimeline) {
+	if _, ok := h.submissionLabels(timeline.SubmissionID, nil); !ok {
+		return
+	}
+	for i := range timeline.Points {
+		timeline.Points[i].AuthorID = 0
+	}
+}
+
+func (h *AssignmentSubmissionHandler) maskFiles(submissionID uint, files []models.Attachment) {
+	if _, ok := h.submissionLabels(submissionID, nil); !ok {
+		return
+	}
+	for i := range files {
+		files[i].OwnerID = 0
+	}
+}
+
+// maskAnnotations — комментарии студентов подписываются псевдонимом; комментарии teacherID не трогаем.
+func (h *AssignmentSubmissionHandler) maskAnnotations(submissionID, teacherID uint, threads []models.SubmissionAnnotation) {
+	var ids []uint
+	for _, t := range threads {
+		ids = append(ids, t.AuthorID)
+		for _, r := range t.Replies {
+			ids = append(ids, r.AuthorID)
+		}
+	}
+	labels, ok := h.submissionLabels(submissionID, ids)
+	if !ok {
+		return
+	}
+	mask := func(a *models.SubmissionAnnotation) {
+		if a.AuthorID != teacherID {
+			a.AuthorName = labels[a.AuthorID]
+			a.AuthorID = 0
+		}
+	}
+	for i := range threads {
+		mask(&threads[i])
+		for j := range threads[i].Replies {
+			mask(&threads[i].Replies[j])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services/archive"
+	"rest-project/internal/services/queue"
+)
+
+type SubmissionArchiveHandler struct {
+	svc *archive.Service
+	q   *queue.Queue
+}
+
+func NewSubmissionArchiveHandler(svc *archive.Service, q *queue.Queue) *SubmissionArchiveHandler {
+	return &SubmissionArchiveHandler{svc: svc, q: q}
+}
+
+// POST /api/teacher/assignments/:id/submissions/archive
+// Барлық жұмыстарды ZIP-ке жинау тапсырмасын queue-ге қояды; сілтеме job_status арқылы келеді.
+func (h *SubmissionArchiveHandler) Start(c *gin.Context) {
+	if h.q == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "queue disabled"})
+		return
+	}
+	if h.svc == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage disabled"})
+		return
+	}
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	if err := ensureTeacherOwnsAssignment(uint(assignmentID), teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	raw, _ := json.Marshal(archive.Payload{AssignmentID: uint(assignmentID)})
+
+	ctx, cancel := contextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	id, err := h.q.Enqueue(ctx, archive.JobType, json.RawMessage(raw), teacherID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job_id": id, "status": "queued", "type": archive.JobType})
+}
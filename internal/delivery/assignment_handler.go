@@ -8,26 +8,56 @@ import (
 
 	"rest-project/internal/models"
 	"rest-project/internal/services"
+	"rest-project/internal/services/release"
 )
 
 type AssignmentHandler struct {
 	service *services.AssignmentService
+	release *release.Service // optional
 }
 
 func NewAssignmentHandler(service *services.AssignmentService) *AssignmentHandler {
 	return &AssignmentHandler{service: service}
 }
 
+// SetRelease — жариялау сервисін қосады: сақтаудан кейін publish бірден тексеріледі (опционально).
+func (h *AssignmentHandler) SetRelease(svc *release.Service) {
+	h.release = svc
+}
+
+// triggerRelease — уақыты келген тапсырмаларды асинхронды жариялайды.
+func (h *AssignmentHandler) triggerRelease() {
+	if h.release == nil {
+		return
+	}
+	go func() { _, _ = h.release.PublishDue() }()
+}
+
 // createAssignmentInput — тапсырма жасауға/өзгертуге арналған input
 type createAssignmentInput struct {
 	Title       string          `json:"title" binding:"required"`
 	Description string          `json:"description"`
 	DueDate     string          `json:"due_date" binding:"required"`
 	MaxScore    float64         `json:"max_score"`
+	MaxBonus    float64         `json:"max_bonus"`
 	Type        string          `json:"type"`
 	WordCount   int             `json:"word_count"`
 	Criteria    []criterionInput `json:"criteria"`
 	Questions   []questionInput  `json:"questions"`
+	LatePolicy  string          `json:"late_policy"`
+	LatePenalty float64         `json:"late_penalty"`
+	CloseDate   string          `json:"close_date"` // RFC3339, бос болса — шектеу жоқ
+	IsDraft     bool            `json:"is_draft"`
+	PublishAt   string          `json:"publish_at"` // RFC3339, бос болса — бірден жарияланады
+	GroupMode   string          `json:"group_mode"` // "none" | "teacher" | "self_signup"
+	MaxGroupSize int            `json:"max_group_size"`
+	AllowAttachments  bool      `json:"allow_attachments"`
+	AllowedExtensions string    `json:"allowed_extensions"`
+	MaxFileSizeMB     int       `json:"max_file_size_mb"`
+	CodeLanguage string         `json:"code_language"`
+	StarterCode  string         `json:"starter_code"`
+	TestCases    []models.CodeTestCase `json:"test_cases"`
+	AnonymousGrading bool           `json:"anonymous_grading"`
 }
 
 type criterionInput struct {
@@ -40,6 +70,7 @@ type criterionInput struct {
 	AutoCheckable bool    `json:"auto_checkable"`
 	CheckPrompt   string  `json:"check_prompt"`
 	OrderIndex    int     `json:"order_index"`
+	Levels        []models.RubricLevel `json:"levels"`
 }
 
 type questionInput struct {
@@ -54,6 +85,12 @@ type updateCriteriaInput struct {
 	Criteria []criterionInput `json:"criteria"`
 }
 
+type grantExtensionInput struct {
+	StudentID uint   `json:"student_id" binding:"required"`
+	DueDate   string `json:"due_date" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
 // GetCourseAssignments — Teacher: correctIndex КӨРІНЕДІ
 func (h *AssignmentHandler) GetCourseAssignments(c *gin.Context) {
 	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -158,11 +195,20 @@ func (h *AssignmentHandler) CreateAssignment(c *gin.Context) {
 	teacherID := userID.(uint)
 
 	req := buildRequest(input, uint(courseID), dueDate)
+	if req.CloseDate, err = parseOptionalTime(input.CloseDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат close_date (RFC3339)"})
+		return
+	}
+	if req.PublishAt, err = parseOptionalTime(input.PublishAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат publish_at (RFC3339)"})
+		return
+	}
 	assignment, err := h.service.CreateAssignment(req, teacherID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	h.triggerRelease()
 	c.JSON(http.StatusCreated, assignment)
 }
 
@@ -194,11 +240,20 @@ func (h *AssignmentHandler) UpdateAssignment(c *gin.Context) {
 	teacherID := userID.(uint)
 
 	req := buildRequest(input, 0, dueDate) // courseID update-та өзгермейді
+	if req.CloseDate, err = parseOptionalTime(input.CloseDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат close_date (RFC3339)"})
+		return
+	}
+	if req.PublishAt, err = parseOptionalTime(input.PublishAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат publish_at (RFC3339)"})
+		return
+	}
 	assignment, err := h.service.UpdateAssignment(uint(assignmentID), req, teacherID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.triggerRelease()
 	c.JSON(http.StatusOK, assignment)
 }
 
@@ -239,6 +294,7 @@ func (h *AssignmentHandler) UpdateAssignmentCriteria(c *gin.Context) {
 			AutoCheckable: ci.AutoCheckable,
 			CheckPrompt:   ci.CheckPrompt,
 			OrderIndex:    ci.OrderIndex,
+			Levels:        ci.Levels,
 		})
 	}
 
@@ -289,6 +345,123 @@ func (h *AssignmentHandler) DeleteAssignment(c *gin.Context) {
 	})
 }
 
+// PublishAssignment — POST /api/teacher/assignments/:id/publish
+func (h *AssignmentHandler) PublishAssignment(c *gin.Context) {
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	assignment, err := h.service.PublishAssignment(uint(assignmentID), teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.triggerRelease()
+	c.JSON(http.StatusOK, assignment)
+}
+
+// ListExtensions — GET /api/teacher/assignments/:id/extensions
+func (h *AssignmentHandler) ListExtensions(c *gin.Context) {
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	extensions, err := h.service.ListExtensions(uint(assignmentID), teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, extensions)
+}
+
+// GrantExtension — POST /api/teacher/assignments/:id/extensions
+func (h *AssignmentHandler) GrantExtension(c *gin.Context) {
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+
+	var input grantExtensionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid extension data: " + err.Error()})
+		return
+	}
+	dueDate, err := time.Parse(time.RFC3339, input.DueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date (RFC3339)"})
+		return
+	}
+
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	extension, err := h.service.GrantExtension(uint(assignmentID), services.GrantExtensionRequest{
+		StudentID: input.StudentID,
+		DueDate:   dueDate,
+		Reason:    input.Reason,
+	}, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, extension)
+}
+
+// RevokeExtension — DELETE /api/teacher/assignments/:id/extensions/:student_id
+func (h *AssignmentHandler) RevokeExtension(c *gin.Context) {
+	assignmentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("student_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid student id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	if err := h.service.RevokeExtension(uint(assignmentID), uint(studentID), teacherID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "extension revoked"})
+}
+
+// parseOptionalTime — бос жол болса nil қайтарады
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // buildRequest — input-ты CreateAssignmentRequest-ке айналдырады
 func buildRequest(input createAssignmentInput, courseID uint, dueDate time.Time) services.CreateAssignmentRequest {
 	req := services.CreateAssignmentRequest{
@@ -297,8 +470,21 @@ func buildRequest(input createAssignmentInput, courseID uint, dueDate time.Time)
 		CourseID:    courseID,
 		DueDate:     dueDate,
 		MaxScore:    input.MaxScore,
+		MaxBonus:    input.MaxBonus,
 		Type:        input.Type,
 		WordCount:   input.WordCount,
+		LatePolicy:  input.LatePolicy,
+		LatePenalty: input.LatePenalty,
+		IsDraft:     input.IsDraft,
+		GroupMode:   input.GroupMode,
+		MaxGroupSize: input.MaxGroupSize,
+		AllowAttachments:  input.AllowAttachments,
+		AllowedExtensions: input.AllowedExtensions,
+		MaxFileSizeMB:     input.MaxFileSizeMB,
+		CodeLanguage: input.CodeLanguage,
+		StarterCode:  input.StarterCode,
+		TestCases:    input.TestCases,
+		AnonymousGrading: input.AnonymousGrading,
 	}
 	for _, ci := range input.Criteria {
 		maxPoints := ci.MaxPoints
@@ -315,6 +501,7 @@ func buildRequest(input createAssignmentInput, courseID uint, dueDate time.Time)
 			AutoCheckable: ci.AutoCheckable,
 			CheckPrompt:   ci.CheckPrompt,
 			OrderIndex:    ci.OrderIndex,
+			Levels:        ci.Levels,
 		})
 	}
 	for _, qi := range input.Questions {
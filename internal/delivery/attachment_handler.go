@@ -3,6 +3,7 @@ package delivery
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -52,6 +53,11 @@ func (h *AttachmentHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_type"})
 		return
 	}
+	// Файлы студентов выдаются только через эндпоинты сдачи работ
+	if strings.HasPrefix(input.ObjectKey, submissionObjectPrefix) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "submission files cannot be attached here"})
+		return
+	}
 	uid, _ := c.Get("user_id")
 	ownerID, _ := uid.(uint)
 
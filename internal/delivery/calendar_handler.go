@@ -67,7 +67,9 @@ func (h *CalendarHandler) GetStudentCalendar(c *gin.Context) {
 		JOIN course_students cs ON cs.course_id = c.id
 		WHERE cs.user_id = ?
 		  AND a.due_date IS NOT NULL
-		  AND a.deleted_at IS NULL`
+		  AND a.deleted_at IS NULL
+		  AND a.is_draft = FALSE
+		  AND (a.publish_at IS NULL OR a.publish_at <= NOW())`
 
 	args := []interface{}{studentID}
 	if !start.IsZero() {
@@ -116,7 +118,11 @@ func (h *CalendarHandler) GetStudentCalendar(c *gin.Context) {
 		FROM schedule_events se
 		JOIN courses c ON c.id = se.course_id
 		JOIN course_students cs ON cs.course_id = se.course_id
-		WHERE cs.user_id = ?`
+		LEFT JOIN assignments a ON a.id = se.assignment_id
+		WHERE cs.user_id = ?
+		  AND (se.assignment_id IS NULL
+		       OR (a.deleted_at IS NULL AND a.is_draft = FALSE
+		           AND (a.publish_at IS NULL OR a.publish_at <= NOW())))`
 
 	schedArgs := []interface{}{studentID}
 	if !start.IsZero() {
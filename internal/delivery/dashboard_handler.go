@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"rest-project/internal/db"
 	"rest-project/internal/models"
+	"rest-project/internal/services/analytics"
 )
 
 type DashboardHandler struct{}
@@ -22,7 +23,7 @@ type DashboardStats struct {
 	StudentsCount      int64   `json:"students_count"`
 	AssignmentsCount   int64   `json:"assignments_count"`
 	PendingSubmissions int64   `json:"pending_submissions"`
-	AverageScore       float64 `json:"average_score"`
+	AverageScore       float64 `json:"average_score"` // средний процент от max_score
 	PromptCount        int64   `json:"prompt_count"`
 }
 
@@ -49,7 +50,7 @@ type DashCourseOverview struct {
 	Title           string  `json:"title"`
 	StudentCount    int64   `json:"student_count"`
 	AssignmentCount int64   `json:"assignment_count"`
-	AvgScore        float64 `json:"avg_score"`
+	AvgScore        float64 `json:"avg_score"` // средний процент от max_score
 }
 
 type GradeBucket struct {
@@ -100,7 +101,7 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 		Count(&resp.Stats.PendingSubmissions)
 
 	gdb.Table("grades g").
-		Select("COALESCE(AVG(g.score), 0)").
+		Select("COALESCE(AVG("+analytics.ScorePercentSQL+"), 0)").
 		Joins("JOIN assignments a ON a.id = g.assignment_id").
 		Joins("JOIN courses c ON c.id = a.course_id").
 		Where("c.teacher_id = ? AND c.deleted_at IS NULL AND g.deleted_at IS NULL", teacherID).
@@ -188,7 +189,7 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 		Select(`c.id, c.title,
 			(SELECT COUNT(DISTINCT cs2.user_id) FROM course_students cs2 WHERE cs2.course_id = c.id) as student_count,
 			(SELECT COUNT(*) FROM assignments a2 WHERE a2.course_id = c.id AND a2.deleted_at IS NULL) as assignment_count,
-			COALESCE((SELECT AVG(g2.score) FROM grades g2 JOIN assignments a3 ON a3.id = g2.assignment_id WHERE a3.course_id = c.id AND g2.deleted_at IS NULL), 0) as avg_score`).
+			COALESCE((SELECT AVG(`+analytics.ScorePercentSQL+`) FROM grades g JOIN assignments a ON a.id = g.assignment_id WHERE a.course_id = c.id AND g.deleted_at IS NULL), 0) as avg_score`).
 		Where("c.teacher_id = ? AND c.deleted_at IS NULL", teacherID).
 		Order("c.created_at DESC").
 		Limit(6).
@@ -211,11 +212,12 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 		Count int64
 	}
 	var distRows []distRow
+	// Диапазоны — по проценту от max_score задания (бонус выше 100% попадает в 90-100)
 	gdb.Table("grades g").
 		Select(`CASE
-				WHEN g.score >= 90 THEN '90-100'
-				WHEN g.score >= 75 THEN '75-89'
-				WHEN g.score >= 60 THEN '60-74'
+				WHEN `+analytics.ScorePercentSQL+` >= 90 THEN '90-100'
+				WHEN `+analytics.ScorePercentSQL+` >= 75 THEN '75-89'
+				WHEN `+analytics.ScorePercentSQL+` >= 60 THEN '60-74'
 				ELSE '0-59'
 			END as range,
 			COUNT(*) as count`).
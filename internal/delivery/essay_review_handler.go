@@ -3,6 +3,7 @@ package delivery
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -18,15 +19,15 @@ import (
 
 // EssayReviewHandler — teacher эссені AI-мен тексеру.
 type EssayReviewHandler struct {
-	ai *aiservice.OpenRouterClient
+	ai aiservice.Provider
 }
 
-func NewEssayReviewHandler(ai *aiservice.OpenRouterClient) *EssayReviewHandler {
+func NewEssayReviewHandler(ai aiservice.Provider) *EssayReviewHandler {
 	return &EssayReviewHandler{ai: ai}
 }
 
 // POST /api/teacher/assignments/:id/ai-review
-// Body: { "content": "...", "student_id": 123 }
+// Body: { "content": "..." } немесе { "submission_id": 45 } — анонимді тексеруде студентті білмей-ақ.
 func (h *EssayReviewHandler) Review(c *gin.Context) {
 	if h.ai == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "AI қызметі қосылмаған"})
@@ -40,24 +41,47 @@ func (h *EssayReviewHandler) Review(c *gin.Context) {
 	}
 
 	var body struct {
-		Content string `json:"content" binding:"required"`
+		Content      string `json:"content"`
+		SubmissionID uint   `json:"submission_id"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "content is required"})
 		return
 	}
-	body.Content = strings.TrimSpace(body.Content)
-	if body.Content == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "essay content is empty"})
-		return
-	}
-
-	// Assignment + criteria
+	// Тапсырма + курс мұғалімі: басқа курстың жұмысын LLM-ге жіберуге болмайды
+	teacherID, _ := getCurrentUserID(c)
 	var assignment models.Assignment
 	if err := db.DB.First(&assignment, assignmentID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "assignment not found"})
 		return
 	}
+	var course models.Course
+	if err := db.DB.Select("id", "teacher_id").First(&course, assignment.CourseID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		return
+	}
+	if course.TeacherID != teacherID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "teacher is not assigned to this course"})
+		return
+	}
+
+	if body.SubmissionID != 0 {
+		// Мәтін тек осы тапсырманың жұмысынан алынады
+		var submission models.AssignmentSubmission
+		err := db.DB.Select("id", "content").
+			Where("id = ? AND assignment_id = ?", body.SubmissionID, assignmentID).
+			Take(&submission).Error
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+			return
+		}
+		body.Content = submission.Content
+	}
+	body.Content = strings.TrimSpace(body.Content)
+	if body.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "essay content is empty"})
+		return
+	}
 
 	var criteria []models.EssayCriterion
 	if assignment.Criteria != "" {
@@ -68,8 +92,15 @@ func (h *EssayReviewHandler) Review(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 90*time.Second)
 	defer cancel()
+	ctx = aiservice.WithCall(ctx, aiservice.CallInfo{
+		UserID:       teacherID,
+		Feature:      aiservice.FeatureEssayReview,
+		AssignmentID: assignment.ID,
+		SubmissionID: body.SubmissionID,
+	})
 
-	raw, err := h.ai.Chat(ctx, []aiservice.Message{
+	var review aiservice.EssayReviewOutput
+	err = aiservice.ChatStructured(ctx, h.ai, aiservice.EssayReviewTask(assignment.MaxScore), []aiservice.Message{
 		{
 			Role: "system",
 			Content: "Сен тәжірибелі мектеп мұғалімінің AI-көмекшісісің. " +
@@ -77,17 +108,23 @@ func (h *EssayReviewHandler) Review(c *gin.Context) {
 				"Жауапты қазақ тілінде бер. Нақты болып, конструктивті пікір жаз.",
 		},
 		{Role: "user", Content: prompt},
-	})
+	}, &review)
 	if err != nil {
+		if errors.Is(err, aiservice.ErrQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, aiservice.ErrInvalidOutput) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	suggestedScore, feedback := parseEssayReviewResponse(raw, assignment.MaxScore)
-
 	c.JSON(http.StatusOK, gin.H{
-		"suggested_score": suggestedScore,
-		"feedback":        feedback,
+		"suggested_score": review.SuggestedScore,
+		"feedback":        review.Feedback,
 	})
 }
 
@@ -116,66 +153,12 @@ func buildEssayReviewPrompt(a models.Assignment, criteria []models.EssayCriterio
 	sb.WriteString("\n```\n\n")
 
 	sb.WriteString("## Нұсқаулар:\n")
-	sb.WriteString("Жоғарыдағы эссені критерийлер бойынша тексер. Жауапты ТЕК осы форматта бер:\n\n")
-	sb.WriteString(fmt.Sprintf("ҰСЫНЫЛАТЫН_БАЛЛ: [0-%.0f арасындағы бүтін немесе ондық сан]\n\n", a.MaxScore))
-	sb.WriteString("ПІКІР:\n")
-	sb.WriteString("[Мұнда әр критерий бойынша нақты бағалауды жаз. ")
-	sb.WriteString("Жақсы жақтарын, кемшіліктерін, және жақсарту жолдарын атап өт.]\n")
+	sb.WriteString("Жоғарыдағы эссені критерийлер бойынша тексер. Жауапты ТЕК JSON түрінде бер, басқа мәтінсіз:\n")
+	sb.WriteString(`{"suggested_score": 0, "feedback": "..."}`)
+	sb.WriteString("\n\n")
+	sb.WriteString(fmt.Sprintf("suggested_score — 0-%.0f арасындағы бүтін немесе ондық сан.\n", a.MaxScore))
+	sb.WriteString("feedback — әр критерий бойынша нақты бағалау: ")
+	sb.WriteString("жақсы жақтары, кемшіліктері және жақсарту жолдары.\n")
 
 	return sb.String()
 }
-
-// parseEssayReviewResponse — AI жауабынан балл мен пікірді шығарады.
-func parseEssayReviewResponse(response string, maxScore float64) (float64, string) {
-	var suggestedScore float64 = -1
-	var feedbackLines []string
-	inFeedback := false
-
-	for _, line := range strings.Split(response, "\n") {
-		trimmed := strings.TrimSpace(line)
-
-		if strings.HasPrefix(trimmed, "ҰСЫНЫЛАТЫН_БАЛЛ:") || strings.HasPrefix(trimmed, "ҰСЫНЫЛАТЫН БАЛЛ:") {
-			parts := strings.SplitN(trimmed, ":", 2)
-			if len(parts) == 2 {
-				numStr := strings.TrimSpace(parts[1])
-				// Extract first number
-				var buf strings.Builder
-				for _, r := range numStr {
-					if (r >= '0' && r <= '9') || r == '.' {
-						buf.WriteRune(r)
-					} else if buf.Len() > 0 {
-						break
-					}
-				}
-				if n, err := strconv.ParseFloat(buf.String(), 64); err == nil {
-					if n > maxScore {
-						n = maxScore
-					}
-					suggestedScore = n
-				}
-			}
-			continue
-		}
-
-		if strings.HasPrefix(trimmed, "ПІКІР:") {
-			inFeedback = true
-			rest := strings.TrimSpace(strings.TrimPrefix(trimmed, "ПІКІР:"))
-			if rest != "" {
-				feedbackLines = append(feedbackLines, rest)
-			}
-			continue
-		}
-
-		if inFeedback {
-			feedbackLines = append(feedbackLines, line)
-		}
-	}
-
-	feedback := strings.TrimSpace(strings.Join(feedbackLines, "\n"))
-	if feedback == "" {
-		// Fallback: жауаптың өзін пікір ретінде қайтар
-		feedback = response
-	}
-
-	return suggestedScore, feedback
-}
@@ -4,15 +4,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"time"
 
 	"rest-project/internal/db"
+	"rest-project/internal/models"
 	"rest-project/internal/services"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/gradescale"
 	"rest-project/internal/services/notifier"
 )
 
 type GradeHandler struct {
 	service *services.GradeService
-	hub     *notifier.Hub // optional
+	hub     *notifier.Hub       // optional
+	anon    *anonymize.Service // optional — анонимная проверка
+	scales  *gradescale.Service // optional — оценка по шкале курса
 }
 
 func NewGradeHandler(service *services.GradeService) *GradeHandler {
@@ -26,21 +32,50 @@ func (h *GradeHandler) SetHub(hub *notifier.Hub) {
 	h.hub = hub
 }
 
-// notifyStudent — отправляет студенту событие grade_updated.
+// SetAnonymizer — подключает анонимную проверку (псевдонимы вместо студентов).
+func (h *GradeHandler) SetAnonymizer(anon *anonymize.Service) {
+	h.anon = anon
+}
+
+// SetScales — подключает шкалы оценивания (буква / 5-балльная / зачёт).
+func (h *GradeHandler) SetScales(scales *gradescale.Service) {
+	h.scales = scales
+}
+
+// applyScales — проставляет Grade.Scaled по шкале курса.
+func (h *GradeHandler) applyScales(grades []models.Grade) {
+	if h.scales != nil {
+		h.scales.ApplyToGrades(grades)
+	}
+}
+
+// notifyStudent — отправляет студенту событие grade_updated (если оценки задания опубликованы).
 func (h *GradeHandler) notifyStudent(studentID, assignmentID uint, score float64) {
 	if h.hub == nil {
 		return
 	}
 	var meta struct {
-		AssignmentTitle string `gorm:"column:assignment_title"`
-		CourseTitle     string `gorm:"column:course_title"`
+		AssignmentTitle  string     `gorm:"column:assignment_title"`
+		CourseTitle      string     `gorm:"column:course_title"`
+		GradeReleaseMode string     `gorm:"column:grade_release_mode"`
+		GradesReleaseAt  *time.Time `gorm:"column:grades_release_at"`
+		GradesReleasedAt *time.Time `gorm:"column:grades_released_at"`
 	}
 	_ = db.DB.Table("assignments a").
-		Select("a.title AS assignment_title, c.title AS course_title").
+		Select("a.title AS assignment_title, c.title AS course_title, a.grade_release_mode, a.grades_release_at, a.grades_released_at").
 		Joins("JOIN courses c ON c.id = a.course_id").
 		Where("a.id = ?", assignmentID).
 		Limit(1).
 		Scan(&meta).Error
+	// Неопубликованные оценки не анонсируем — событие уйдёт при публикации
+	release := models.Assignment{
+		GradeReleaseMode: meta.GradeReleaseMode,
+		GradesReleaseAt:  meta.GradesReleaseAt,
+		GradesReleasedAt: meta.GradesReleasedAt,
+	}
+	if !release.GradesVisible(time.Now()) {
+		return
+	}
 	h.hub.SendToUser(studentID, "grade_updated", map[string]any{
 		"assignment_id":    assignmentID,
 		"assignment_title": meta.AssignmentTitle,
@@ -60,8 +95,9 @@ func (h *GradeHandler) GetAssignmentSubmissions(c *gin.Context) {
 
 	type submissionRow struct {
 		ID          uint    `gorm:"column:id"            json:"id"`
-		StudentID   uint    `gorm:"column:student_id"    json:"student_id"`
+		StudentID   uint    `gorm:"column:student_id"    json:"student_id,omitempty"`
 		Username    string  `gorm:"column:username"      json:"username"`
+		Pseudonym   string  `gorm:"-"                    json:"pseudonym,omitempty"`
 		Content     string  `gorm:"column:content"       json:"content"`
 		Answers     string  `gorm:"column:answers"       json:"answers"`
 		Status      string  `gorm:"column:status"        json:"status"`
@@ -70,6 +106,10 @@ func (h *GradeHandler) GetAssignmentSubmissions(c *gin.Context) {
 		GradeID     *uint   `gorm:"column:grade_id"      json:"grade_id"`
 		Score       *float64 `gorm:"column:score"        json:"score"`
 		Feedback    *string  `gorm:"column:feedback"     json:"feedback"`
+		LatePenalty *float64 `gorm:"column:late_penalty" json:"late_penalty"`
+		GroupID     *uint    `gorm:"column:group_id"     json:"group_id"`
+		GroupName   *string  `gorm:"column:group_name"   json:"group_name"`
+		FileCount   int      `gorm:"column:file_count"   json:"file_count"`
 	}
 
 	var rows []submissionRow
@@ -80,10 +120,15 @@ func (h *GradeHandler) GetAssignmentSubmissions(c *gin.Context) {
 			s.submitted_at::text AS submitted_at,
 			g.id   AS grade_id,
 			g.score,
-			g.feedback
+			g.feedback,
+			g.late_penalty,
+			s.group_id,
+			ag.name AS group_name,
+			(SELECT COUNT(*) FROM attachments f WHERE f.target_type = 'submission' AND f.target_id = s.id) AS file_count
 		FROM assignment_submissions s
 		JOIN users u ON u.id = s.student_id
 		LEFT JOIN grades g ON g.student_id = s.student_id AND g.assignment_id = s.assignment_id
+		LEFT JOIN assignment_groups ag ON ag.id = s.group_id
 		WHERE s.assignment_id = ?
 		  AND s.deleted_at IS NULL
 		ORDER BY s.submitted_at DESC NULLS LAST
@@ -95,6 +140,20 @@ func (h *GradeHandler) GetAssignmentSubmissions(c *gin.Context) {
 	if rows == nil {
 		rows = []submissionRow{}
 	}
+	if h.anon != nil {
+		ids := make([]uint, 0, len(rows))
+		for _, r := range rows {
+			ids = append(ids, r.StudentID)
+		}
+		// Анонимная проверка: вместо имени и ID — стабильный псевдоним
+		if labels, ok := h.anon.Labels(uint(assignmentID), ids); ok {
+			for i := range rows {
+				rows[i].Pseudonym = labels[rows[i].StudentID]
+				rows[i].Username = rows[i].Pseudonym
+				rows[i].StudentID = 0
+			}
+		}
+	}
 	c.JSON(http.StatusOK, rows)
 }
 
@@ -111,6 +170,8 @@ func (h *GradeHandler) GetAssignmentGrades(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
 		return
 	}
+	maskGrades(h.anon, uint(assignmentID), grades)
+	h.applyScales(grades)
 
 	c.JSON(http.StatusOK, grades)
 }
@@ -123,10 +184,13 @@ func (h *GradeHandler) CreateGrade(c *gin.Context) {
 		return
 	}
 
+	// При анонимной проверке студент указывается через submission_id
 	var input struct {
-		StudentID uint    `json:"student_id" binding:"required"`
-		Score     float64 `json:"score" binding:"required"`
-		Feedback  string  `json:"feedback"`
+		StudentID    uint     `json:"student_id"`
+		SubmissionID uint     `json:"submission_id"`
+		Score        *float64 `json:"score"`
+		Criteria     []models.CriterionScoreInput `json:"criteria"` // оценка по рубрике — score считается автоматически
+		Feedback     string   `json:"feedback"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -142,10 +206,28 @@ func (h *GradeHandler) CreateGrade(c *gin.Context) {
 
 	teacherID := userID.(uint)
 
+	if input.SubmissionID != 0 {
+		studentID, err := submissionStudent(input.SubmissionID, uint(assignmentID))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		input.StudentID = studentID
+	}
+	if input.StudentID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "student_id or submission_id is required"})
+		return
+	}
+	if input.Score == nil && len(input.Criteria) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score or criteria is required"})
+		return
+	}
+
 	grade, err := h.service.CreateGrade(
 		uint(assignmentID),
 		input.StudentID,
-		input.Score,
+		scoreValue(input.Score),
+		input.Criteria,
 		input.Feedback,
 		teacherID,
 	)
@@ -155,9 +237,47 @@ func (h *GradeHandler) CreateGrade(c *gin.Context) {
 		return
 	}
 
-	go h.notifyStudent(input.StudentID, uint(assignmentID), input.Score)
+	go h.notifyStudent(input.StudentID, uint(assignmentID), grade.Score)
 
-	c.JSON(http.StatusCreated, grade)
+	masked := []models.Grade{*grade}
+	maskGrades(h.anon, uint(assignmentID), masked)
+	h.applyScales(masked)
+	c.JSON(http.StatusCreated, masked[0])
+}
+
+// GradeGroup — POST /api/teacher/assignments/:id/groups/:group_id/grade
+// Одна оценка на группу, раздаётся каждому участнику с поправками.
+func (h *GradeHandler) GradeGroup(c *gin.Context) {
+	assignmentID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+
+	var input services.GroupGradeRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные для оценки"})
+		return
+	}
+
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не авторизован"})
+		return
+	}
+
+	grades, err := h.service.GradeGroup(assignmentID, groupID, input, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, g := range grades {
+		go h.notifyStudent(g.StudentID, g.AssignmentID, g.Score)
+	}
+	maskGrades(h.anon, assignmentID, grades)
+	h.applyScales(grades)
+
+	c.JSON(http.StatusOK, grades)
 }
 
 // UpdateGrade обновляет оценку
@@ -169,8 +289,10 @@ func (h *GradeHandler) UpdateGrade(c *gin.Context) {
 	}
 
 	var input struct {
-		Score    float64 `json:"score" binding:"required"`
-		Feedback string  `json:"feedback"`
+		Score    *float64 `json:"score"`
+		Criteria []models.CriterionScoreInput `json:"criteria"`
+		Feedback string   `json:"feedback"`
+		Reason   string   `json:"reason"` // обязательна после публикации оценок
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -186,10 +308,17 @@ func (h *GradeHandler) UpdateGrade(c *gin.Context) {
 
 	teacherID := userID.(uint)
 
+	if input.Score == nil && len(input.Criteria) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score or criteria is required"})
+		return
+	}
+
 	grade, err := h.service.UpdateGrade(
 		uint(gradeID),
-		input.Score,
+		scoreValue(input.Score),
+		input.Criteria,
 		input.Feedback,
+		input.Reason,
 		teacherID,
 	)
 
@@ -200,6 +329,11 @@ func (h *GradeHandler) UpdateGrade(c *gin.Context) {
 
 	if grade != nil {
 		go h.notifyStudent(grade.StudentID, grade.AssignmentID, grade.Score)
+		masked := []models.Grade{*grade}
+		maskGrades(h.anon, grade.AssignmentID, masked)
+		h.applyScales(masked)
+		c.JSON(http.StatusOK, masked[0])
+		return
 	}
 
 	c.JSON(http.StatusOK, grade)
@@ -221,7 +355,21 @@ func (h *GradeHandler) DeleteGrade(c *gin.Context) {
 
 	teacherID := userID.(uint)
 
-	err = h.service.DeleteGrade(uint(gradeID), teacherID)
+	// Причина удаления — в теле запроса или в ?reason=
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+			return
+		}
+	}
+	if input.Reason == "" {
+		input.Reason = c.Query("reason")
+	}
+
+	err = h.service.DeleteGrade(uint(gradeID), teacherID, input.Reason)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -230,6 +378,37 @@ func (h *GradeHandler) DeleteGrade(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Оценка успешно удалена"})
 }
 
+// GetGradeHistory — журнал изменений оценки.
+// GET /api/teacher/grades/:id/history и GET /api/admin/grades/:id/history
+func (h *GradeHandler) GetGradeHistory(c *gin.Context) {
+	gradeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID оценки"})
+		return
+	}
+
+	userID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не авторизован"})
+		return
+	}
+
+	role, _ := c.Get("role")
+	isAdmin := role == string(models.RoleAdmin)
+
+	history, err := h.service.GetGradeHistory(uint(gradeID), userID, isAdmin)
+	if err != nil {
+		status := http.StatusNotFound
+		if err.Error() == "teacher is not assigned to this course" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // GetStudentGrades получает все оценки студента
 func (h *GradeHandler) GetStudentGrades(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -240,11 +419,50 @@ func (h *GradeHandler) GetStudentGrades(c *gin.Context) {
 
 	studentID := userID.(uint)
 
-	grades, err := h.service.GetGradesByStudent(studentID)
+	grades, err := h.service.GetVisibleGradesByStudent(studentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении оценок"})
 		return
 	}
+	h.applyScales(grades)
 
 	c.JSON(http.StatusOK, grades)
 }
+
+// GetSubmissionRubric — GET /api/teacher/submissions/:id/rubric
+// Рубрика задания, текущий разбор оценки по критериям и подсказка AI (если была).
+func (h *GradeHandler) GetSubmissionRubric(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID работы"})
+		return
+	}
+
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не авторизован"})
+		return
+	}
+
+	view, err := h.service.GetRubric(uint(submissionID), teacherID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if view.Grade != nil {
+		masked := []models.Grade{*view.Grade}
+		maskGrades(h.anon, view.AssignmentID, masked)
+		h.applyScales(masked)
+		view.Grade = &masked[0]
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+// scoreValue — балл из запроса; при оценке по рубрике он не передаётся.
+func scoreValue(score *float64) float64 {
+	if score == nil {
+		return 0
+	}
+	return *score
+}
@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services/release"
+)
+
+// GradeReleaseHandler — публикация оценок задания: сразу, вручную или по расписанию.
+type GradeReleaseHandler struct {
+	svc *release.Service
+}
+
+func NewGradeReleaseHandler(svc *release.Service) *GradeReleaseHandler {
+	return &GradeReleaseHandler{svc: svc}
+}
+
+type gradeReleaseInput struct {
+	Mode      string `json:"mode" binding:"required"` // immediate | manual | scheduled
+	ReleaseAt string `json:"release_at"`              // RFC3339, для scheduled
+}
+
+// PUT /api/teacher/assignments/:id/grade-release
+// Переключение на manual/scheduled снова скрывает уже опубликованные оценки.
+func (h *GradeReleaseHandler) SetPolicy(c *gin.Context) {
+	assignmentID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	var input gradeReleaseInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	var releaseAt *time.Time
+	if input.ReleaseAt != "" {
+		t, err := time.Parse(time.RFC3339, input.ReleaseAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "release_at must be RFC3339"})
+			return
+		}
+		releaseAt = &t
+	}
+	if err := h.svc.SetGradePolicy(assignmentID, input.Mode, releaseAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Наступившее время публикации обрабатываем сразу, не дожидаясь тикера
+	go func() { _, _ = h.svc.ReleaseDueGrades() }()
+	c.JSON(http.StatusOK, gin.H{"assignment_id": assignmentID, "mode": input.Mode, "release_at": releaseAt})
+}
+
+// POST /api/teacher/assignments/:id/grades/release
+// Публикует все оценки задания и отправляет студентам grade_updated.
+func (h *GradeReleaseHandler) Release(c *gin.Context) {
+	assignmentID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	notified, err := h.svc.ReleaseGrades(assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assignment_id": assignmentID, "released": true, "notified": notified})
+}
+
+func (h *GradeReleaseHandler) teacherAssignment(c *gin.Context) (uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, false
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	return assignmentID, true
+}
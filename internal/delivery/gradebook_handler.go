@@ -0,0 +1,335 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/db"
+	"rest-project/internal/services/gradebook"
+)
+
+type GradebookHandler struct {
+	svc *gradebook.Service
+}
+
+func NewGradebookHandler(svc *gradebook.Service) *GradebookHandler {
+	return &GradebookHandler{svc: svc}
+}
+
+type assignmentCategoryInput struct {
+	CategoryID *uint `json:"category_id"` // null — убрать из категории
+}
+
+type excuseInput struct {
+	Reason string `json:"reason"`
+}
+
+// maxGradebookImportSize — предельный размер файла импорта журнала
+const maxGradebookImportSize = 5 << 20
+
+// GET /api/teacher/courses/:id/gradebook
+// Полный журнал курса: студенты × задания, проценты по категориям и итог.
+func (h *GradebookHandler) Matrix(c *gin.Context) {
+	courseID, teacherID, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	matrix, err := h.svc.Matrix(teacherID, courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
+
+// GET /api/teacher/courses/:id/gradebook/export?format=csv|xlsx
+// Журнал в таблице: username, баллы по заданиям ("Название [#id]"), категории и итог.
+func (h *GradebookHandler) Export(c *gin.Context) {
+	courseID, teacherID, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", gradebook.FormatCSV)
+	data, contentType, err := h.svc.Export(teacherID, courseID, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="gradebook_course_%d.%s"`, courseID, format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// POST /api/teacher/courses/:id/gradebook/import/preview (multipart, поле "file")
+// Показывает, какие оценки будут созданы или изменены; база не меняется.
+func (h *GradebookHandler) PreviewImport(c *gin.Context) {
+	courseID, _, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+	preview, err := h.svc.PreviewImport(courseID, filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// POST /api/teacher/courses/:id/gradebook/import (multipart: file, overwrite, reason)
+// Оценки ставятся через GradeService — с проверками курса, студента и истории оценки.
+// Без overwrite=true существующие оценки не перезаписываются.
+func (h *GradebookHandler) ApplyImport(c *gin.Context) {
+	courseID, teacherID, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+	overwrite, _ := strconv.ParseBool(c.PostForm("overwrite"))
+	result, err := h.svc.ApplyImport(teacherID, courseID, filename, data, gradebook.ImportOptions{
+		Overwrite: overwrite,
+		Reason:    c.PostForm("reason"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GET /api/teacher/courses/:id/gradebook/categories
+func (h *GradebookHandler) ListCategories(c *gin.Context) {
+	courseID, _, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	categories, err := h.svc.ListCategories(courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// POST /api/teacher/courses/:id/gradebook/categories
+func (h *GradebookHandler) CreateCategory(c *gin.Context) {
+	courseID, _, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	var req gradebook.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category: " + err.Error()})
+		return
+	}
+	category, err := h.svc.CreateCategory(courseID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// PUT /api/teacher/courses/:id/gradebook/categories/:category_id
+func (h *GradebookHandler) UpdateCategory(c *gin.Context) {
+	courseID, _, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+	var req gradebook.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category: " + err.Error()})
+		return
+	}
+	category, err := h.svc.UpdateCategory(courseID, uint(categoryID), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DELETE /api/teacher/courses/:id/gradebook/categories/:category_id
+func (h *GradebookHandler) DeleteCategory(c *gin.Context) {
+	courseID, _, ok := h.teacherCourse(c)
+	if !ok {
+		return
+	}
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+	if err := h.svc.DeleteCategory(courseID, uint(categoryID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// PUT /api/teacher/assignments/:id/gradebook-category
+func (h *GradebookHandler) SetAssignmentCategory(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	var input assignmentCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	if err := h.svc.SetAssignmentCategory(assignmentID, input.CategoryID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assignment_id": assignmentID, "category_id": input.CategoryID})
+}
+
+// PUT /api/teacher/assignments/:id/excused/:student_id
+// Освобождает студента от задания: ячейка не учитывается в итоговой оценке.
+func (h *GradebookHandler) Excuse(c *gin.Context) {
+	assignmentID, teacherID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("student_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid student id"})
+		return
+	}
+	var input excuseInput
+	_ = c.ShouldBindJSON(&input)
+
+	exemption, err := h.svc.Excuse(assignmentID, uint(studentID), teacherID, input.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, exemption)
+}
+
+// DELETE /api/teacher/assignments/:id/excused/:student_id
+func (h *GradebookHandler) Unexcuse(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	studentID, err := strconv.ParseUint(c.Param("student_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid student id"})
+		return
+	}
+	if err := h.svc.Unexcuse(assignmentID, uint(studentID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// GET /api/student/courses/:id/gradebook
+// Собственная строка журнала студента: оценки, проценты по категориям, итог по шкале курса.
+func (h *GradebookHandler) StudentGradebook(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	row, scale, err := h.svc.StudentRow(uint(courseID), studentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"course_id": courseID, "scale": scale, "gradebook": row})
+}
+
+func readImportFile(c *gin.Context) (string, []byte, bool) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return "", nil, false
+	}
+	defer file.Close()
+	if header.Size > maxGradebookImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is too large"})
+		return "", nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxGradebookImportSize+1))
+	if err != nil || len(data) > maxGradebookImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is too large"})
+		return "", nil, false
+	}
+	return header.Filename, data, true
+}
+
+func (h *GradebookHandler) teacherCourse(c *gin.Context) (uint, uint, bool) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return 0, 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	if err := ensureTeacherOwnsCourse(uint(courseID), teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return uint(courseID), teacherID, true
+}
+
+func (h *GradebookHandler) teacherAssignment(c *gin.Context) (uint, uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return assignmentID, teacherID, true
+}
+
+// ensureTeacherOwnsCourse — курс существует и принадлежит преподавателю.
+func ensureTeacherOwnsCourse(courseID, teacherID uint) error {
+	var teacherIDfromDB uint
+	err := db.DB.Table("courses").
+		Select("teacher_id").
+		Where("id = ? AND deleted_at IS NULL", courseID).
+		Limit(1).
+		Scan(&teacherIDfromDB).Error
+	if err != nil || teacherIDfromDB == 0 {
+		return errors.New("course not found")
+	}
+	if teacherIDfromDB != teacherID {
+		return errors.New("forbidden: teacher does not own this course")
+	}
+	return nil
+}
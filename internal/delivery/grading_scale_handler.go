@@ -0,0 +1,144 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services/gradescale"
+)
+
+// GradingScaleHandler — шкалы оценивания. Администратор управляет шкалами организации,
+// преподаватель — своими; оба видят шкалы организации.
+type GradingScaleHandler struct {
+	svc *gradescale.Service
+}
+
+func NewGradingScaleHandler(svc *gradescale.Service) *GradingScaleHandler {
+	return &GradingScaleHandler{svc: svc}
+}
+
+type courseScaleInput struct {
+	ScaleID *uint `json:"scale_id"` // null — без шкалы
+}
+
+// GET /api/teacher/grading-scales, GET /api/admin/grading-scales
+func (h *GradingScaleHandler) List(c *gin.Context) {
+	owner, ok := h.owner(c)
+	if !ok {
+		return
+	}
+	var ownerID uint
+	if owner != nil {
+		ownerID = *owner
+	}
+	scales, err := h.svc.List(ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, scales)
+}
+
+// POST /api/teacher/grading-scales, POST /api/admin/grading-scales
+func (h *GradingScaleHandler) Create(c *gin.Context) {
+	owner, ok := h.owner(c)
+	if !ok {
+		return
+	}
+	var req gradescale.ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grading scale: " + err.Error()})
+		return
+	}
+	scale, err := h.svc.Create(owner, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, scale)
+}
+
+// PUT /api/teacher/grading-scales/:id, PUT /api/admin/grading-scales/:id
+func (h *GradingScaleHandler) Update(c *gin.Context) {
+	owner, ok := h.owner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grading scale id"})
+		return
+	}
+	var req gradescale.ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grading scale: " + err.Error()})
+		return
+	}
+	scale, err := h.svc.Update(uint(id), owner, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, scale)
+}
+
+// DELETE /api/teacher/grading-scales/:id, DELETE /api/admin/grading-scales/:id
+func (h *GradingScaleHandler) Delete(c *gin.Context) {
+	owner, ok := h.owner(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid grading scale id"})
+		return
+	}
+	if err := h.svc.Delete(uint(id), owner); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// PUT /api/teacher/courses/:id/grading-scale
+func (h *GradingScaleHandler) SetCourseScale(c *gin.Context) {
+	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	if err := ensureTeacherOwnsCourse(uint(courseID), teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	var input courseScaleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	if err := h.svc.SetCourseScale(uint(courseID), teacherID, input.ScaleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"course_id": courseID, "scale_id": input.ScaleID})
+}
+
+// owner — nil для администратора (шкалы организации), иначе ID преподавателя.
+func (h *GradingScaleHandler) owner(c *gin.Context) (*uint, bool) {
+	userID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return nil, false
+	}
+	if !isTeacher(c) {
+		return nil, true
+	}
+	return &userID, true
+}
@@ -0,0 +1,206 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services"
+)
+
+// AssignmentGroupHandler — топтық тапсырмалардың топтарын басқару
+type AssignmentGroupHandler struct {
+	service *services.AssignmentGroupService
+}
+
+func NewAssignmentGroupHandler(service *services.AssignmentGroupService) *AssignmentGroupHandler {
+	return &AssignmentGroupHandler{service: service}
+}
+
+// ListGroups — GET /api/teacher/assignments/:id/groups
+func (h *AssignmentGroupHandler) ListGroups(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	groups, err := h.service.ListGroups(assignmentID, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// CreateGroup — POST /api/teacher/assignments/:id/groups
+func (h *AssignmentGroupHandler) CreateGroup(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	var input services.GroupRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group data: " + err.Error()})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	group, err := h.service.CreateGroup(assignmentID, input, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup — PUT /api/teacher/assignments/:id/groups/:group_id
+func (h *AssignmentGroupHandler) UpdateGroup(c *gin.Context) {
+	assignmentID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+	var input services.GroupRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group data: " + err.Error()})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	group, err := h.service.UpdateGroup(assignmentID, groupID, input, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup — DELETE /api/teacher/assignments/:id/groups/:group_id
+func (h *AssignmentGroupHandler) DeleteGroup(c *gin.Context) {
+	assignmentID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	if err := h.service.DeleteGroup(assignmentID, groupID, teacherID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "group deleted"})
+}
+
+// ListGroupsForStudent — GET /api/student/assignments/:id/groups
+func (h *AssignmentGroupHandler) ListGroupsForStudent(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	groups, err := h.service.ListGroupsForStudent(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetMyGroup — GET /api/student/assignments/:id/group
+func (h *AssignmentGroupHandler) GetMyGroup(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	group, err := h.service.GetStudentGroup(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// JoinGroup — POST /api/student/assignments/:id/groups/:group_id/join
+func (h *AssignmentGroupHandler) JoinGroup(c *gin.Context) {
+	assignmentID, groupID, ok := parseGroupParams(c)
+	if !ok {
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	group, err := h.service.JoinGroup(assignmentID, groupID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// LeaveGroup — DELETE /api/student/assignments/:id/group
+func (h *AssignmentGroupHandler) LeaveGroup(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	if err := h.service.LeaveGroup(assignmentID, studentID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "left group"})
+}
+
+func parseGroupParams(c *gin.Context) (uint, uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, 0, false
+	}
+	groupID, err := strconv.ParseUint(c.Param("group_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return 0, 0, false
+	}
+	return assignmentID, uint(groupID), true
+}
@@ -0,0 +1,163 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/moderation"
+)
+
+// ModerationHandler — двойная проверка: настройки, слепые оценки двух проверяющих
+// и решение модератора (преподавателя курса) по расхождениям.
+type ModerationHandler struct {
+	svc *moderation.Service
+}
+
+func NewModerationHandler(svc *moderation.Service) *ModerationHandler {
+	return &ModerationHandler{svc: svc}
+}
+
+// GET /api/teacher/assignments/:id/moderation
+func (h *ModerationHandler) GetSettings(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	settings, err := h.svc.GetSettings(assignmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// PUT /api/teacher/assignments/:id/moderation
+// Пока двойная проверка включена, оценки задания ставятся только через неё.
+func (h *ModerationHandler) Configure(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	var req moderation.SettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid moderation settings: " + err.Error()})
+		return
+	}
+	settings, err := h.svc.Configure(assignmentID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// GET /api/teacher/assignments/:id/moderation/cases?status=pending|agreed|disputed|resolved
+// Модератору: обе оценки по каждой работе, расхождения первыми.
+func (h *ModerationHandler) Cases(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", models.ModerationPending, models.ModerationAgreed, models.ModerationDisputed, models.ModerationResolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	cases, err := h.svc.Cases(assignmentID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+// POST /api/teacher/moderation/cases/:id/resolve
+func (h *ModerationHandler) Resolve(c *gin.Context) {
+	caseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid case id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	var req moderation.ResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score is required"})
+		return
+	}
+	resolved, err := h.svc.Resolve(uint(caseID), teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resolved)
+}
+
+// GET /api/teacher/moderation/assignments/:id/work
+// Проверяющему: сданные работы и только его собственные оценки.
+func (h *ModerationHandler) Worklist(c *gin.Context) {
+	assignmentID, markerID, ok := h.markerAssignment(c)
+	if !ok {
+		return
+	}
+	items, err := h.svc.Worklist(assignmentID, markerID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// POST /api/teacher/moderation/assignments/:id/marks
+func (h *ModerationHandler) SubmitMark(c *gin.Context) {
+	assignmentID, markerID, ok := h.markerAssignment(c)
+	if !ok {
+		return
+	}
+	var req moderation.MarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mark: " + err.Error()})
+		return
+	}
+	result, err := h.svc.SubmitMark(assignmentID, markerID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// markerAssignment — проверяющий может не быть преподавателем курса; роль проверяет сервис.
+func (h *ModerationHandler) markerAssignment(c *gin.Context) (uint, uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, 0, false
+	}
+	markerID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	return assignmentID, markerID, true
+}
+
+func (h *ModerationHandler) teacherAssignment(c *gin.Context) (uint, uint, bool) {
+	assignmentID, teacherID, ok := h.markerAssignment(c)
+	if !ok {
+		return 0, 0, false
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return assignmentID, teacherID, true
+}
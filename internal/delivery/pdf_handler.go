@@ -7,13 +7,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"rest-project/internal/db"
+	"rest-project/internal/models"
+	"rest-project/internal/services/analytics"
+	"rest-project/internal/services/gradebook"
+	"rest-project/internal/services/gradescale"
 	"rest-project/internal/services/pdfgen"
 )
 
-type PDFHandler struct{}
+type PDFHandler struct {
+	scales *gradescale.Service // optional — оценки по шкале курса
+	book   *gradebook.Service  // optional — итоговые оценки по журналу
+}
 
 func NewPDFHandler() *PDFHandler { return &PDFHandler{} }
 
+// SetGrading — добавляет в отчёт оценки по шкале и итоговые оценки журнала.
+func (h *PDFHandler) SetGrading(scales *gradescale.Service, book *gradebook.Service) {
+	h.scales = scales
+	h.book = book
+}
+
 // CourseReport — GET /api/teacher/courses/:id/report.pdf
 func (h *PDFHandler) CourseReport(c *gin.Context) {
 	courseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -53,7 +66,7 @@ func (h *PDFHandler) CourseReport(c *gin.Context) {
 		Scan(&summary.StudentsCount)
 
 	gdb.Table("grades g").
-		Select("COALESCE(AVG(g.score), 0)").
+		Select("COALESCE(AVG("+analytics.ScorePercentSQL+"), 0)").
 		Joins("JOIN assignments a ON a.id = g.assignment_id").
 		Where("a.course_id = ? AND g.deleted_at IS NULL", courseID).
 		Scan(&summary.AvgScore)
@@ -63,12 +76,13 @@ func (h *PDFHandler) CourseReport(c *gin.Context) {
 		StudentName     string
 		AssignmentTitle string
 		Score           float64
+		LatePenalty     float64
 		MaxScore        float64
 		UpdatedAt       time.Time
 	}
 	var raws []rowRaw
 	gdb.Table("grades g").
-		Select("u.username AS student_name, a.title AS assignment_title, g.score, a.max_score, g.updated_at").
+		Select("u.username AS student_name, a.title AS assignment_title, g.score, g.late_penalty, a.max_score, g.updated_at").
 		Joins("JOIN users u ON u.id = g.student_id").
 		Joins("JOIN assignments a ON a.id = g.assignment_id").
 		Where("a.course_id = ? AND g.deleted_at IS NULL", courseID).
@@ -76,15 +90,45 @@ func (h *PDFHandler) CourseReport(c *gin.Context) {
 		Limit(200).
 		Scan(&raws)
 
+	// Шкала курса (если задана)
+	var scale *models.GradingScaleResponse
+	var scaleName string
+	if h.scales != nil {
+		scale, _ = h.scales.ForCourse(course.ID)
+	}
+	if scale != nil {
+		scaleName = scale.Name
+	}
+
 	rows := make([]pdfgen.CourseReportRow, 0, len(raws))
 	for _, r := range raws {
-		rows = append(rows, pdfgen.CourseReportRow{
+		row := pdfgen.CourseReportRow{
 			StudentName:     r.StudentName,
 			AssignmentTitle: r.AssignmentTitle,
 			Score:           r.Score,
 			MaxScore:        r.MaxScore,
 			GradedAt:        r.UpdatedAt,
-		})
+		}
+		if scale != nil && r.MaxScore > 0 {
+			if g := scale.Apply(gradescale.Percent(r.Score, r.LatePenalty, r.MaxScore)); g != nil {
+				row.Symbol = g.Symbol
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	// Итоговые оценки по журналу курса
+	var finals []pdfgen.CourseFinalGrade
+	if h.book != nil {
+		if matrix, err := h.book.Matrix(teacherID, course.ID); err == nil {
+			for _, r := range matrix.Rows {
+				final := pdfgen.CourseFinalGrade{StudentName: r.StudentName, Percent: r.FinalPercent}
+				if r.FinalGrade != nil {
+					final.Symbol = r.FinalGrade.Symbol
+				}
+				finals = append(finals, final)
+			}
+		}
 	}
 
 	pdfBytes, err := pdfgen.BuildCourseReportPDF(pdfgen.CourseReport{
@@ -94,6 +138,8 @@ func (h *PDFHandler) CourseReport(c *gin.Context) {
 		StudentsCount: int(summary.StudentsCount),
 		AvgScore:      summary.AvgScore,
 		Rows:          rows,
+		ScaleName:     scaleName,
+		FinalGrades:   finals,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
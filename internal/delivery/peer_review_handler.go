@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/services/peerreview"
+)
+
+type PeerReviewHandler struct {
+	svc *peerreview.Service
+}
+
+func NewPeerReviewHandler(svc *peerreview.Service) *PeerReviewHandler {
+	return &PeerReviewHandler{svc: svc}
+}
+
+type peerReviewSettingsInput struct {
+	Enabled                *bool   `json:"enabled"`
+	ReviewersPerSubmission int     `json:"reviewers_per_submission"`
+	ReviewDueDate          string  `json:"review_due_date" binding:"required"` // RFC3339
+	BlendWeight            float64 `json:"blend_weight"`
+}
+
+// GET /api/teacher/assignments/:id/peer-review
+// Баптаулар, барлық рецензиялар және рецензенттер сапасы.
+func (h *PeerReviewHandler) Report(c *gin.Context) {
+	assignmentID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	report, err := h.svc.Report(assignmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// PUT /api/teacher/assignments/:id/peer-review
+func (h *PeerReviewHandler) Configure(c *gin.Context) {
+	assignmentID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	var input peerReviewSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid peer review settings: " + err.Error()})
+		return
+	}
+	dueDate, err := time.Parse(time.RFC3339, input.ReviewDueDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review_due_date (RFC3339)"})
+		return
+	}
+
+	settings, err := h.svc.Configure(assignmentID, peerreview.SettingsRequest{
+		Enabled:                input.Enabled,
+		ReviewersPerSubmission: input.ReviewersPerSubmission,
+		ReviewDueDate:          dueDate,
+		BlendWeight:            input.BlendWeight,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// POST /api/teacher/assignments/:id/peer-review/allocate
+// Мерзім біткеннен кейін рецензенттерді қолмен бөлу (әдетте фонда орындалады).
+func (h *PeerReviewHandler) Allocate(c *gin.Context) {
+	assignmentID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	count, err := h.svc.Allocate(assignmentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"allocated": count})
+}
+
+// GET /api/student/peer-reviews
+func (h *PeerReviewHandler) MyReviews(c *gin.Context) {
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	tasks, err := h.svc.ListForReviewer(studentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GET /api/student/peer-reviews/:id
+func (h *PeerReviewHandler) GetReview(c *gin.Context) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	task, err := h.svc.GetForReviewer(uint(reviewID), studentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// POST /api/student/peer-reviews/:id
+func (h *PeerReviewHandler) SubmitReview(c *gin.Context) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review id"})
+		return
+	}
+	var input peerreview.SubmitRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review data: " + err.Error()})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	task, err := h.svc.SubmitReview(uint(reviewID), studentID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// GET /api/student/assignments/:id/peer-feedback
+// Өз жұмысына келген анонимді рецензиялар.
+func (h *PeerReviewHandler) ReceivedReviews(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	reviews, err := h.svc.ReceivedReviews(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+func (h *PeerReviewHandler) teacherAssignment(c *gin.Context) (uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, false
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	return assignmentID, true
+}
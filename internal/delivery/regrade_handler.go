@@ -0,0 +1,182 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/regrade"
+)
+
+// RegradeHandler — апелляции студентов на оценку и решения преподавателя.
+type RegradeHandler struct {
+	svc *regrade.Service
+}
+
+func NewRegradeHandler(svc *regrade.Service) *RegradeHandler {
+	return &RegradeHandler{svc: svc}
+}
+
+type regradeRejectInput struct {
+	Reply string `json:"reply" binding:"required"`
+}
+
+type regradeWindowInput struct {
+	WindowDays *int `json:"window_days" binding:"required"`
+}
+
+// POST /api/student/regrade-requests
+// Запрос на перепроверку всей оценки, критерия рубрики (criterion_id) или вопроса теста (question_id).
+func (h *RegradeHandler) Create(c *gin.Context) {
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	var req regrade.CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	request, err := h.svc.Create(studentID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, request)
+}
+
+// GET /api/student/regrade-requests
+func (h *RegradeHandler) ListMine(c *gin.Context) {
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	requests, err := h.svc.ListForStudent(studentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// POST /api/student/regrade-requests/:id/withdraw
+func (h *RegradeHandler) Withdraw(c *gin.Context) {
+	id, userID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Withdraw(id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// GET /api/teacher/regrade-requests?assignment_id=&status=pending|accepted|rejected|withdrawn
+func (h *RegradeHandler) List(c *gin.Context) {
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	assignmentID, _ := strconv.ParseUint(c.Query("assignment_id"), 10, 64)
+	status := c.Query("status")
+	switch status {
+	case "", models.RegradeStatusPending, models.RegradeStatusAccepted, models.RegradeStatusRejected, models.RegradeStatusWithdrawn:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	requests, err := h.svc.ListForTeacher(teacherID, uint(assignmentID), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// POST /api/teacher/regrade-requests/:id/accept
+// Оценка меняется через обычный путь изменения — с записью в истории оценки.
+func (h *RegradeHandler) Accept(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	var req regrade.AcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	request, err := h.svc.Accept(id, teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// POST /api/teacher/regrade-requests/:id/reject
+func (h *RegradeHandler) Reject(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	var input regradeRejectInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reply is required"})
+		return
+	}
+	request, err := h.svc.Reject(id, teacherID, input.Reply)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+// PUT /api/teacher/assignments/:id/regrade-window
+// Сколько дней после публикации оценки принимаются запросы (0 — не принимаются).
+func (h *RegradeHandler) SetWindow(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	var input regradeWindowInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window_days is required"})
+		return
+	}
+	if err := h.svc.SetWindow(assignmentID, *input.WindowDays); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assignment_id": assignmentID, "window_days": *input.WindowDays})
+}
+
+func (h *RegradeHandler) params(c *gin.Context) (uint, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid regrade request id"})
+		return 0, 0, false
+	}
+	userID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	return uint(id), userID, true
+}
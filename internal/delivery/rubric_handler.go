@@ -0,0 +1,255 @@
+package delivery
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/rubric"
+)
+
+// RubricHandler — библиотека рубрик преподавателя: версии, общий доступ,
+// импорт/экспорт и привязка к заданиям.
+type RubricHandler struct {
+	svc *rubric.Service
+}
+
+func NewRubricHandler(svc *rubric.Service) *RubricHandler {
+	return &RubricHandler{svc: svc}
+}
+
+type attachRubricInput struct {
+	RubricID uint `json:"rubric_id" binding:"required"`
+	Version  int  `json:"version"` // 0 — текущая версия
+}
+
+// GET /api/teacher/rubrics?visibility=mine|public
+func (h *RubricHandler) List(c *gin.Context) {
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	rubrics, err := h.svc.List(teacherID, c.Query("visibility"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rubrics)
+}
+
+// GET /api/teacher/rubrics/:id?version=
+func (h *RubricHandler) Get(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	version, _ := strconv.Atoi(c.Query("version"))
+	resp, err := h.svc.Get(id, teacherID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /api/teacher/rubrics
+func (h *RubricHandler) Create(c *gin.Context) {
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	var req rubric.RubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rubric: " + err.Error()})
+		return
+	}
+	resp, err := h.svc.Create(teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// PUT /api/teacher/rubrics/:id
+// Изменение критериев создаёт новую версию; привязанные задания не меняются до push.
+func (h *RubricHandler) Update(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	var req rubric.RubricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rubric: " + err.Error()})
+		return
+	}
+	resp, err := h.svc.Update(id, teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DELETE /api/teacher/rubrics/:id
+func (h *RubricHandler) Delete(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(id, teacherID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// GET /api/teacher/rubrics/:id/versions
+func (h *RubricHandler) Versions(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	versions, err := h.svc.Versions(id, teacherID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// POST /api/teacher/rubrics/:id/clone
+func (h *RubricHandler) Clone(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	resp, err := h.svc.Clone(id, teacherID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GET /api/teacher/rubrics/:id/export?version=
+func (h *RubricHandler) Export(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	version, _ := strconv.Atoi(c.Query("version"))
+	data, err := h.svc.Export(id, teacherID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="rubric_%d_v%d.json"`, id, data.Version))
+	c.JSON(http.StatusOK, data)
+}
+
+// POST /api/teacher/rubrics/import
+func (h *RubricHandler) Import(c *gin.Context) {
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	var data models.RubricExport
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rubric file: " + err.Error()})
+		return
+	}
+	resp, err := h.svc.Import(teacherID, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// POST /api/teacher/rubrics/:id/push
+// Текущая версия рубрики переносится в привязанные задания преподавателя без оценок.
+func (h *RubricHandler) Push(c *gin.Context) {
+	id, teacherID, ok := h.params(c)
+	if !ok {
+		return
+	}
+	result, err := h.svc.Push(id, teacherID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// PUT /api/teacher/assignments/:id/rubric
+// Копирует критерии версии рубрики в задание.
+func (h *RubricHandler) Attach(c *gin.Context) {
+	assignmentID, teacherID, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	var input attachRubricInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+	resp, err := h.svc.Attach(assignmentID, teacherID, input.RubricID, input.Version)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"assignment_id": assignmentID, "rubric": resp})
+}
+
+// DELETE /api/teacher/assignments/:id/rubric
+// Отвязывает задание от рубрики; критерии остаются в задании.
+func (h *RubricHandler) Detach(c *gin.Context) {
+	assignmentID, _, ok := h.teacherAssignment(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Detach(assignmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *RubricHandler) params(c *gin.Context) (uint, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rubric id"})
+		return 0, 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	return uint(id), teacherID, true
+}
+
+func (h *RubricHandler) teacherAssignment(c *gin.Context) (uint, uint, bool) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return 0, 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	if err := ensureTeacherOwnsAssignment(assignmentID, teacherID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return assignmentID, teacherID, true
+}
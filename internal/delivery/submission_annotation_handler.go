@@ -0,0 +1,179 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services"
+)
+
+type annotationReplyRequest struct {
+	Body string `json:"body"`
+}
+
+// ListAnnotations — GET /api/teacher/submissions/:id/annotations
+func (h *AssignmentSubmissionHandler) ListAnnotations(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+
+	threads, err := h.service.ListAnnotationsForTeacher(submissionID, teacherID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	h.maskAnnotations(submissionID, teacherID, threads)
+	c.JSON(http.StatusOK, threads)
+}
+
+// CreateAnnotation — POST /api/teacher/submissions/:id/annotations
+// Тело: {"start_offset": 10, "end_offset": 42, "body": "..."} — смещения в символах content.
+func (h *AssignmentSubmissionHandler) CreateAnnotation(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	var req services.AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	annotation, recipients, err := h.service.CreateAnnotation(submissionID, teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.notifyAnnotation(annotation, recipients)
+	c.JSON(http.StatusCreated, annotation)
+}
+
+// ReplyToAnnotation — POST /api/teacher/submissions/:id/annotations/:annotation_id/replies
+func (h *AssignmentSubmissionHandler) ReplyToAnnotation(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	annotationID, ok := parseAnnotationID(c)
+	if !ok {
+		return
+	}
+	var req annotationReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	reply, recipients, err := h.service.ReplyAsTeacher(submissionID, teacherID, annotationID, req.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.notifyAnnotation(reply, recipients)
+	c.JSON(http.StatusCreated, reply)
+}
+
+// DeleteAnnotation — DELETE /api/teacher/submissions/:id/annotations/:annotation_id
+func (h *AssignmentSubmissionHandler) DeleteAnnotation(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	annotationID, ok := parseAnnotationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAnnotation(submissionID, teacherID, annotationID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// ListStudentAnnotations — GET /api/student/assignments/:id/submission/annotations
+// Комментарии видны после выставления оценки или возврата работы на доработку.
+func (h *AssignmentSubmissionHandler) ListStudentAnnotations(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	threads, err := h.service.ListStudentAnnotations(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, threads)
+}
+
+// ReplyAsStudent — POST /api/student/assignments/:id/submission/annotations/:annotation_id/replies
+func (h *AssignmentSubmissionHandler) ReplyAsStudent(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	annotationID, ok := parseAnnotationID(c)
+	if !ok {
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+	var req annotationReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	reply, recipients, err := h.service.ReplyAsStudent(assignmentID, studentID, annotationID, req.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Преподаватель при анонимной проверке видит псевдоним автора
+	notified := []models.SubmissionAnnotation{*reply}
+	h.maskAnnotations(reply.SubmissionID, 0, notified)
+	h.notifyAnnotation(&notified[0], recipients)
+	c.JSON(http.StatusCreated, reply)
+}
+
+// notifyAnnotation — WS-событие submission_comment участникам обсуждения.
+func (h *AssignmentSubmissionHandler) notifyAnnotation(annotation *models.SubmissionAnnotation, recipients []uint) {
+	if h.hub == nil {
+		return
+	}
+	payload := map[string]any{
+		"submission_id": annotation.SubmissionID,
+		"annotation_id": annotation.ID,
+		"parent_id":     annotation.ParentID,
+		"author_id":     annotation.AuthorID,
+		"author_name":   annotation.AuthorName,
+		"body":          annotation.Body,
+	}
+	for _, id := range recipients {
+		h.hub.SendToUser(id, "submission_comment", payload)
+	}
+}
+
+func parseAnnotationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("annotation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid annotation id"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -1,19 +1,32 @@
 package delivery
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"rest-project/internal/db"
+	"rest-project/internal/models"
 	"rest-project/internal/services"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/gradescale"
 	"rest-project/internal/services/notifier"
+	"rest-project/internal/services/storage"
 )
 
 type AssignmentSubmissionHandler struct {
 	service *services.AssignmentSubmissionService
-	hub     *notifier.Hub // optional
+	hub     *notifier.Hub           // optional
+	storage storage.StorageService // optional
+	anon    *anonymize.Service     // optional — анонимная проверка
+	scales  *gradescale.Service    // optional — оценка по шкале курса
 }
 
 func NewAssignmentSubmissionHandler(service *services.AssignmentSubmissionService) *AssignmentSubmissionHandler {
@@ -25,6 +38,21 @@ func (h *AssignmentSubmissionHandler) SetHub(hub *notifier.Hub) {
 	h.hub = hub
 }
 
+// SetAnonymizer — скрывает студентов в teacher-эндпоинтах при анонимной проверке.
+func (h *AssignmentSubmissionHandler) SetAnonymizer(anon *anonymize.Service) {
+	h.anon = anon
+}
+
+// SetScales — показывает оценку студента по шкале курса.
+func (h *AssignmentSubmissionHandler) SetScales(scales *gradescale.Service) {
+	h.scales = scales
+}
+
+// SetStorage — подключает объектное хранилище для файлов студентов (опционально).
+func (h *AssignmentSubmissionHandler) SetStorage(s storage.StorageService) {
+	h.storage = s
+}
+
 func (h *AssignmentSubmissionHandler) GetStudentSubmission(c *gin.Context) {
 	assignmentID, err := parseAssignmentID(c)
 	if err != nil {
@@ -43,6 +71,11 @@ func (h *AssignmentSubmissionHandler) GetStudentSubmission(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if submission.Grade != nil && h.scales != nil {
+		grades := []models.Grade{*submission.Grade}
+		h.scales.ApplyToGrades(grades)
+		submission.Grade.Scaled = grades[0].Scaled
+	}
 
 	c.JSON(http.StatusOK, submission)
 }
@@ -110,15 +143,207 @@ func (h *AssignmentSubmissionHandler) notifyTeacher(assignmentID, studentID uint
 	if err != nil || info.TeacherID == 0 {
 		return
 	}
-	h.hub.SendToUser(info.TeacherID, "submission_submitted", map[string]any{
+	payload := map[string]any{
 		"assignment_id":    assignmentID,
 		"student_id":       studentID,
 		"student_name":     info.StudentName,
 		"assignment_title": info.AssignmentTitle,
 		"course_title":     info.CourseTitle,
-	})
+	}
+	if h.anon != nil {
+		if labels, ok := h.anon.Labels(assignmentID, []uint{studentID}); ok {
+			payload["student_name"] = labels[studentID]
+			delete(payload, "student_id")
+		}
+	}
+	h.hub.SendToUser(info.TeacherID, "submission_submitted", payload)
+}
+
+// submissionFileResponse — файл метадерегімен және уақытша сілтемемен
+type submissionFileResponse struct {
+	models.Attachment
+	URL string `json:"url"`
+}
+
+// multipartOverhead — multipart шекаралары мен тақырыптарына қосымша орын
+const multipartOverhead = 1 << 20
+
+// UploadFile — POST /api/student/assignments/:id/submission/files (multipart, поле "file")
+func (h *AssignmentSubmissionHandler) UploadFile(c *gin.Context) {
+	if h.storage == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage disabled"})
+		return
+	}
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	// Денені тапсырма шегімен шектейміз — үлкен файл дискке толық жазылмасын
+	limit, err := h.service.UploadSizeLimit(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file too large (max %d MB)", limit>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	submission, err := h.service.PrepareFileUpload(assignmentID, studentID, header.Filename, header.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objectName := submissionObjectName(assignmentID, submission.ID, header.Filename)
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ctx, cancel := contextWithTimeout(c, 30*time.Second)
+	defer cancel()
+	if err := h.storage.PutObject(ctx, objectName, file, header.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed: " + err.Error()})
+		return
+	}
+
+	attachment := &models.Attachment{
+		OwnerID:     studentID,
+		ObjectKey:   objectName,
+		Filename:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		SizeBytes:   header.Size,
+	}
+	if err := h.service.AddSubmissionFile(submission, attachment); err != nil {
+		_ = h.storage.RemoveObject(ctx, objectName)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, h.fileResponse(c, *attachment))
+}
+
+// ListFiles — GET /api/student/assignments/:id/submission/files
+func (h *AssignmentSubmissionHandler) ListFiles(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	files, err := h.service.ListStudentFiles(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.fileResponses(c, files))
 }
 
+// DeleteFile — DELETE /api/student/assignments/:id/submission/files/:file_id (только черновик)
+func (h *AssignmentSubmissionHandler) DeleteFile(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	fileID, err := strconv.ParseUint(c.Param("file_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	removed, err := h.service.RemoveStudentFile(assignmentID, studentID, uint(fileID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Best-effort удаление из storage
+	if h.storage != nil {
+		ctx, cancel := contextWithTimeout(c, 5*time.Second)
+		defer cancel()
+		_ = h.storage.RemoveObject(ctx, removed.ObjectKey)
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// ListSubmissionFiles — GET /api/teacher/submissions/:id/files (только преподаватель курса)
+func (h *AssignmentSubmissionHandler) ListSubmissionFiles(c *gin.Context) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission id"})
+		return
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	files, err := h.service.ListSubmissionFilesForTeacher(uint(submissionID), teacherID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	h.maskFiles(uint(submissionID), files)
+	c.JSON(http.StatusOK, h.fileResponses(c, files))
+}
+
+func (h *AssignmentSubmissionHandler) fileResponse(c *gin.Context, a models.Attachment) submissionFileResponse {
+	url := ""
+	if h.storage != nil {
+		ctx, cancel := contextWithTimeout(c, 5*time.Second)
+		defer cancel()
+		url, _ = h.storage.PresignGet(ctx, a.ObjectKey, 15*time.Minute)
+	}
+	return submissionFileResponse{Attachment: a, URL: url}
+}
+
+func (h *AssignmentSubmissionHandler) fileResponses(c *gin.Context, files []models.Attachment) []submissionFileResponse {
+	out := make([]submissionFileResponse, 0, len(files))
+	for _, f := range files {
+		out = append(out, h.fileResponse(c, f))
+	}
+	return out
+}
+
+// submissionObjectName — файлы студентов хранятся отдельно от uploads/ преподавателей.
+func submissionObjectName(assignmentID, submissionID uint, filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		ext = ".bin"
+	}
+	return fmt.Sprintf("%s%d/%d/%s%s", submissionObjectPrefix, assignmentID, submissionID, uuid.NewString(), ext)
+}
+
+// submissionObjectPrefix — ключи файлов студентов; общие teacher-эндпоинты их не выдают.
+const submissionObjectPrefix = "submissions/"
+
 func parseAssignmentID(c *gin.Context) (uint, error) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	return uint(id), err
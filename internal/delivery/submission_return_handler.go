@@ -0,0 +1,66 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rest-project/internal/db"
+	"rest-project/internal/models"
+	"rest-project/internal/services"
+)
+
+// ReturnSubmission — POST /api/teacher/submissions/:id/return
+// Тело: {"comment": "...", "due_date": "2025-05-01T18:00:00Z"}
+func (h *AssignmentSubmissionHandler) ReturnSubmission(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	var req services.ReturnSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	ret, studentIDs, err := h.service.ReturnSubmission(submissionID, teacherID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if h.hub != nil {
+		go h.notifyReturned(ret, studentIDs)
+	}
+	c.JSON(http.StatusOK, ret)
+}
+
+// ListReturns — GET /api/teacher/submissions/:id/returns (все раунды доработки)
+func (h *AssignmentSubmissionHandler) ListReturns(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+
+	returns, err := h.service.ListReturnsForTeacher(submissionID, teacherID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, returns)
+}
+
+// notifyReturned — WS-событие submission_returned каждому автору работы.
+func (h *AssignmentSubmissionHandler) notifyReturned(ret *models.SubmissionReturn, studentIDs []uint) {
+	var assignmentID uint
+	db.DB.Table("assignment_submissions").Select("assignment_id").Where("id = ?", ret.SubmissionID).Scan(&assignmentID)
+	payload := map[string]any{
+		"assignment_id": assignmentID,
+		"submission_id": ret.SubmissionID,
+		"round":         ret.Round,
+		"comment":       ret.Comment,
+		"due_date":      ret.DueDate,
+	}
+	for _, id := range studentIDs {
+		h.hub.SendToUser(id, "submission_returned", payload)
+	}
+}
@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRevisions — GET /api/student/assignments/:id/submission/revisions
+func (h *AssignmentSubmissionHandler) ListRevisions(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	revisions, err := h.service.ListStudentRevisions(assignmentID, studentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision — GET /api/student/assignments/:id/submission/revisions/:number
+func (h *AssignmentSubmissionHandler) GetRevision(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	revision, err := h.service.GetStudentRevision(assignmentID, studentID, number)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions — GET /api/student/assignments/:id/submission/revisions/diff?from=&to=
+func (h *AssignmentSubmissionHandler) DiffRevisions(c *gin.Context) {
+	assignmentID, err := parseAssignmentID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid assignment id"})
+		return
+	}
+	from, to, ok := parseDiffRange(c)
+	if !ok {
+		return
+	}
+	studentID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return
+	}
+
+	diff, err := h.service.DiffStudentRevisions(assignmentID, studentID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// ListSubmissionRevisions — GET /api/teacher/submissions/:id/revisions
+func (h *AssignmentSubmissionHandler) ListSubmissionRevisions(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.ListRevisionsForTeacher(submissionID, teacherID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	h.maskRevisions(submissionID, revisions)
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetSubmissionRevision — GET /api/teacher/submissions/:id/revisions/:number
+func (h *AssignmentSubmissionHandler) GetSubmissionRevision(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return
+	}
+
+	revision, err := h.service.GetRevisionForTeacher(submissionID, teacherID, number)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := h.submissionLabels(submissionID, nil); ok {
+		revision.AuthorID = 0
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffSubmissionRevisions — GET /api/teacher/submissions/:id/revisions/diff?from=&to=
+func (h *AssignmentSubmissionHandler) DiffSubmissionRevisions(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseDiffRange(c)
+	if !ok {
+		return
+	}
+
+	diff, err := h.service.DiffRevisionsForTeacher(submissionID, teacherID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// WritingTimeline — GET /api/teacher/submissions/:id/timeline
+// Сводка по автосохранениям: сколько слов и когда добавлялось, крупнейший «скачок».
+func (h *AssignmentSubmissionHandler) WritingTimeline(c *gin.Context) {
+	submissionID, teacherID, ok := parseTeacherSubmission(c)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.service.WritingTimeline(submissionID, teacherID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	h.maskTimeline(timeline)
+	c.JSON(http.StatusOK, timeline)
+}
+
+func parseTeacherSubmission(c *gin.Context) (uint, uint, bool) {
+	submissionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission id"})
+		return 0, 0, false
+	}
+	teacherID, ok := getCurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user is not authorized"})
+		return 0, 0, false
+	}
+	return uint(submissionID), teacherID, true
+}
+
+// parseDiffRange — to обязателен; from по умолчанию предыдущая версия (0 — пустой текст).
+func parseDiffRange(c *gin.Context) (int, int, bool) {
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to revision"})
+		return 0, 0, false
+	}
+	from := to - 1
+	if raw := c.Query("from"); raw != "" {
+		from, err = strconv.Atoi(raw)
+		if err != nil || from < 0 || from >= to {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be less than to"})
+			return 0, 0, false
+		}
+	}
+	return from, to, true
+}
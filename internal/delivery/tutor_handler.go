@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"rest-project/internal/models"
+	aiservice "rest-project/internal/services/ai"
 	"rest-project/internal/services/tutor"
 )
 
@@ -84,6 +86,15 @@ func (h *TutorHandler) Stream(c *gin.Context) {
 		return
 	}
 
+	if err := h.svc.CheckQuota(sid, uint(assignID)); err != nil {
+		if errors.Is(err, aiservice.ErrQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	session, err := h.svc.GetOrCreate(sid, uint(assignID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -120,7 +131,7 @@ func (h *TutorHandler) Stream(c *gin.Context) {
 
 	go func() {
 		defer close(ch)
-		h.svc.Stream(ctx, aiMsgs, ch)
+		h.svc.Stream(ctx, sid, uint(assignID), aiMsgs, ch)
 	}()
 
 	c.Stream(func(w io.Writer) bool {
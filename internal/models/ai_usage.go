@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// AIUsage — бір LLM жауабының токендері.
+type AIUsage struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserID           *uint     `json:"user_id,omitempty"`
+	CourseID         *uint     `json:"course_id,omitempty"`
+	Feature          string    `json:"feature"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Estimated        bool      `json:"estimated"` // провайдер usage қайтармады, мәтін ұзындығынан есептелді
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (AIUsage) TableName() string { return "ai_usage" }
+
+// AIQuota — токен лимиті: рөлге (UserID nil) немесе жеке пайдаланушыға. 0 — шексіз.
+type AIQuota struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Role          *string   `json:"role,omitempty"`
+	UserID        *uint     `json:"user_id,omitempty"`
+	DailyTokens   int64     `json:"daily_tokens"`
+	MonthlyTokens int64     `json:"monthly_tokens"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	Username string `gorm:"->;column:username" json:"username,omitempty"`
+}
+
+func (AIQuota) TableName() string { return "ai_quotas" }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// SubmissionAnnotation — жұмыс мәтінінің бөлігіне (таңбалар ауқымы) мұғалім пікірі.
+// ParentID толтырылса — тармақтағы жауап, ауқымы жоқ.
+type SubmissionAnnotation struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SubmissionID   uint      `gorm:"not null" json:"submission_id"`
+	ParentID       *uint     `json:"parent_id,omitempty"`
+	AuthorID       uint      `gorm:"not null" json:"author_id,omitempty"`
+	AuthorName     string    `gorm:"->" json:"author_name"`
+	RevisionNumber *int      `json:"revision_number,omitempty"`
+	StartOffset    int       `json:"start_offset"` // таңба (rune) индексі, қоса
+	EndOffset      int       `json:"end_offset"`   // қоспай
+	Quote          string    `gorm:"type:text" json:"quote,omitempty"`
+	Body           string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Replies []SubmissionAnnotation `gorm:"-" json:"replies,omitempty"`
+}
+
+func (SubmissionAnnotation) TableName() string { return "submission_annotations" }
@@ -12,6 +12,31 @@ type AssignmentType string
 const (
 	AssignmentTypeEssay AssignmentType = "essay"
 	AssignmentTypeTest  AssignmentType = "test"
+	AssignmentTypeFile  AssignmentType = "file" // студент файл жүктейді
+	AssignmentTypeCode  AssignmentType = "code" // бағдарлама тест-кейстермен тексеріледі
+)
+
+// IsValidAssignmentType — белгілі тапсырма түрі ме
+func IsValidAssignmentType(t string) bool {
+	switch AssignmentType(t) {
+	case AssignmentTypeEssay, AssignmentTypeTest, AssignmentTypeFile, AssignmentTypeCode:
+		return true
+	}
+	return false
+}
+
+// Бағаларды жариялау тәртібі
+const (
+	GradeReleaseImmediate = "immediate" // баға қойылған сәтте көрінеді
+	GradeReleaseManual    = "manual"    // мұғалім барлығын бірге жариялайды
+	GradeReleaseScheduled = "scheduled" // grades_release_at уақытында
+)
+
+// Кешігу саясаты
+const (
+	LatePolicyNone          = "none"
+	LatePolicyPercentPerDay = "percent_per_day" // late_penalty — әр күнге пайыз
+	LatePolicyFixed         = "fixed"           // late_penalty — бір реттік балл
 )
 
 // EssayCriterion — эссе критерийі
@@ -25,6 +50,7 @@ type EssayCriterion struct {
 	AutoCheckable bool    `json:"auto_checkable,omitempty"`
 	CheckPrompt   string  `json:"check_prompt,omitempty"`
 	OrderIndex    int     `json:"order_index,omitempty"`
+	Levels        []RubricLevel `json:"levels,omitempty"` // рубрика деңгейлері
 }
 
 // TestQuestion — тест сұрағы
@@ -44,10 +70,34 @@ type Assignment struct {
 	CourseID    uint           `json:"course_id"`
 	DueDate     time.Time      `json:"due_date"`
 	MaxScore    float64        `gorm:"default:100" json:"max_score"`
+	MaxBonus    float64        `gorm:"default:0" json:"max_bonus"` // max_score-тан жоғары қосымша балл шегі
 	Type        string         `gorm:"default:'essay'" json:"type"`
 	Criteria    string         `gorm:"type:text" json:"-"` // JSON string — сервисте парсталады
 	Questions   string         `gorm:"type:text" json:"-"` // JSON string — сервисте парсталады
 	WordCount   int            `gorm:"default:0" json:"word_count"`
+	LatePolicy  string         `gorm:"default:'none'" json:"late_policy"`
+	LatePenalty float64        `gorm:"default:0" json:"late_penalty"`
+	CloseDate   *time.Time     `json:"close_date,omitempty"` // осыдан кейін тапсыруға болмайды
+	IsDraft     bool           `gorm:"default:false" json:"is_draft"`
+	PublishAt   *time.Time     `json:"publish_at,omitempty"`   // осы уақыттан бастап студентке көрінеді
+	PublishedAt *time.Time     `json:"published_at,omitempty"` // жарияланып, хабарлама жіберілген уақыт
+	GroupMode   string         `gorm:"default:'none'" json:"group_mode"`
+	MaxGroupSize int           `gorm:"default:0" json:"max_group_size"`
+	AllowAttachments  bool     `gorm:"default:false" json:"allow_attachments"` // эссеге файл тіркеуге бола ма
+	AllowedExtensions string   `gorm:"default:''" json:"allowed_extensions"`   // ".pdf,.docx"; бос — кез келген
+	MaxFileSizeMB     int      `gorm:"default:0" json:"max_file_size_mb"`      // 0 — жүйелік шек (25 MB)
+	CodeLanguage string        `gorm:"default:''" json:"code_language"`
+	StarterCode  string        `gorm:"type:text" json:"starter_code"`
+	TestCases    string        `gorm:"type:text" json:"-"` // JSON []CodeTestCase — сервисте парсталады
+	AnonymousGrading bool      `gorm:"default:false" json:"anonymous_grading"` // мұғалім студент аттарын көрмейді
+	DeanonymizedAt *time.Time  `json:"deanonymized_at,omitempty"`              // аттар ашылған уақыт
+	GradebookCategoryID *uint  `json:"gradebook_category_id,omitempty"`        // журналдағы санаты
+	RubricID     *uint         `json:"rubric_id,omitempty"`                     // кітапханадағы рубрика
+	RubricVersion *int         `json:"rubric_version,omitempty"`                // критерийлер алынған нұсқа
+	GradeReleaseMode string    `gorm:"default:'immediate'" json:"grade_release_mode"`
+	GradesReleaseAt  *time.Time `json:"grades_release_at,omitempty"`  // scheduled: жариялау уақыты
+	GradesReleasedAt *time.Time `json:"grades_released_at,omitempty"` // бағалар жарияланған уақыт
+	RegradeWindowDays int      `gorm:"default:7" json:"regrade_window_days"` // апелляция мерзімі (күн), 0 — жабық
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -57,6 +107,44 @@ type Assignment struct {
 	Grades []*Grade `json:"grades,omitempty"`
 }
 
+// IsVisibleToStudents — черновик емес және publish_at уақыты келген
+func (a *Assignment) IsVisibleToStudents(now time.Time) bool {
+	if a.IsDraft {
+		return false
+	}
+	return a.PublishAt == nil || !a.PublishAt.After(now)
+}
+
+// GradesVisible — студент бағаны көре ала ма: бірден жариялау, мұғалім жариялаған
+// немесе жоспарланған уақыт келген.
+func (a *Assignment) GradesVisible(now time.Time) bool {
+	switch {
+	case a.GradeReleaseMode == "" || a.GradeReleaseMode == GradeReleaseImmediate:
+		return true
+	case a.GradesReleasedAt != nil:
+		return true
+	case a.GradeReleaseMode == GradeReleaseScheduled && a.GradesReleaseAt != nil:
+		return !a.GradesReleaseAt.After(now)
+	}
+	return false
+}
+
+// AcceptsFiles — студент файл жүктей ала ма
+func (a *Assignment) AcceptsFiles() bool {
+	return a.Type == string(AssignmentTypeFile) ||
+		(a.Type == string(AssignmentTypeEssay) && a.AllowAttachments)
+}
+
+// MaxAllowedScore — қойылатын бағаның жоғарғы шегі (бонусты қоса)
+func (a *Assignment) MaxAllowedScore() float64 {
+	return a.MaxScore + a.MaxBonus
+}
+
+// IsGroupAssignment — топпен орындалатын тапсырма ма
+func (a *Assignment) IsGroupAssignment() bool {
+	return a.GroupMode != "" && a.GroupMode != GroupModeNone
+}
+
 // AssignmentResponse — API жауабы (criteria/questions парсталған)
 type AssignmentResponse struct {
 	ID          uint             `json:"id"`
@@ -65,10 +153,36 @@ type AssignmentResponse struct {
 	CourseID    uint             `json:"course_id"`
 	DueDate     time.Time        `json:"due_date"`
 	MaxScore    float64          `json:"max_score"`
+	MaxBonus    float64          `json:"max_bonus,omitempty"`
 	Type        string           `json:"type"`
 	WordCount   int              `json:"word_count,omitempty"`
+	LatePolicy  string           `json:"late_policy"`
+	LatePenalty float64          `json:"late_penalty,omitempty"`
+	CloseDate   *time.Time       `json:"close_date,omitempty"`
+	IsDraft     bool             `json:"is_draft"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty"`
+	PublishedAt *time.Time       `json:"published_at,omitempty"`
+	GroupMode   string           `json:"group_mode"`
+	MaxGroupSize int             `json:"max_group_size,omitempty"`
+	AllowAttachments  bool       `json:"allow_attachments"`
+	AllowedExtensions string     `json:"allowed_extensions,omitempty"`
+	MaxFileSizeMB     int        `json:"max_file_size_mb,omitempty"`
+	CodeLanguage string          `json:"code_language,omitempty"`
+	StarterCode  string          `json:"starter_code,omitempty"`
+	TestCases    []CodeTestCase  `json:"test_cases,omitempty"` // студентке тек ашық тесттер
+	AnonymousGrading bool        `json:"anonymous_grading"`
+	DeanonymizedAt *time.Time    `json:"deanonymized_at,omitempty"`
+	GradebookCategoryID *uint    `json:"gradebook_category_id,omitempty"`
+	RubricID     *uint           `json:"rubric_id,omitempty"`
+	RubricVersion *int           `json:"rubric_version,omitempty"`
+	GradeReleaseMode string      `json:"grade_release_mode"`
+	GradesReleaseAt  *time.Time  `json:"grades_release_at,omitempty"`
+	GradesReleasedAt *time.Time  `json:"grades_released_at,omitempty"`
+	RegradeWindowDays int        `json:"regrade_window_days"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 	Criteria    []EssayCriterion `json:"criteria,omitempty"`
 	Questions   []TestQuestion   `json:"questions,omitempty"` // student-та correctIndex=-1
+
+	ExtendedDueDate *time.Time `json:"extended_due_date,omitempty"` // тек студентке, ұзарту берілсе
 }
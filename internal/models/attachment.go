@@ -5,7 +5,7 @@ import "time"
 // Attachment — файл, привязанный к assignment / submission / course (или просто библиотека).
 type Attachment struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
-	OwnerID     uint      `json:"owner_id"`
+	OwnerID     uint      `json:"owner_id,omitempty"`
 	TargetType  string    `json:"target_type"` // "assignment" | "submission" | "course" | "free"
 	TargetID    *uint     `json:"target_id,omitempty"`
 	ObjectKey   string    `json:"object_key"`
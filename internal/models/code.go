@@ -0,0 +1,34 @@
+package models
+
+// CodeTestCase — code тапсырмасының тест-кейсі (stdin → күтілетін stdout)
+type CodeTestCase struct {
+	ID             int     `json:"id"`
+	Name           string  `json:"name"`
+	Input          string  `json:"input"`
+	ExpectedOutput string  `json:"expected_output"`
+	Hidden         bool    `json:"hidden"` // студентке көрсетілмейді, тек нәтижесі
+	Points         float64 `json:"points,omitempty"`
+}
+
+// Тест нәтижесінің күйі
+const (
+	CodeTestPassed  = "passed"
+	CodeTestFailed  = "failed"  // шығыс сәйкес келмеді
+	CodeTestError   = "error"   // runtime error / nonzero exit
+	CodeTestTimeout = "timeout" // уақыт немесе ресурс шегі
+)
+
+// CodeTestResult — бір тесттің нәтижесі. Жасырын тесттерде кіріс/шығыс көрсетілмейді.
+type CodeTestResult struct {
+	TestID     int     `json:"test_id"`
+	Name       string  `json:"name,omitempty"`
+	Hidden     bool    `json:"hidden"`
+	Status     string  `json:"status"`
+	Points     float64 `json:"points"`
+	PointsMax  float64 `json:"points_max"`
+	Input      string  `json:"input,omitempty"`
+	Expected   string  `json:"expected,omitempty"`
+	Actual     string  `json:"actual,omitempty"`
+	Stderr     string  `json:"stderr,omitempty"`
+	DurationMs int64   `json:"duration_ms"`
+}
@@ -11,6 +11,7 @@ type Course struct {
 	Title       string         `gorm:"not null" json:"title"`
 	Description string         `json:"description"`
 	TeacherID   uint           `json:"teacher_id"`
+	GradingScaleID *uint       `json:"grading_scale_id,omitempty"` // шкала оценивания курса
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -44,6 +45,7 @@ type CourseResponse struct {
 	AssignmentsCount int                        `json:"assignments_count"`
 	Students         []CourseStudentResponse    `json:"students"`
 	Assignments      []CourseAssignmentResponse `json:"assignments"`
+	GradingScaleID   *uint                      `json:"grading_scale_id,omitempty"`
 	CreatedAt        time.Time                  `json:"created_at"`
 	UpdatedAt        time.Time                  `json:"updated_at"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AssignmentExtension — мұғалім берген жеке deadline ұзарту
+type AssignmentExtension struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	AssignmentID uint      `gorm:"not null" json:"assignment_id"`
+	StudentID    uint      `gorm:"not null" json:"student_id"`
+	DueDate      time.Time `gorm:"not null" json:"due_date"`
+	Reason       string    `json:"reason"`
+	GrantedBy    uint      `gorm:"not null" json:"granted_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (AssignmentExtension) TableName() string { return "assignment_extensions" }
@@ -7,10 +7,17 @@ import (
 
 type Grade struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
-	StudentID    uint           `json:"student_id"`
+	StudentID    uint           `json:"student_id,omitempty"` // скрыт при анонимной проверке
 	AssignmentID uint           `json:"assignment_id"`
 	Score        float64        `json:"score"`
 	Feedback     string         `json:"feedback"`
+	LatePenalty  float64        `json:"late_penalty"` // штраф за опоздание (в баллах)
+	GroupID      *uint          `json:"group_id,omitempty"`
+	Adjustment   float64        `json:"adjustment"` // индивидуальная поправка к оценке группы
+	PeerScore    *float64       `json:"peer_score,omitempty"` // средняя peer-оценка, учтённая в score
+	RevisionNumber *int         `json:"revision_number,omitempty"` // версия работы, по которой выставлена оценка
+	Pseudonym    string         `gorm:"-" json:"pseudonym,omitempty"` // псевдоним студента при анонимной проверке
+	Scaled       *ScaledGrade   `gorm:"-" json:"scaled,omitempty"` // оценка по шкале курса
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -18,4 +25,5 @@ type Grade struct {
 	// Связи
 	Student      *User          `json:"student,omitempty"`
 	Assignment   *Assignment    `json:"assignment,omitempty"`
+	Criteria     []GradeCriterionScore `gorm:"foreignKey:GradeID" json:"criteria,omitempty"` // разбор по рубрике
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Баға тарихындағы әрекеттер
+const (
+	GradeActionCreate = "create"
+	GradeActionUpdate = "update"
+	GradeActionDelete = "delete"
+)
+
+// GradeHistory — бағаның өзгеру журналы (тек қосылады, өзгертілмейді).
+// ChangedBy nil — жүйе (автотексеру).
+type GradeHistory struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	GradeID       uint      `gorm:"not null" json:"grade_id"`
+	AssignmentID  uint      `gorm:"not null" json:"assignment_id"`
+	StudentID     uint      `gorm:"not null" json:"student_id,omitempty"` // анонимді тексеруде жасырылады
+	Action        string    `gorm:"not null" json:"action"`
+	OldScore      *float64  `json:"old_score"`
+	NewScore      *float64  `json:"new_score"`
+	OldFeedback   string    `json:"old_feedback"`
+	NewFeedback   string    `json:"new_feedback"`
+	Reason        string    `json:"reason"`
+	ChangedBy     *uint     `json:"changed_by"`
+	ChangedByName string    `gorm:"->;column:changed_by_name" json:"changed_by_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (GradeHistory) TableName() string { return "grade_history" }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// GradebookCategory — курс журналындағы тапсырмалар санаты (үй жұмысы, тест, емтихан)
+type GradebookCategory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CourseID   uint      `gorm:"not null" json:"course_id"`
+	Name       string    `gorm:"not null" json:"name"`
+	Weight     float64   `gorm:"not null" json:"weight"`       // қорытынды бағадағы үлесі, %
+	DropLowest int       `gorm:"default:0" json:"drop_lowest"` // есептен шығарылатын ең төмен бағалар саны
+	Position   int       `gorm:"default:0" json:"position"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (GradebookCategory) TableName() string { return "gradebook_categories" }
+
+// GradebookExemption — студент осы тапсырмадан босатылған, қорытындыға кірмейді
+type GradebookExemption struct {
+	AssignmentID uint      `gorm:"primaryKey" json:"assignment_id"`
+	StudentID    uint      `gorm:"primaryKey" json:"student_id"`
+	Reason       string    `json:"reason"`
+	CreatedBy    uint      `gorm:"not null" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (GradebookExemption) TableName() string { return "gradebook_exemptions" }
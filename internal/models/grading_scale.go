@@ -0,0 +1,78 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Шкала түрлері
+const (
+	GradingScaleLetter    = "letter"     // A–F, GPA
+	GradingScaleFivePoint = "five_point" // мектептегі 5 балдық жүйе
+	GradingScalePassFail  = "pass_fail"
+	GradingScaleCustom    = "custom"
+)
+
+// GradingScale — пайызды бағаға (әріп, балл, сынақ) айналдыратын шкала.
+// OwnerID nil — ұйым шкаласы (әкімші жасайды), әйтпесе мұғалімнің жеке шкаласы.
+type GradingScale struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Kind      string    `gorm:"default:'custom'" json:"kind"`
+	OwnerID   *uint     `json:"owner_id,omitempty"`
+	Levels    string    `gorm:"type:text" json:"-"` // JSON []ScaleLevel — сервисте парсталады
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (GradingScale) TableName() string { return "grading_scales" }
+
+// ScaleLevel — шкала деңгейі: min_percent және одан жоғары пайыз осы символды алады
+type ScaleLevel struct {
+	Symbol     string   `json:"symbol"`
+	MinPercent float64  `json:"min_percent"`
+	Points     *float64 `json:"points,omitempty"` // GPA немесе 5 балдық мән
+	Passing    bool     `json:"passing"`
+}
+
+// GradingScaleResponse — API жауабы (levels парсталған, жоғарыдан төмен)
+type GradingScaleResponse struct {
+	ID        uint         `json:"id"`
+	Name      string       `json:"name"`
+	Kind      string       `json:"kind"`
+	OwnerID   *uint        `json:"owner_id,omitempty"`
+	IsOrg     bool         `json:"is_org"`
+	Levels    []ScaleLevel `json:"levels"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ScaledGrade — шкала бойынша көрсетілген баға
+type ScaledGrade struct {
+	Scale   string   `json:"scale"`
+	Percent float64  `json:"percent"`
+	Symbol  string   `json:"symbol"`
+	Points  *float64 `json:"points,omitempty"`
+	Passing bool     `json:"passing"`
+}
+
+// Apply — пайызға сәйкес деңгейді табады; levels жоғарыдан төмен сұрыпталған болуы керек.
+func (r *GradingScaleResponse) Apply(percent float64) *ScaledGrade {
+	for _, level := range r.Levels {
+		if percent >= level.MinPercent {
+			return &ScaledGrade{
+				Scale:   r.Name,
+				Percent: percent,
+				Symbol:  level.Symbol,
+				Points:  level.Points,
+				Passing: level.Passing,
+			}
+		}
+	}
+	return nil
+}
+
+// SortScaleLevels — деңгейлерді min_percent бойынша кему ретімен сұрыптайды
+func SortScaleLevels(levels []ScaleLevel) {
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].MinPercent > levels[j].MinPercent })
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Топтық тапсырма режимі
+const (
+	GroupModeNone       = "none"
+	GroupModeTeacher    = "teacher"     // топтарды тек мұғалім құрады
+	GroupModeSelfSignup = "self_signup" // студенттер өздері қосылады (max_group_size шегі)
+)
+
+// AssignmentGroup — тапсырма бойынша студенттер тобы
+type AssignmentGroup struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	AssignmentID uint      `gorm:"not null" json:"assignment_id"`
+	Name         string    `gorm:"not null" json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Members []AssignmentGroupMember `gorm:"foreignKey:GroupID" json:"-"`
+}
+
+func (AssignmentGroup) TableName() string { return "assignment_groups" }
+
+type AssignmentGroupMember struct {
+	GroupID      uint      `gorm:"primaryKey" json:"group_id"`
+	StudentID    uint      `gorm:"primaryKey" json:"student_id"`
+	AssignmentID uint      `gorm:"not null" json:"assignment_id"`
+	JoinedAt     time.Time `gorm:"autoCreateTime" json:"joined_at"`
+
+	Student *User `gorm:"foreignKey:StudentID" json:"-"`
+}
+
+func (AssignmentGroupMember) TableName() string { return "assignment_group_members" }
+
+type GroupMemberResponse struct {
+	StudentID uint   `json:"student_id"`
+	Username  string `json:"username"`
+}
+
+// AssignmentGroupResponse — API жауабы (мүшелер аттарымен)
+type AssignmentGroupResponse struct {
+	ID           uint                  `json:"id"`
+	AssignmentID uint                  `json:"assignment_id"`
+	Name         string                `json:"name"`
+	Members      []GroupMemberResponse `json:"members"`
+	Size         int                   `json:"size"`
+	MaxSize      int                   `json:"max_size,omitempty"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Қос тексерудегі рөлдер
+const (
+	MarkerFirst  = "first"
+	MarkerSecond = "second"
+)
+
+// Модерация ісінің күйлері
+const (
+	ModerationPending  = "pending"  // бағалардың бірі әлі жоқ
+	ModerationAgreed   = "agreed"   // айырма шектен аспады — орташа балл қойылды
+	ModerationDisputed = "disputed" // айырма шектен асты — модератор шешеді
+	ModerationResolved = "resolved" // модератор қорытынды бағаны қойды
+)
+
+// ModerationSettings — тапсырманың қос тексеру баптаулары.
+// FirstMarkerID nil — бірінші тексеруші курс мұғалімі; Threshold — max_score-тың пайызы.
+type ModerationSettings struct {
+	AssignmentID   uint      `gorm:"primaryKey" json:"assignment_id"`
+	Enabled        bool      `gorm:"default:true" json:"enabled"`
+	FirstMarkerID  *uint     `json:"first_marker_id"`
+	SecondMarkerID uint      `gorm:"not null" json:"second_marker_id"`
+	Threshold      float64   `gorm:"default:10" json:"threshold"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (ModerationSettings) TableName() string { return "moderation_settings" }
+
+// ModerationMark — бір тексерушінің бағасы. Жазбалар жойылмайды — шешімнен кейін де сақталады.
+type ModerationMark struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	AssignmentID uint      `gorm:"not null" json:"assignment_id"`
+	StudentID    uint      `gorm:"not null" json:"student_id,omitempty"`
+	MarkerID     uint      `gorm:"not null" json:"marker_id"`
+	Role         string    `gorm:"not null" json:"role"`
+	Score        float64   `json:"score"`
+	Feedback     string    `gorm:"type:text" json:"feedback"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	MarkerName string `gorm:"->;column:marker_name" json:"marker_name,omitempty"`
+}
+
+func (ModerationMark) TableName() string { return "moderation_marks" }
+
+// ModerationCase — студент жұмысының қос тексеру күйі және модератор шешімі
+type ModerationCase struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	AssignmentID uint       `gorm:"not null" json:"assignment_id"`
+	StudentID    uint       `gorm:"not null" json:"student_id,omitempty"` // анонимді тексеруде жасырылады
+	Status       string     `gorm:"default:'pending'" json:"status"`
+	Difference   *float64   `json:"difference,omitempty"` // бағалар айырмасы, max_score-тың пайызы
+	FinalScore   *float64   `json:"final_score,omitempty"`
+	GradeID      *uint      `json:"grade_id,omitempty"`
+	ModeratorID  *uint      `json:"moderator_id,omitempty"`
+	Note         string     `gorm:"type:text" json:"note"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	StudentName string           `gorm:"-" json:"student_name,omitempty"`
+	Marks       []ModerationMark `gorm:"-" json:"marks,omitempty"`
+}
+
+func (ModerationCase) TableName() string { return "moderation_cases" }
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+const (
+	PeerReviewStatusPending   = "pending"
+	PeerReviewStatusSubmitted = "submitted"
+)
+
+// PeerReviewSettings — эссе тапсырмасы бойынша peer review баптаулары
+type PeerReviewSettings struct {
+	AssignmentID           uint       `gorm:"primaryKey" json:"assignment_id"`
+	Enabled                bool       `gorm:"default:true" json:"enabled"`
+	ReviewersPerSubmission int        `gorm:"default:2" json:"reviewers_per_submission"`
+	ReviewDueDate          time.Time  `json:"review_due_date"`
+	BlendWeight            float64    `gorm:"default:0" json:"blend_weight"` // 0 — peer бағасы қорытындыға әсер етпейді
+	AllocatedAt            *time.Time `json:"allocated_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+}
+
+func (PeerReviewSettings) TableName() string { return "peer_review_settings" }
+
+// PeerReview — бір рецензенттің бір жұмысқа берген бағасы
+type PeerReview struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	AssignmentID uint       `gorm:"not null" json:"assignment_id"`
+	SubmissionID uint       `gorm:"not null" json:"submission_id"`
+	ReviewerID   uint       `gorm:"not null" json:"reviewer_id"`
+	Status       string     `gorm:"default:'pending'" json:"status"`
+	Scores       string     `gorm:"type:text" json:"-"` // JSON []PeerCriterionScore
+	Comment      string     `gorm:"type:text" json:"comment"`
+	TotalScore   float64    `json:"total_score"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (PeerReview) TableName() string { return "peer_reviews" }
+
+// PeerCriterionScore — критерий бойынша балл
+type PeerCriterionScore struct {
+	CriterionID int     `json:"criterion_id"`
+	Score       float64 `json:"score"`
+	Comment     string  `json:"comment,omitempty"`
+}
+
+// PeerReviewTask — студентке көрсетілетін рецензия (автор жасырылған)
+type PeerReviewTask struct {
+	ID              uint                 `json:"id"`
+	AssignmentID    uint                 `json:"assignment_id"`
+	AssignmentTitle string               `json:"assignment_title"`
+	Content         string               `json:"content,omitempty"`
+	Criteria        []EssayCriterion     `json:"criteria,omitempty"`
+	Status          string               `json:"status"`
+	Scores          []PeerCriterionScore `json:"scores"`
+	Comment         string               `json:"comment"`
+	TotalScore      float64              `json:"total_score"`
+	ReviewDueDate   time.Time            `json:"review_due_date"`
+	SubmittedAt     *time.Time           `json:"submitted_at,omitempty"`
+}
+
+// ReceivedPeerReview — автор алған рецензия (рецензент жасырылған)
+type ReceivedPeerReview struct {
+	Label      string               `json:"label"` // "Reviewer 1", "Reviewer 2", ...
+	Scores     []PeerCriterionScore `json:"scores"`
+	Comment    string               `json:"comment"`
+	TotalScore float64              `json:"total_score"`
+}
+
+// PeerReviewReportRow — мұғалімге: кім кімді бағалады
+type PeerReviewReportRow struct {
+	ID            uint       `json:"id"`
+	SubmissionID  uint       `json:"submission_id"`
+	AuthorID      uint       `json:"author_id,omitempty"` // анонимді тексеруде жасырылады
+	AuthorName    string     `json:"author_name"`
+	ReviewerID    uint       `json:"reviewer_id,omitempty"`
+	ReviewerName  string     `json:"reviewer_name"`
+	Status        string     `json:"status"`
+	TotalScore    float64    `json:"total_score"`
+	TeacherScore  *float64   `json:"teacher_score,omitempty"`
+	PeerMean      float64    `json:"peer_mean"`
+	CommentLength int        `json:"comment_length"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty"`
+}
+
+// ReviewerQuality — рецензент сапасы: толықтығы және басқалардан/мұғалімнен ауытқуы
+type ReviewerQuality struct {
+	ReviewerID          uint     `json:"reviewer_id,omitempty"`
+	ReviewerName        string   `json:"reviewer_name"`
+	Assigned            int      `json:"assigned"`
+	Completed           int      `json:"completed"`
+	AvgDeviationPeers   float64  `json:"avg_deviation_peers"`
+	AvgDeviationTeacher *float64 `json:"avg_deviation_teacher,omitempty"`
+	AvgCommentLength    float64  `json:"avg_comment_length"`
+}
+
+type PeerReviewReport struct {
+	Settings  *PeerReviewSettings   `json:"settings"`
+	Reviews   []PeerReviewReportRow `json:"reviews"`
+	Reviewers []ReviewerQuality     `json:"reviewers"`
+}
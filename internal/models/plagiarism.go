@@ -18,8 +18,8 @@ func (PlagiarismReport) TableName() string { return "plagiarism_reports" }
 
 // PlagiarismPair — бір "күдікті" жұп.
 type PlagiarismPair struct {
-	StudentA       uint    `json:"student_a"`
-	StudentB       uint    `json:"student_b"`
+	StudentA       uint    `json:"student_a,omitempty"` // анонимді тексеруде жасырылады
+	StudentB       uint    `json:"student_b,omitempty"`
 	StudentAName   string  `json:"student_a_name,omitempty"`
 	StudentBName   string  `json:"student_b_name,omitempty"`
 	SubmissionA    uint    `json:"submission_a"`
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Қайта тексеру сұранысының күйлері
+const (
+	RegradeStatusPending   = "pending"
+	RegradeStatusAccepted  = "accepted"
+	RegradeStatusRejected  = "rejected"
+	RegradeStatusWithdrawn = "withdrawn"
+)
+
+// RegradeRequest — студенттің бағаға апелляциясы. Бүкіл бағаға, рубриканың бір критерийіне
+// немесе тесттің бір сұрағына қатысты болуы мүмкін.
+type RegradeRequest struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	GradeID       uint       `gorm:"not null" json:"grade_id"`
+	AssignmentID  uint       `gorm:"not null" json:"assignment_id"`
+	StudentID     uint       `gorm:"not null" json:"student_id,omitempty"` // анонимді тексеруде жасырылады
+	CriterionID   *int       `json:"criterion_id,omitempty"`
+	QuestionID    *int       `json:"question_id,omitempty"`
+	Justification string     `gorm:"type:text;not null" json:"justification"`
+	Status        string     `gorm:"default:'pending'" json:"status"`
+	Reply         string     `gorm:"type:text" json:"reply"`
+	OldScore      float64    `json:"old_score"`
+	NewScore      *float64   `json:"new_score,omitempty"`
+	ResolvedBy    *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	AssignmentTitle string `gorm:"->;column:assignment_title" json:"assignment_title,omitempty"`
+	StudentName     string `gorm:"->;column:student_name" json:"student_name,omitempty"` // анонимді тексеруде бүркеншік ат
+}
+
+func (RegradeRequest) TableName() string { return "regrade_requests" }
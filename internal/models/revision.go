@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	RevisionKindDraft  = "draft"
+	RevisionKindSubmit = "submit"
+)
+
+// SubmissionRevision — жұмыстың өзгермейтін нұсқасы (әр сақтау және тапсыру)
+type SubmissionRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	SubmissionID uint      `gorm:"not null" json:"submission_id"`
+	Number       int       `gorm:"not null" json:"number"`
+	AuthorID     uint      `gorm:"not null" json:"author_id,omitempty"` // анонимді тексеруде жасырылады
+	Kind         string    `gorm:"not null" json:"kind"`
+	Content      string    `gorm:"type:text" json:"content,omitempty"`
+	Answers      string    `gorm:"type:text" json:"-"`
+	WordCount    int       `json:"word_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (SubmissionRevision) TableName() string { return "submission_revisions" }
+
+// DiffLine — екі нұсқа арасындағы жол
+type DiffLine struct {
+	Op   string `json:"op"` // "equal" | "insert" | "delete"
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	From         int        `json:"from"`
+	To           int        `json:"to"`
+	LinesAdded   int        `json:"lines_added"`
+	LinesRemoved int        `json:"lines_removed"`
+	WordsDelta   int        `json:"words_delta"`
+	Lines        []DiffLine `json:"lines"`
+}
+
+// TimelinePoint — уақыт бойынша сөз саны
+type TimelinePoint struct {
+	Number       int       `json:"number"`
+	Kind         string    `json:"kind"`
+	AuthorID     uint      `json:"author_id,omitempty"`
+	At           time.Time `json:"at"`
+	WordCount    int       `json:"word_count"`
+	WordsAdded   int       `json:"words_added"`
+	WordsRemoved int       `json:"words_removed"`
+}
+
+// WritingTimeline — мұғалімге жазу барысының қорытындысы
+type WritingTimeline struct {
+	SubmissionID    uint            `json:"submission_id"`
+	Revisions       int             `json:"revisions"`
+	FirstSavedAt    *time.Time      `json:"first_saved_at,omitempty"`
+	SubmittedAt     *time.Time      `json:"submitted_at,omitempty"`
+	FinalWordCount  int             `json:"final_word_count"`
+	MaxJumpWords    int             `json:"max_jump_words"` // бір сақтаудағы ең үлкен өсім
+	MaxJumpAt       *time.Time      `json:"max_jump_at,omitempty"`
+	MaxJumpShare    float64         `json:"max_jump_share"`   // қорытынды мәтіннің қанша үлесі (0..1)
+	WordsLastHour   int             `json:"words_last_hour"`  // тапсыруға дейінгі соңғы сағатта қосылғаны
+	WritingSessions int             `json:"writing_sessions"` // 30 минуттан астам үзіліспен бөлінген
+	Points          []TimelinePoint `json:"points"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxRubricLevels — бір критерийдегі деңгейлердің жоғарғы шегі
+const MaxRubricLevels = 10
+
+// RubricLevel — критерий деңгейі: атауы, ұпайы және сипаттамасы
+// (мысалы, "Өте жақсы" — 10 балл, "Дәлелдер толық және нақты").
+type RubricLevel struct {
+	Label       string  `json:"label"`
+	Points      float64 `json:"points"`
+	Description string  `json:"description,omitempty"`
+}
+
+// GradeCriterionScore — бағаның бір критерий бойынша бөлігі
+type GradeCriterionScore struct {
+	GradeID       uint    `gorm:"primaryKey" json:"-"`
+	CriterionID   int     `gorm:"primaryKey" json:"criterion_id"`
+	AssignmentID  uint    `gorm:"not null" json:"-"`
+	CriterionName string  `json:"criterion_name"`
+	Score         float64 `json:"score"`
+	MaxScore      float64 `json:"max_score"`
+	Level         string  `json:"level,omitempty"`
+	Comment       string  `json:"comment,omitempty"`
+}
+
+func (GradeCriterionScore) TableName() string {
+	return "grade_criterion_scores"
+}
+
+// CriterionScoreInput — мұғалім (немесе AI) берген критерий бағасы.
+// Score берілмесе, таңдалған деңгейдің ұпайы алынады.
+type CriterionScoreInput struct {
+	CriterionID int      `json:"criterion_id"`
+	Name        string   `json:"name,omitempty"` // criterion_id жоқ болса, атауы бойынша сәйкестендіріледі
+	Score       *float64 `json:"score"`
+	Level       string   `json:"level,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+}
+
+// RubricSuggestion — AI бағалаушының критерийлер бойынша ұсынысы (мұғалімге алдын ала толтыру үшін)
+type RubricSuggestion struct {
+	Criteria  []GradeCriterionScore `json:"criteria"`
+	Score     float64               `json:"score"`
+	Feedback  string                `json:"feedback,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// ParseEssayCriteria — тапсырманың JSON критерийлерін оқиды; ID жоқ болса, реттік нөмір беріледі.
+func ParseEssayCriteria(raw string) []EssayCriterion {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	var criteria []EssayCriterion
+	if err := json.Unmarshal([]byte(raw), &criteria); err != nil {
+		return nil
+	}
+	for i := range criteria {
+		if criteria[i].ID == 0 {
+			criteria[i].ID = i + 1
+		}
+	}
+	return criteria
+}
+
+// PointsMax — критерийдің ең жоғары балы (maxPoints, болмаса max_score).
+func (c EssayCriterion) PointsMax() float64 {
+	if c.MaxPoints > 0 {
+		return float64(c.MaxPoints)
+	}
+	return float64(c.MaxScore)
+}
+
+// FindLevel — атауы бойынша деңгейді табады (регистрге қарамайды).
+func (c EssayCriterion) FindLevel(label string) (*RubricLevel, bool) {
+	for i := range c.Levels {
+		if strings.EqualFold(strings.TrimSpace(c.Levels[i].Label), strings.TrimSpace(label)) {
+			return &c.Levels[i], true
+		}
+	}
+	return nil, false
+}
+
+// ValidateRubricLevels — деңгейлер атауы бірегей, ұпайы 0..критерий максимумы аралығында.
+// Деңгейлер ұпайы бойынша кему ретімен сұрыпталады.
+func ValidateRubricLevels(criteria []EssayCriterion) error {
+	for i := range criteria {
+		c := &criteria[i]
+		if len(c.Levels) == 0 {
+			continue
+		}
+		if len(c.Levels) > MaxRubricLevels {
+			return fmt.Errorf("criterion %q: at most %d levels allowed", c.Name, MaxRubricLevels)
+		}
+		max := c.PointsMax()
+		labels := map[string]bool{}
+		for j := range c.Levels {
+			level := &c.Levels[j]
+			level.Label = strings.TrimSpace(level.Label)
+			if level.Label == "" || len([]rune(level.Label)) > 100 {
+				return fmt.Errorf("criterion %q: level label must be 1-100 characters", c.Name)
+			}
+			key := strings.ToLower(level.Label)
+			if labels[key] {
+				return fmt.Errorf("criterion %q: duplicate level %q", c.Name, level.Label)
+			}
+			labels[key] = true
+			if level.Points < 0 || (max > 0 && level.Points > max) {
+				return fmt.Errorf("criterion %q: level points must be between 0 and %g", c.Name, max)
+			}
+		}
+		sort.SliceStable(c.Levels, func(a, b int) bool {
+			return c.Levels[a].Points > c.Levels[b].Points
+		})
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RubricExportFormat — импорт/экспорт файлының нұсқасы
+const RubricExportFormat = "rubric/v1"
+
+// Rubric — мұғалімнің рубрикалар кітапханасындағы рубрика.
+// Критерийлер нұсқаларда сақталады; IsPublic — ұйымның барлық мұғалімдеріне көрінеді.
+type Rubric struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	OwnerID        uint           `gorm:"not null" json:"owner_id"`
+	Title          string         `gorm:"not null" json:"title"`
+	Description    string         `json:"description"`
+	IsPublic       bool           `gorm:"not null;default:false" json:"is_public"`
+	CurrentVersion int            `gorm:"not null;default:1" json:"current_version"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Rubric) TableName() string { return "rubrics" }
+
+// RubricVersion — рубриканың өзгермейтін нұсқасы
+type RubricVersion struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RubricID  uint      `gorm:"not null" json:"rubric_id"`
+	Version   int       `gorm:"not null" json:"version"`
+	Criteria  string    `gorm:"type:text" json:"-"` // JSON []EssayCriterion
+	Note      string    `json:"note,omitempty"`
+	CreatedBy *uint     `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RubricVersion) TableName() string { return "rubric_versions" }
+
+// RubricResponse — API жауабы: рубрика және таңдалған нұсқаның критерийлері
+type RubricResponse struct {
+	ID             uint             `json:"id"`
+	OwnerID        uint             `json:"owner_id"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	IsPublic       bool             `json:"is_public"`
+	IsOwner        bool             `json:"is_owner"`
+	CurrentVersion int              `json:"current_version"`
+	Version        int              `json:"version"`
+	Criteria       []EssayCriterion `json:"criteria"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// RubricVersionResponse — нұсқалар тарихы
+type RubricVersionResponse struct {
+	Version   int              `json:"version"`
+	Note      string           `json:"note,omitempty"`
+	Criteria  []EssayCriterion `json:"criteria"`
+	CreatedBy *uint            `json:"created_by,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// RubricExport — басқа жүйеге немесе мұғалімге беруге арналған JSON
+type RubricExport struct {
+	Format      string           `json:"format"`
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Version     int              `json:"version,omitempty"`
+	Criteria    []EssayCriterion `json:"criteria"`
+}
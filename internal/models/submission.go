@@ -11,6 +11,7 @@ const (
 	SubmissionStatusSubmitted = "submitted"
 	SubmissionStatusLate      = "late"
 	SubmissionStatusGraded    = "graded"
+	SubmissionStatusReturned  = "returned" // мұғалім қайта өңдеуге қайтарды
 )
 
 type TestAnswer struct {
@@ -38,6 +39,11 @@ type AssignmentSubmission struct {
 	Status       string         `gorm:"not null;default:'draft'" json:"status"`
 	WordCount    int            `gorm:"not null;default:0" json:"word_count"`
 	SubmittedAt  *time.Time     `json:"submitted_at,omitempty"`
+	GroupID      *uint          `json:"group_id,omitempty"` // топтық тапсырмада — ортақ жұмыс
+	TestResults  string         `gorm:"type:text" json:"-"`  // code: JSON []CodeTestResult
+	Round        int            `gorm:"not null;default:1" json:"round"`
+	ReturnDueDate *time.Time    `json:"return_due_date,omitempty"` // қайтарылғаннан кейінгі жаңа мерзім
+	AIRubric     string         `gorm:"type:text" json:"-"`  // JSON RubricSuggestion — AI критерий бойынша ұсынысы
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -55,8 +61,16 @@ type AssignmentSubmissionResponse struct {
 	Status       string               `json:"status"`
 	WordCount    int                  `json:"word_count"`
 	SubmittedAt  *time.Time           `json:"submitted_at,omitempty"`
+	GroupID      *uint                `json:"group_id,omitempty"`
+	Round        int                  `json:"round"`
+	ReturnDueDate *time.Time          `json:"return_due_date,omitempty"`
+	Returns      []SubmissionReturn   `json:"returns,omitempty"` // қайтару раундтары және пікірлер
+	Annotations  []SubmissionAnnotation `json:"annotations,omitempty"` // мәтін бөліктеріне пікірлер (баға шыққан соң)
+	Files        []Attachment         `json:"files,omitempty"` // file/эссе тапсырмасына жүктелген файлдар
 	CreatedAt    time.Time            `json:"created_at"`
 	UpdatedAt    time.Time            `json:"updated_at"`
 	Grade        *Grade               `json:"grade,omitempty"`
 	TestReview   []TestQuestionReview `json:"test_review,omitempty"`
+	TestResults  []CodeTestResult     `json:"test_results,omitempty"`
+	GradingJobID string               `json:"grading_job_id,omitempty"` // code: автотексеру тапсырмасы
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SubmissionReturn — мұғалімнің жұмысты қайта өңдеуге қайтаруы (бір раунд)
+type SubmissionReturn struct {
+	ID                  uint       `gorm:"primaryKey" json:"id"`
+	SubmissionID        uint       `gorm:"not null" json:"submission_id"`
+	Round               int        `gorm:"not null" json:"round"`
+	TeacherID           uint       `gorm:"not null" json:"teacher_id"`
+	Comment             string     `gorm:"type:text" json:"comment"`
+	DueDate             time.Time  `gorm:"not null" json:"due_date"`
+	RevisionNumber      *int       `json:"revision_number,omitempty"`
+	ResubmittedRevision *int       `json:"resubmitted_revision,omitempty"`
+	ResubmittedAt       *time.Time `json:"resubmitted_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+func (SubmissionReturn) TableName() string { return "submission_returns" }
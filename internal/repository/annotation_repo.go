@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+type SubmissionAnnotationRepository interface {
+	GetByID(id uint) (*models.SubmissionAnnotation, error)
+	GetBySubmissionID(submissionID uint) ([]models.SubmissionAnnotation, error)
+	Create(annotation *models.SubmissionAnnotation) error
+	Delete(id uint) error
+}
+
+type SubmissionAnnotationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSubmissionAnnotationRepository(db *gorm.DB) *SubmissionAnnotationRepositoryImpl {
+	return &SubmissionAnnotationRepositoryImpl{db: db}
+}
+
+func (r *SubmissionAnnotationRepositoryImpl) withAuthor() *gorm.DB {
+	return r.db.Model(&models.SubmissionAnnotation{}).
+		Select("submission_annotations.*, users.username AS author_name").
+		Joins("JOIN users ON users.id = submission_annotations.author_id")
+}
+
+func (r *SubmissionAnnotationRepositoryImpl) GetByID(id uint) (*models.SubmissionAnnotation, error) {
+	var annotation models.SubmissionAnnotation
+	err := r.withAuthor().Where("submission_annotations.id = ?", id).First(&annotation).Error
+	return &annotation, err
+}
+
+// GetBySubmissionID — все комментарии работы (корни и ответы) в порядке создания.
+func (r *SubmissionAnnotationRepositoryImpl) GetBySubmissionID(submissionID uint) ([]models.SubmissionAnnotation, error) {
+	var annotations []models.SubmissionAnnotation
+	err := r.withAuthor().
+		Where("submission_annotations.submission_id = ?", submissionID).
+		Order("submission_annotations.created_at ASC, submission_annotations.id ASC").
+		Find(&annotations).Error
+	return annotations, err
+}
+
+func (r *SubmissionAnnotationRepositoryImpl) Create(annotation *models.SubmissionAnnotation) error {
+	return r.db.Create(annotation).Error
+}
+
+func (r *SubmissionAnnotationRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.SubmissionAnnotation{}, id).Error
+}
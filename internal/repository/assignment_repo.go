@@ -41,7 +41,15 @@ func (r *AssignmentRepositoryImpl) Create(assignment *models.Assignment) error {
 }
 
 func (r *AssignmentRepositoryImpl) Update(id uint, assignment *models.Assignment) error {
-	return r.db.Model(&models.Assignment{}).Where("id = ?", id).Updates(assignment).Error
+	return r.db.Model(&models.Assignment{}).
+		Where("id = ?", id).
+		Select("title", "description", "due_date", "max_score", "max_bonus", "type", "criteria", "questions", "word_count",
+			"late_policy", "late_penalty", "close_date", "is_draft", "publish_at", "published_at",
+			"group_mode", "max_group_size",
+			"allow_attachments", "allowed_extensions", "max_file_size_mb",
+			"code_language", "starter_code", "test_cases",
+			"anonymous_grading", "deanonymized_at").
+		Updates(assignment).Error
 }
 
 func (r *AssignmentRepositoryImpl) Delete(id uint) error {
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+type AttachmentRepository interface {
+	GetByID(id uint) (*models.Attachment, error)
+	GetByTarget(targetType string, targetID uint) ([]models.Attachment, error)
+	Create(attachment *models.Attachment) error
+	Delete(id uint) error
+}
+
+type AttachmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepositoryImpl {
+	return &AttachmentRepositoryImpl{db: db}
+}
+
+func (r *AttachmentRepositoryImpl) GetByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.First(&attachment, id).Error
+	return &attachment, err
+}
+
+func (r *AttachmentRepositoryImpl) GetByTarget(targetType string, targetID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.
+		Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *AttachmentRepositoryImpl) Create(attachment *models.Attachment) error {
+	return r.db.Create(attachment).Error
+}
+
+func (r *AttachmentRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.Attachment{}, id).Error
+}
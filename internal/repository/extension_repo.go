@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"rest-project/internal/models"
+)
+
+type AssignmentExtensionRepository interface {
+	GetByStudentAndAssignment(studentID, assignmentID uint) (*models.AssignmentExtension, error)
+	GetByAssignmentID(assignmentID uint) ([]models.AssignmentExtension, error)
+	Upsert(extension *models.AssignmentExtension) error
+	Delete(assignmentID, studentID uint) error
+}
+
+type AssignmentExtensionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssignmentExtensionRepository(db *gorm.DB) *AssignmentExtensionRepositoryImpl {
+	return &AssignmentExtensionRepositoryImpl{db: db}
+}
+
+func (r *AssignmentExtensionRepositoryImpl) GetByStudentAndAssignment(studentID, assignmentID uint) (*models.AssignmentExtension, error) {
+	var extension models.AssignmentExtension
+	err := r.db.Where("student_id = ? AND assignment_id = ?", studentID, assignmentID).First(&extension).Error
+	return &extension, err
+}
+
+func (r *AssignmentExtensionRepositoryImpl) GetByAssignmentID(assignmentID uint) ([]models.AssignmentExtension, error) {
+	var extensions []models.AssignmentExtension
+	err := r.db.Where("assignment_id = ?", assignmentID).Order("student_id ASC").Find(&extensions).Error
+	return extensions, err
+}
+
+// Upsert — одно продление на пару (assignment, student); повторная выдача перезаписывает срок.
+func (r *AssignmentExtensionRepositoryImpl) Upsert(extension *models.AssignmentExtension) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "assignment_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"due_date", "reason", "granted_by", "updated_at"}),
+	}).Create(extension).Error
+}
+
+func (r *AssignmentExtensionRepositoryImpl) Delete(assignmentID, studentID uint) error {
+	return r.db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Delete(&models.AssignmentExtension{}).Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+// GradeHistoryRepository — журнал изменений оценок; только добавление и чтение.
+type GradeHistoryRepository interface {
+	Create(entry *models.GradeHistory) error
+	GetByGradeID(gradeID uint) ([]models.GradeHistory, error)
+}
+
+type GradeHistoryRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewGradeHistoryRepository(db *gorm.DB) *GradeHistoryRepositoryImpl {
+	return &GradeHistoryRepositoryImpl{db: db}
+}
+
+func (r *GradeHistoryRepositoryImpl) Create(entry *models.GradeHistory) error {
+	return r.db.Omit("ChangedByName").Create(entry).Error
+}
+
+func (r *GradeHistoryRepositoryImpl) GetByGradeID(gradeID uint) ([]models.GradeHistory, error) {
+	var entries []models.GradeHistory
+	err := r.db.Table("grade_history h").
+		Select("h.*, u.username AS changed_by_name").
+		Joins("LEFT JOIN users u ON u.id = h.changed_by").
+		Where("h.grade_id = ?", gradeID).
+		Order("h.created_at ASC, h.id ASC").
+		Scan(&entries).Error
+	return entries, err
+}
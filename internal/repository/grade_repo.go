@@ -14,6 +14,9 @@ type GradeRepository interface {
 	GetGradesByStudentID(studentID uint) ([]models.Grade, error)
 	GetGradesByAssignmentID(assignmentID uint) ([]models.Grade, error)
 	GetGradeByStudentAndAssignment(studentID, assignmentID uint) (*models.Grade, error)
+	ReplaceCriteria(gradeID uint, scores []models.GradeCriterionScore) error
+	// Transaction — оценка и запись журнала пишутся вместе или не пишутся вовсе
+	Transaction(fn func(grades GradeRepository, history GradeHistoryRepository) error) error
 }
 
 type GradeRepositoryImpl struct {
@@ -32,7 +35,7 @@ func (r *GradeRepositoryImpl) GetAll() ([]models.Grade, error) {
 
 func (r *GradeRepositoryImpl) GetByID(id uint) (*models.Grade, error) {
 	var grade models.Grade
-	err := r.db.First(&grade, id).Error
+	err := r.withCriteria().First(&grade, id).Error
 	return &grade, err
 }
 
@@ -43,7 +46,7 @@ func (r *GradeRepositoryImpl) Create(grade *models.Grade) error {
 func (r *GradeRepositoryImpl) Update(id uint, grade *models.Grade) error {
 	return r.db.Model(&models.Grade{}).
 		Where("id = ?", id).
-		Select("score", "feedback").
+		Select("score", "feedback", "late_penalty", "group_id", "adjustment", "peer_score", "revision_number").
 		Updates(grade).Error
 }
 
@@ -53,18 +56,47 @@ func (r *GradeRepositoryImpl) Delete(id uint) error {
 
 func (r *GradeRepositoryImpl) GetGradesByStudentID(studentID uint) ([]models.Grade, error) {
 	var grades []models.Grade
-	err := r.db.Where("student_id = ?", studentID).Find(&grades).Error
+	err := r.withCriteria().Where("student_id = ?", studentID).Find(&grades).Error
 	return grades, err
 }
 
 func (r *GradeRepositoryImpl) GetGradesByAssignmentID(assignmentID uint) ([]models.Grade, error) {
 	var grades []models.Grade
-	err := r.db.Where("assignment_id = ?", assignmentID).Find(&grades).Error
+	err := r.withCriteria().Where("assignment_id = ?", assignmentID).Find(&grades).Error
 	return grades, err
 }
 
 func (r *GradeRepositoryImpl) GetGradeByStudentAndAssignment(studentID, assignmentID uint) (*models.Grade, error) {
 	var grade models.Grade
-	err := r.db.Where("student_id = ? AND assignment_id = ?", studentID, assignmentID).First(&grade).Error
+	err := r.withCriteria().Where("student_id = ? AND assignment_id = ?", studentID, assignmentID).First(&grade).Error
 	return &grade, err
 }
+
+// ReplaceCriteria — заменяет разбор оценки по рубрике; пустой список удаляет разбор.
+func (r *GradeRepositoryImpl) ReplaceCriteria(gradeID uint, scores []models.GradeCriterionScore) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("grade_id = ?", gradeID).Delete(&models.GradeCriterionScore{}).Error; err != nil {
+			return err
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+		for i := range scores {
+			scores[i].GradeID = gradeID
+		}
+		return tx.Create(&scores).Error
+	})
+}
+
+// Transaction — fn получает репозитории оценок и журнала, работающие в одной транзакции.
+func (r *GradeRepositoryImpl) Transaction(fn func(grades GradeRepository, history GradeHistoryRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&GradeRepositoryImpl{db: tx}, &GradeHistoryRepositoryImpl{db: tx})
+	})
+}
+
+func (r *GradeRepositoryImpl) withCriteria() *gorm.DB {
+	return r.db.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("criterion_id ASC")
+	})
+}
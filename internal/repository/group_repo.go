@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"rest-project/internal/models"
+)
+
+type AssignmentGroupRepository interface {
+	GetByID(id uint) (*models.AssignmentGroup, error)
+	GetByAssignmentID(assignmentID uint) ([]models.AssignmentGroup, error)
+	GetByStudentAndAssignment(studentID, assignmentID uint) (*models.AssignmentGroup, error)
+	Create(group *models.AssignmentGroup) error
+	Update(id uint, group *models.AssignmentGroup) error
+	Delete(id uint) error
+	AddMember(member *models.AssignmentGroupMember) error
+	AddMemberLimited(member *models.AssignmentGroupMember, maxSize int) error
+	RemoveMember(groupID, studentID uint) error
+	ReplaceMembers(group *models.AssignmentGroup, studentIDs []uint) error
+}
+
+type AssignmentGroupRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAssignmentGroupRepository(db *gorm.DB) *AssignmentGroupRepositoryImpl {
+	return &AssignmentGroupRepositoryImpl{db: db}
+}
+
+func (r *AssignmentGroupRepositoryImpl) GetByID(id uint) (*models.AssignmentGroup, error) {
+	var group models.AssignmentGroup
+	err := r.db.Preload("Members.Student").First(&group, id).Error
+	return &group, err
+}
+
+func (r *AssignmentGroupRepositoryImpl) GetByAssignmentID(assignmentID uint) ([]models.AssignmentGroup, error) {
+	var groups []models.AssignmentGroup
+	err := r.db.Preload("Members.Student").
+		Where("assignment_id = ?", assignmentID).
+		Order("id ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *AssignmentGroupRepositoryImpl) GetByStudentAndAssignment(studentID, assignmentID uint) (*models.AssignmentGroup, error) {
+	var group models.AssignmentGroup
+	err := r.db.Preload("Members.Student").
+		Joins("JOIN assignment_group_members m ON m.group_id = assignment_groups.id").
+		Where("m.student_id = ? AND m.assignment_id = ?", studentID, assignmentID).
+		First(&group).Error
+	return &group, err
+}
+
+func (r *AssignmentGroupRepositoryImpl) Create(group *models.AssignmentGroup) error {
+	return r.db.Create(group).Error
+}
+
+func (r *AssignmentGroupRepositoryImpl) Update(id uint, group *models.AssignmentGroup) error {
+	return r.db.Model(&models.AssignmentGroup{}).
+		Where("id = ?", id).
+		Select("name").
+		Updates(group).Error
+}
+
+func (r *AssignmentGroupRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.AssignmentGroup{}, id).Error
+}
+
+func (r *AssignmentGroupRepositoryImpl) AddMember(member *models.AssignmentGroupMember) error {
+	return r.db.Create(member).Error
+}
+
+// ErrGroupFull — в группе уже maxSize участников.
+var ErrGroupFull = errors.New("group is full")
+
+// AddMemberLimited — строка группы блокируется (FOR UPDATE), поэтому параллельные
+// вступления не превысят maxSize. maxSize <= 0 — без ограничения.
+func (r *AssignmentGroupRepositoryImpl) AddMemberLimited(member *models.AssignmentGroupMember, maxSize int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var group models.AssignmentGroup
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, member.GroupID).Error; err != nil {
+			return err
+		}
+		if maxSize > 0 {
+			var count int64
+			if err := tx.Model(&models.AssignmentGroupMember{}).
+				Where("group_id = ?", member.GroupID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count >= int64(maxSize) {
+				return ErrGroupFull
+			}
+		}
+		return tx.Create(member).Error
+	})
+}
+
+func (r *AssignmentGroupRepositoryImpl) RemoveMember(groupID, studentID uint) error {
+	return r.db.Where("group_id = ? AND student_id = ?", groupID, studentID).
+		Delete(&models.AssignmentGroupMember{}).Error
+}
+
+// ReplaceMembers — состав группы целиком (в транзакции).
+func (r *AssignmentGroupRepositoryImpl) ReplaceMembers(group *models.AssignmentGroup, studentIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.AssignmentGroupMember{}).Error; err != nil {
+			return err
+		}
+		for _, id := range studentIDs {
+			member := &models.AssignmentGroupMember{
+				GroupID:      group.ID,
+				StudentID:    id,
+				AssignmentID: group.AssignmentID,
+			}
+			if err := tx.Create(member).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
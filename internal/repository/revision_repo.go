@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+type SubmissionRevisionRepository interface {
+	Create(revision *models.SubmissionRevision) error
+	GetBySubmissionID(submissionID uint) ([]models.SubmissionRevision, error)
+	GetByNumber(submissionID uint, number int) (*models.SubmissionRevision, error)
+	GetLatest(submissionID uint) (*models.SubmissionRevision, error)
+}
+
+type SubmissionRevisionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSubmissionRevisionRepository(db *gorm.DB) *SubmissionRevisionRepositoryImpl {
+	return &SubmissionRevisionRepositoryImpl{db: db}
+}
+
+// Create — номер версии = последний + 1 (строка submission блокируется на время вставки).
+func (r *SubmissionRevisionRepositoryImpl) Create(revision *models.SubmissionRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT id FROM assignment_submissions WHERE id = ? FOR UPDATE", revision.SubmissionID).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&models.SubmissionRevision{}).
+			Where("submission_id = ?", revision.SubmissionID).
+			Select("COALESCE(MAX(number), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		revision.Number = last + 1
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *SubmissionRevisionRepositoryImpl) GetBySubmissionID(submissionID uint) ([]models.SubmissionRevision, error) {
+	var revisions []models.SubmissionRevision
+	err := r.db.Where("submission_id = ?", submissionID).
+		Order("number ASC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *SubmissionRevisionRepositoryImpl) GetByNumber(submissionID uint, number int) (*models.SubmissionRevision, error) {
+	var revision models.SubmissionRevision
+	err := r.db.Where("submission_id = ? AND number = ?", submissionID, number).
+		First(&revision).Error
+	return &revision, err
+}
+
+func (r *SubmissionRevisionRepositoryImpl) GetLatest(submissionID uint) (*models.SubmissionRevision, error) {
+	var revision models.SubmissionRevision
+	err := r.db.Where("submission_id = ?", submissionID).
+		Order("number DESC").
+		First(&revision).Error
+	return &revision, err
+}
@@ -9,10 +9,12 @@ import (
 type AssignmentSubmissionRepository interface {
 	GetByID(id uint) (*models.AssignmentSubmission, error)
 	GetByStudentAndAssignment(studentID, assignmentID uint) (*models.AssignmentSubmission, error)
+	GetByGroupAndAssignment(groupID, assignmentID uint) (*models.AssignmentSubmission, error)
 	GetSubmissionsByStudentID(studentID uint) ([]models.AssignmentSubmission, error)
 	GetSubmissionsByAssignmentID(assignmentID uint) ([]models.AssignmentSubmission, error)
 	Create(submission *models.AssignmentSubmission) error
 	Update(id uint, submission *models.AssignmentSubmission) error
+	SetAIRubric(id uint, data string) error
 }
 
 type AssignmentSubmissionRepositoryImpl struct {
@@ -37,6 +39,14 @@ func (r *AssignmentSubmissionRepositoryImpl) GetByStudentAndAssignment(studentID
 	return &submission, err
 }
 
+func (r *AssignmentSubmissionRepositoryImpl) GetByGroupAndAssignment(groupID, assignmentID uint) (*models.AssignmentSubmission, error) {
+	var submission models.AssignmentSubmission
+	err := r.db.
+		Where("group_id = ? AND assignment_id = ?", groupID, assignmentID).
+		First(&submission).Error
+	return &submission, err
+}
+
 func (r *AssignmentSubmissionRepositoryImpl) GetSubmissionsByStudentID(studentID uint) ([]models.AssignmentSubmission, error) {
 	var submissions []models.AssignmentSubmission
 	err := r.db.Where("student_id = ?", studentID).Find(&submissions).Error
@@ -56,6 +66,13 @@ func (r *AssignmentSubmissionRepositoryImpl) Create(submission *models.Assignmen
 func (r *AssignmentSubmissionRepositoryImpl) Update(id uint, submission *models.AssignmentSubmission) error {
 	return r.db.Model(&models.AssignmentSubmission{}).
 		Where("id = ?", id).
-		Select("content", "answers", "status", "word_count", "submitted_at").
+		Select("content", "answers", "status", "word_count", "submitted_at", "group_id", "test_results", "round", "return_due_date").
 		Updates(submission).Error
 }
+
+// SetAIRubric — сохраняет разбор по критериям от AI (JSON RubricSuggestion).
+func (r *AssignmentSubmissionRepositoryImpl) SetAIRubric(id uint, data string) error {
+	return r.db.Model(&models.AssignmentSubmission{}).
+		Where("id = ?", id).
+		Update("ai_rubric", data).Error
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+type SubmissionReturnRepository interface {
+	Create(ret *models.SubmissionReturn) error
+	GetBySubmissionID(submissionID uint) ([]models.SubmissionReturn, error)
+	GetOpen(submissionID uint) (*models.SubmissionReturn, error)
+	MarkResubmitted(id uint, revision int, at time.Time) error
+}
+
+type SubmissionReturnRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSubmissionReturnRepository(db *gorm.DB) *SubmissionReturnRepositoryImpl {
+	return &SubmissionReturnRepositoryImpl{db: db}
+}
+
+func (r *SubmissionReturnRepositoryImpl) Create(ret *models.SubmissionReturn) error {
+	return r.db.Create(ret).Error
+}
+
+func (r *SubmissionReturnRepositoryImpl) GetBySubmissionID(submissionID uint) ([]models.SubmissionReturn, error) {
+	var returns []models.SubmissionReturn
+	err := r.db.Where("submission_id = ?", submissionID).
+		Order("round ASC").
+		Find(&returns).Error
+	return returns, err
+}
+
+// GetOpen — последний возврат, на который студент ещё не ответил повторной сдачей.
+func (r *SubmissionReturnRepositoryImpl) GetOpen(submissionID uint) (*models.SubmissionReturn, error) {
+	var ret models.SubmissionReturn
+	err := r.db.Where("submission_id = ? AND resubmitted_at IS NULL", submissionID).
+		Order("round DESC").
+		First(&ret).Error
+	return &ret, err
+}
+
+func (r *SubmissionReturnRepositoryImpl) MarkResubmitted(id uint, revision int, at time.Time) error {
+	return r.db.Model(&models.SubmissionReturn{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"resubmitted_revision": revision, "resubmitted_at": at}).Error
+}
@@ -16,13 +16,25 @@ import (
 	"rest-project/internal/repository"
 	"rest-project/internal/services"
 	"rest-project/internal/services/ai"
+	"rest-project/internal/services/aiusage"
 	"rest-project/internal/services/analytics"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/archive"
+	"rest-project/internal/services/codegrade"
+	"rest-project/internal/services/gradebook"
+	"rest-project/internal/services/gradescale"
 	"rest-project/internal/services/metrics"
+	"rest-project/internal/services/moderation"
 	"rest-project/internal/services/schedule"
 	"rest-project/internal/services/tutor"
 	"rest-project/internal/services/notifier"
+	"rest-project/internal/services/peerreview"
 	"rest-project/internal/services/plagiarism"
 	"rest-project/internal/services/queue"
+	"rest-project/internal/services/regrade"
+	"rest-project/internal/services/release"
+	"rest-project/internal/services/rubric"
+	"rest-project/internal/services/sandbox"
 	"rest-project/internal/services/storage"
 	"rest-project/internal/utils"
 )
@@ -60,6 +72,13 @@ func SetupRoutes(r *gin.Engine) {
 	gradeRepo := repository.NewGradeRepository(db.DB)
 	submissionRepo := repository.NewAssignmentSubmissionRepository(db.DB)
 	promptRepo := repository.NewPromptRepository(db.DB)
+	extensionRepo := repository.NewAssignmentExtensionRepository(db.DB)
+	groupRepo := repository.NewAssignmentGroupRepository(db.DB)
+	attachmentRepo := repository.NewAttachmentRepository(db.DB)
+	revisionRepo := repository.NewSubmissionRevisionRepository(db.DB)
+	returnRepo := repository.NewSubmissionReturnRepository(db.DB)
+	annotationRepo := repository.NewSubmissionAnnotationRepository(db.DB)
+	gradeHistoryRepo := repository.NewGradeHistoryRepository(db.DB)
 
 	// Логируем старт приложения
 	utils.WriteInfoLog(0, "System", "Приложение Smart Course запущено")
@@ -68,9 +87,10 @@ func SetupRoutes(r *gin.Engine) {
 	userService := services.NewUserService(userRepo)
 	courseService := services.NewCourseService(courseRepo, userRepo)
 	studentService := services.NewStudentService(userRepo)
-	assignmentService := services.NewAssignmentService(assignmentRepo, courseRepo, userRepo)
-	gradeService := services.NewGradeService(gradeRepo, assignmentRepo, courseRepo, userRepo)
-	submissionService := services.NewAssignmentSubmissionService(submissionRepo, assignmentRepo, courseRepo, userRepo, gradeRepo)
+	assignmentService := services.NewAssignmentService(assignmentRepo, courseRepo, userRepo, extensionRepo)
+	gradeService := services.NewGradeService(gradeRepo, assignmentRepo, courseRepo, userRepo, submissionRepo, extensionRepo, groupRepo, revisionRepo, gradeHistoryRepo)
+	submissionService := services.NewAssignmentSubmissionService(submissionRepo, assignmentRepo, courseRepo, userRepo, gradeRepo, extensionRepo, groupRepo, attachmentRepo, revisionRepo, returnRepo, annotationRepo)
+	groupService := services.NewAssignmentGroupService(groupRepo, assignmentRepo, courseRepo, submissionRepo)
 	promptService := services.NewPromptService(promptRepo)
 
 	// Инициализация обработчиков
@@ -80,6 +100,7 @@ func SetupRoutes(r *gin.Engine) {
 	assignmentHandler := delivery.NewAssignmentHandler(assignmentService)
 	gradeHandler := delivery.NewGradeHandler(gradeService)
 	submissionHandler := delivery.NewAssignmentSubmissionHandler(submissionService)
+	groupHandler := delivery.NewAssignmentGroupHandler(groupService)
 	studentCourseHandler := delivery.NewStudentCourseHandler(courseService)
 	promptHandler := delivery.NewPromptHandler(promptService)
 	dashboardHandler := delivery.NewDashboardHandler()
@@ -95,6 +116,24 @@ func SetupRoutes(r *gin.Engine) {
 	}
 	wsHub := notifier.NewHub()
 
+	// Выгрузка всех работ задания в ZIP (нужны storage и очередь)
+	var archiveSvc *archive.Service
+	if storageSvc != nil {
+		archiveSvc = archive.NewService(db.DB, storageSvc)
+	}
+
+	// LLM провайдер: LLM_PROVIDER=openrouter|openai|ollama|mock (по умолчанию openrouter)
+	// Учёт токенов и лимиты: провайдер оборачивается в ai.Metered
+	aiUsageSvc := aiusage.NewService(db.DB)
+	aiUsageHandler := delivery.NewAIUsageHandler(aiUsageSvc)
+	llmProvider, llmErr := ai.NewProviderFromEnv()
+	if llmErr != nil {
+		log.Printf("[routes] LLM провайдер отключён: %v", llmErr)
+	} else {
+		log.Printf("[routes] LLM провайдер: %s", llmProvider.Name())
+		llmProvider = ai.NewMetered(llmProvider, aiUsageSvc)
+	}
+
 	// Воркер очереди — пример AI-обработчика (реальная логика подключается позже)
 	if queueSvc != nil {
 		worker := queue.NewWorker(queueSvc)
@@ -109,15 +148,15 @@ func SetupRoutes(r *gin.Engine) {
 			return map[string]any{"echo": payload}, nil
 		})
 
-		// Реальный AI-обработчик через OpenRouter (если задан OPENROUTER_API_KEY)
-		if aiClient, aiErr := ai.NewOpenRouterFromEnv(); aiErr == nil {
-			worker.Register("ai_evaluate", ai.MakeEvaluateHandler(aiClient))
-			worker.Register("ai_lesson_plan", ai.MakeLessonPlanHandler(aiClient))
-			worker.Register("ai_test_generate", ai.MakeTestGenerateHandler(aiClient))
-			worker.Register("ai_improve_suggestion", ai.MakeImproveHandler(aiClient))
+		// AI-обработчики через выбранный LLM провайдер
+		if llmProvider != nil {
+			worker.Register("ai_evaluate", ai.MakeEvaluateHandler(llmProvider, gradeService))
+			worker.Register("ai_lesson_plan", ai.MakeLessonPlanHandler(llmProvider))
+			worker.Register("ai_test_generate", ai.MakeTestGenerateHandler(llmProvider))
+			worker.Register("ai_improve_suggestion", ai.MakeImproveHandler(llmProvider))
 			log.Println("[routes] AI handlers зарегистрированы: evaluate, lesson_plan, test_generate, improve")
 		} else {
-			log.Printf("[routes] ai handlers отключены: %v", aiErr)
+			log.Printf("[routes] ai handlers отключены: %v", llmErr)
 		}
 
 		// Plagiarism (TF-IDF) worker
@@ -125,6 +164,28 @@ func SetupRoutes(r *gin.Engine) {
 		worker.Register("plagiarism_scan", plagiarism.MakeScanHandler(plagiarismSvc))
 		log.Println("[routes] plagiarism_scan handler зарегистрирован")
 
+		// Выгрузки идут своим списком и воркером: долгий архив не держит code_grade и AI
+		if archiveSvc != nil {
+			archiveWorker := queue.NewWorker(queueSvc.Lane("archive", archive.JobType))
+			archiveWorker.SetNotifier(func(userID uint, st queue.JobStatus) {
+				wsHub.SendToUser(userID, "job_status", st)
+			})
+			archiveWorker.Register(archive.JobType, archive.MakeZipHandler(archiveSvc))
+			archiveWorker.SetTimeout(archive.JobType, archive.JobTimeout)
+			go archiveWorker.Run(context.Background())
+			log.Println("[routes] submissions_zip handler зарегистрирован (отдельный воркер)")
+		}
+
+		// Автопроверка code-заданий в песочнице (нужен unshare для изоляции сети)
+		if runner, sbErr := sandbox.NewRunnerFromEnv(); sbErr == nil {
+			codeGradeSvc := codegrade.NewService(db.DB, runner, submissionService)
+			worker.Register(services.CodeGradeJobType, codegrade.MakeGradeHandler(codeGradeSvc))
+			submissionService.SetQueue(queueSvc)
+			log.Println("[routes] code_grade handler зарегистрирован")
+		} else {
+			log.Printf("[routes] code_grade отключён: %v", sbErr)
+		}
+
 		go worker.Run(context.Background())
 	}
 
@@ -140,23 +201,64 @@ func SetupRoutes(r *gin.Engine) {
 	scheduleHandler := delivery.NewScheduleHandler(scheduleSvc)
 
 	// AI Tutor (student)
-	tutorClient := ai.NewOpenRouterFromEnvSafe()
-	tutorSvc := tutor.NewService(db.DB, tutorClient)
+	tutorSvc := tutor.NewService(db.DB, llmProvider)
 	tutorHandler := delivery.NewTutorHandler(tutorSvc)
 
 	// Essay AI Review (teacher)
-	essayReviewHandler := delivery.NewEssayReviewHandler(tutorClient)
+	essayReviewHandler := delivery.NewEssayReviewHandler(llmProvider)
 
 	// Student Calendar
 	calendarHandler := delivery.NewCalendarHandler(db.DB)
 	plagiarismHandler := delivery.NewPlagiarismHandler(plagiarismSvcForHandler, queueSvc)
+	archiveHandler := delivery.NewSubmissionArchiveHandler(archiveSvc, queueSvc)
 	analyticsSvc := analytics.NewService(db.DB)
 	analyticsHandler := delivery.NewAnalyticsHandler(analyticsSvc)
+	// Шкалы оценивания: оценки и итог курса по шкале (буквы, 5-балльная, зачёт)
+	gradeScaleSvc := gradescale.NewService(db.DB)
+	gradebookSvc := gradebook.NewService(db.DB, analyticsSvc)
+	gradebookSvc.SetScales(gradeScaleSvc)
+	gradebookSvc.SetGrades(gradeService)
+	gradebookHandler := delivery.NewGradebookHandler(gradebookSvc)
+	gradingScaleHandler := delivery.NewGradingScaleHandler(gradeScaleSvc)
+	// Библиотека рубрик: версии, общий доступ, привязка к заданиям
+	rubricHandler := delivery.NewRubricHandler(rubric.NewService(db.DB))
+	gradeHandler.SetScales(gradeScaleSvc)
+	submissionHandler.SetScales(gradeScaleSvc)
+	pdfHandler.SetGrading(gradeScaleSvc, gradebookSvc)
 
 	// Подключаем WS-нотификации к существующим обработчикам
 	submissionHandler.SetHub(wsHub)
+	if storageSvc != nil {
+		submissionHandler.SetStorage(storageSvc)
+	}
 	gradeHandler.SetHub(wsHub)
 
+	// Отложенная публикация заданий (publish_at) + уведомление студентов
+	releaseSvc := release.NewService(db.DB, wsHub)
+	assignmentHandler.SetRelease(releaseSvc)
+	gradeReleaseHandler := delivery.NewGradeReleaseHandler(releaseSvc)
+	go releaseSvc.Run(context.Background(), time.Minute)
+
+	// Peer review: распределение рецензентов после дедлайна + смешивание оценок
+	peerReviewSvc := peerreview.NewService(db.DB, wsHub)
+	gradeService.SetPeerScores(peerReviewSvc)
+	peerReviewHandler := delivery.NewPeerReviewHandler(peerReviewSvc)
+	go peerReviewSvc.Run(context.Background(), time.Minute)
+
+	// Апелляции на оценку: решение преподавателя меняет оценку через GradeService (с историей)
+	regradeSvc := regrade.NewService(db.DB, wsHub, gradeService)
+	regradeHandler := delivery.NewRegradeHandler(regradeSvc)
+
+	// Двойная проверка: оценки двух проверяющих и решение модератора через GradeService
+	moderationSvc := moderation.NewService(db.DB, wsHub, gradeService)
+	gradeService.SetModeration(moderationSvc)
+	moderationHandler := delivery.NewModerationHandler(moderationSvc)
+
+	// Анонимная проверка: псевдонимы вместо студентов в teacher-эндпоинтах
+	anonSvc := anonymize.NewService(db.DB)
+	gradeHandler.SetAnonymizer(anonSvc)
+	submissionHandler.SetAnonymizer(anonSvc)
+
 	// WebSocket — без AuthMiddleware (токен идёт в query)
 	r.GET("/ws", wsHandler.Connect)
 
@@ -180,6 +282,23 @@ func SetupRoutes(r *gin.Engine) {
 			adminRoutes.DELETE("/courses/:id", courseHandler.DeleteCourse)
 			adminRoutes.POST("/courses/:id/students", courseHandler.AddStudentToCourse)
 			adminRoutes.DELETE("/courses/:id/students/:student_id", courseHandler.RemoveStudentFromCourse)
+
+			// Шкалы оценивания организации
+			adminRoutes.GET("/grading-scales", gradingScaleHandler.List)
+			adminRoutes.POST("/grading-scales", gradingScaleHandler.Create)
+			adminRoutes.PUT("/grading-scales/:id", gradingScaleHandler.Update)
+			adminRoutes.DELETE("/grading-scales/:id", gradingScaleHandler.Delete)
+
+			// Журнал изменений оценок
+			adminRoutes.GET("/grades/:id/history", gradeHandler.GetGradeHistory)
+
+			// Расход токенов AI и лимиты
+			adminRoutes.GET("/ai/usage", aiUsageHandler.Report)
+			adminRoutes.GET("/ai/usage/users/:id", aiUsageHandler.UserUsage)
+			adminRoutes.GET("/ai/quotas", aiUsageHandler.ListQuotas)
+			adminRoutes.PUT("/ai/quotas/roles/:role", aiUsageHandler.SetRoleQuota)
+			adminRoutes.PUT("/ai/quotas/users/:id", aiUsageHandler.SetUserQuota)
+			adminRoutes.DELETE("/ai/quotas/users/:id", aiUsageHandler.DeleteUserQuota)
 		}
 
 		// Маршруты для преподавателей
@@ -200,12 +319,51 @@ func SetupRoutes(r *gin.Engine) {
 			teacherRoutes.PUT("/assignments/:id", assignmentHandler.UpdateAssignment)
 			teacherRoutes.PUT("/assignments/:id/criteria", assignmentHandler.UpdateAssignmentCriteria)
 			teacherRoutes.DELETE("/assignments/:id", assignmentHandler.DeleteAssignment)
+			teacherRoutes.POST("/assignments/:id/publish", assignmentHandler.PublishAssignment)
+			teacherRoutes.GET("/assignments/:id/extensions", assignmentHandler.ListExtensions)
+			teacherRoutes.POST("/assignments/:id/extensions", assignmentHandler.GrantExtension)
+			teacherRoutes.DELETE("/assignments/:id/extensions/:student_id", assignmentHandler.RevokeExtension)
+			teacherRoutes.GET("/assignments/:id/groups", groupHandler.ListGroups)
+			teacherRoutes.POST("/assignments/:id/groups", groupHandler.CreateGroup)
+			teacherRoutes.PUT("/assignments/:id/groups/:group_id", groupHandler.UpdateGroup)
+			teacherRoutes.DELETE("/assignments/:id/groups/:group_id", groupHandler.DeleteGroup)
+			teacherRoutes.POST("/assignments/:id/groups/:group_id/grade", gradeHandler.GradeGroup)
+			teacherRoutes.GET("/assignments/:id/peer-review", peerReviewHandler.Report)
+			teacherRoutes.PUT("/assignments/:id/peer-review", peerReviewHandler.Configure)
+			teacherRoutes.POST("/assignments/:id/peer-review/allocate", peerReviewHandler.Allocate)
 			teacherRoutes.POST("/assignments/:id/ai-review", essayReviewHandler.Review)
 			teacherRoutes.GET("/assignments/:id/submissions", gradeHandler.GetAssignmentSubmissions)
+			teacherRoutes.POST("/assignments/:id/submissions/archive", archiveHandler.Start)
+			teacherRoutes.GET("/submissions/:id/files", submissionHandler.ListSubmissionFiles)
+			teacherRoutes.GET("/submissions/:id/revisions", submissionHandler.ListSubmissionRevisions)
+			teacherRoutes.GET("/submissions/:id/revisions/diff", submissionHandler.DiffSubmissionRevisions)
+			teacherRoutes.GET("/submissions/:id/revisions/:number", submissionHandler.GetSubmissionRevision)
+			teacherRoutes.GET("/submissions/:id/timeline", submissionHandler.WritingTimeline)
+			teacherRoutes.POST("/submissions/:id/return", submissionHandler.ReturnSubmission)
+			teacherRoutes.GET("/submissions/:id/returns", submissionHandler.ListReturns)
+			teacherRoutes.GET("/submissions/:id/rubric", gradeHandler.GetSubmissionRubric)
+			teacherRoutes.GET("/submissions/:id/annotations", submissionHandler.ListAnnotations)
+			teacherRoutes.POST("/submissions/:id/annotations", submissionHandler.CreateAnnotation)
+			teacherRoutes.POST("/submissions/:id/annotations/:annotation_id/replies", submissionHandler.ReplyToAnnotation)
+			teacherRoutes.DELETE("/submissions/:id/annotations/:annotation_id", submissionHandler.DeleteAnnotation)
 			teacherRoutes.GET("/assignments/:id/grades", gradeHandler.GetAssignmentGrades)
 			teacherRoutes.POST("/assignments/:id/grades", gradeHandler.CreateGrade)
+			teacherRoutes.PUT("/assignments/:id/grade-release", gradeReleaseHandler.SetPolicy)
+			teacherRoutes.POST("/assignments/:id/grades/release", gradeReleaseHandler.Release)
+			teacherRoutes.POST("/assignments/:id/deanonymize", gradeHandler.Deanonymize)
 			teacherRoutes.PUT("/grades/:id", gradeHandler.UpdateGrade)
 			teacherRoutes.DELETE("/grades/:id", gradeHandler.DeleteGrade)
+			teacherRoutes.GET("/grades/:id/history", gradeHandler.GetGradeHistory)
+			teacherRoutes.PUT("/assignments/:id/regrade-window", regradeHandler.SetWindow)
+			teacherRoutes.GET("/assignments/:id/moderation", moderationHandler.GetSettings)
+			teacherRoutes.PUT("/assignments/:id/moderation", moderationHandler.Configure)
+			teacherRoutes.GET("/assignments/:id/moderation/cases", moderationHandler.Cases)
+			teacherRoutes.POST("/moderation/cases/:id/resolve", moderationHandler.Resolve)
+			teacherRoutes.GET("/moderation/assignments/:id/work", moderationHandler.Worklist)
+			teacherRoutes.POST("/moderation/assignments/:id/marks", moderationHandler.SubmitMark)
+			teacherRoutes.GET("/regrade-requests", regradeHandler.List)
+			teacherRoutes.POST("/regrade-requests/:id/accept", regradeHandler.Accept)
+			teacherRoutes.POST("/regrade-requests/:id/reject", regradeHandler.Reject)
 
 			// Prompt library (teacher-owned)
 			teacherRoutes.GET("/prompts", promptHandler.List)
@@ -250,6 +408,41 @@ func SetupRoutes(r *gin.Engine) {
 			teacherRoutes.GET("/analytics/grades-over-time", analyticsHandler.GradesOverTime)
 			teacherRoutes.GET("/analytics/heatmap", analyticsHandler.Heatmap)
 			teacherRoutes.GET("/analytics/at-risk", analyticsHandler.AtRisk)
+			teacherRoutes.GET("/analytics/criteria", analyticsHandler.Criteria)
+
+			// Журнал курса: категории с весами, освобождения, итоговый процент
+			teacherRoutes.GET("/courses/:id/gradebook", gradebookHandler.Matrix)
+			teacherRoutes.GET("/courses/:id/gradebook/export", gradebookHandler.Export)
+			teacherRoutes.POST("/courses/:id/gradebook/import/preview", gradebookHandler.PreviewImport)
+			teacherRoutes.POST("/courses/:id/gradebook/import", gradebookHandler.ApplyImport)
+			teacherRoutes.GET("/courses/:id/gradebook/categories", gradebookHandler.ListCategories)
+			teacherRoutes.POST("/courses/:id/gradebook/categories", gradebookHandler.CreateCategory)
+			teacherRoutes.PUT("/courses/:id/gradebook/categories/:category_id", gradebookHandler.UpdateCategory)
+			teacherRoutes.DELETE("/courses/:id/gradebook/categories/:category_id", gradebookHandler.DeleteCategory)
+			teacherRoutes.PUT("/assignments/:id/gradebook-category", gradebookHandler.SetAssignmentCategory)
+			teacherRoutes.PUT("/assignments/:id/excused/:student_id", gradebookHandler.Excuse)
+			teacherRoutes.DELETE("/assignments/:id/excused/:student_id", gradebookHandler.Unexcuse)
+
+			// Библиотека рубрик
+			teacherRoutes.GET("/rubrics", rubricHandler.List)
+			teacherRoutes.POST("/rubrics", rubricHandler.Create)
+			teacherRoutes.POST("/rubrics/import", rubricHandler.Import)
+			teacherRoutes.GET("/rubrics/:id", rubricHandler.Get)
+			teacherRoutes.PUT("/rubrics/:id", rubricHandler.Update)
+			teacherRoutes.DELETE("/rubrics/:id", rubricHandler.Delete)
+			teacherRoutes.GET("/rubrics/:id/versions", rubricHandler.Versions)
+			teacherRoutes.GET("/rubrics/:id/export", rubricHandler.Export)
+			teacherRoutes.POST("/rubrics/:id/clone", rubricHandler.Clone)
+			teacherRoutes.POST("/rubrics/:id/push", rubricHandler.Push)
+			teacherRoutes.PUT("/assignments/:id/rubric", rubricHandler.Attach)
+			teacherRoutes.DELETE("/assignments/:id/rubric", rubricHandler.Detach)
+
+			// Шкалы оценивания (свои + организации)
+			teacherRoutes.GET("/grading-scales", gradingScaleHandler.List)
+			teacherRoutes.POST("/grading-scales", gradingScaleHandler.Create)
+			teacherRoutes.PUT("/grading-scales/:id", gradingScaleHandler.Update)
+			teacherRoutes.DELETE("/grading-scales/:id", gradingScaleHandler.Delete)
+			teacherRoutes.PUT("/courses/:id/grading-scale", gradingScaleHandler.SetCourseScale)
 
 			// Сабақ кестесі (Calendar)
 			teacherRoutes.GET("/schedule", scheduleHandler.List)
@@ -266,11 +459,31 @@ func SetupRoutes(r *gin.Engine) {
 			studentRoutes.GET("/courses", studentCourseHandler.GetStudentCourses)
 			studentRoutes.GET("/courses/:id", courseHandler.GetCourseByID)
 			studentRoutes.GET("/courses/:id/assignments", assignmentHandler.GetCourseAssignmentsForStudent)
+			studentRoutes.GET("/courses/:id/gradebook", gradebookHandler.StudentGradebook)
 			studentRoutes.GET("/assignments/:id", assignmentHandler.GetAssignmentForStudent)
 			studentRoutes.GET("/assignments/:id/submission", submissionHandler.GetStudentSubmission)
 			studentRoutes.PUT("/assignments/:id/submission/draft", submissionHandler.SaveDraft)
 			studentRoutes.POST("/assignments/:id/submission/submit", submissionHandler.Submit)
+			studentRoutes.GET("/assignments/:id/submission/files", submissionHandler.ListFiles)
+			studentRoutes.POST("/assignments/:id/submission/files", submissionHandler.UploadFile)
+			studentRoutes.DELETE("/assignments/:id/submission/files/:file_id", submissionHandler.DeleteFile)
+			studentRoutes.GET("/assignments/:id/submission/revisions", submissionHandler.ListRevisions)
+			studentRoutes.GET("/assignments/:id/submission/revisions/diff", submissionHandler.DiffRevisions)
+			studentRoutes.GET("/assignments/:id/submission/revisions/:number", submissionHandler.GetRevision)
+			studentRoutes.GET("/assignments/:id/submission/annotations", submissionHandler.ListStudentAnnotations)
+			studentRoutes.POST("/assignments/:id/submission/annotations/:annotation_id/replies", submissionHandler.ReplyAsStudent)
+			studentRoutes.GET("/assignments/:id/groups", groupHandler.ListGroupsForStudent)
+			studentRoutes.GET("/assignments/:id/group", groupHandler.GetMyGroup)
+			studentRoutes.DELETE("/assignments/:id/group", groupHandler.LeaveGroup)
+			studentRoutes.POST("/assignments/:id/groups/:group_id/join", groupHandler.JoinGroup)
+			studentRoutes.GET("/assignments/:id/peer-feedback", peerReviewHandler.ReceivedReviews)
+			studentRoutes.GET("/peer-reviews", peerReviewHandler.MyReviews)
+			studentRoutes.GET("/peer-reviews/:id", peerReviewHandler.GetReview)
+			studentRoutes.POST("/peer-reviews/:id", peerReviewHandler.SubmitReview)
 			studentRoutes.GET("/grades", gradeHandler.GetStudentGrades)
+			studentRoutes.GET("/regrade-requests", regradeHandler.ListMine)
+			studentRoutes.POST("/regrade-requests", regradeHandler.Create)
+			studentRoutes.POST("/regrade-requests/:id/withdraw", regradeHandler.Withdraw)
 
 			// AI Репетитор (тапсырма бойынша чат)
 			studentRoutes.GET("/assignments/:id/tutor", tutorHandler.GetSession)
@@ -0,0 +1,15 @@
+package ai
+
+// EssayReviewOutput — мұғалімге эссе тексеру ұсынысы.
+type EssayReviewOutput struct {
+	SuggestedScore float64 `json:"suggested_score"`
+	Feedback       string  `json:"feedback"`
+}
+
+// EssayReviewTask — балл 0..maxScore, пікір бос болмауы керек.
+func EssayReviewTask(maxScore float64) *Task {
+	return &Task{Name: FeatureEssayReview, Schema: Object([]string{"suggested_score", "feedback"}, map[string]*Schema{
+		"suggested_score": Number(0, maxScore),
+		"feedback":        String(1),
+	})}
+}
@@ -5,14 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 
+	"rest-project/internal/models"
 	"rest-project/internal/services/queue"
 )
 
 // EvaluatePayload — вход для задачи AI-оценивания.
+// С submission_id разбор по критериям сохраняется к работе как подсказка преподавателю.
 type EvaluatePayload struct {
+	SubmissionID   uint                   `json:"submission_id,omitempty"`
 	SubmissionText string                 `json:"submission_text"`
 	AssignmentName string                 `json:"assignment_name,omitempty"`
 	Criteria       []EvaluateCriterion    `json:"criteria"`
@@ -20,6 +22,7 @@ type EvaluatePayload struct {
 }
 
 type EvaluateCriterion struct {
+	ID          int    `json:"id,omitempty"` // ID критерия рубрики задания
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	MaxScore    int    `json:"max_score"`
@@ -38,14 +41,22 @@ type EvaluateResult struct {
 	Max            int               `json:"max"`
 	OverallFeedback string           `json:"overall_feedback"`
 	Criteria       []CriterionResult `json:"criteria"`
+	RubricSaved    bool              `json:"rubric_saved,omitempty"`
+	RubricError    string            `json:"rubric_error,omitempty"`
+}
+
+// RubricSink — сохраняет разбор AI по критериям к работе студента.
+type RubricSink interface {
+	SaveAIRubric(submissionID, teacherID uint, criteria []models.CriterionScoreInput, feedback string) error
 }
 
 // MakeEvaluateHandler — фабрика обработчика для воркера очереди.
 // Возвращает queue.Handler, готовый к worker.Register("ai_evaluate", h).
-func MakeEvaluateHandler(client *OpenRouterClient) queue.Handler {
+// sink может быть nil — тогда результат только возвращается в статусе задачи.
+func MakeEvaluateHandler(client Provider, sink RubricSink) queue.Handler {
 	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
 		if client == nil {
-			return nil, errors.New("openrouter client disabled (OPENROUTER_API_KEY missing)")
+			return nil, errors.New("llm provider disabled (LLM_PROVIDER is not configured)")
 		}
 		var p EvaluatePayload
 		if err := json.Unmarshal(job.Payload, &p); err != nil {
@@ -62,15 +73,20 @@ func MakeEvaluateHandler(client *OpenRouterClient) queue.Handler {
 		messages := buildEvaluateMessages(p)
 		progress(35)
 
-		raw, err := client.Chat(ctx, messages)
-		if err != nil {
+		ctx = WithCall(ctx, CallInfo{UserID: job.UserID, Feature: FeatureEvaluate, SubmissionID: p.SubmissionID})
+		var data evaluateOutput
+		if err := ChatStructured(ctx, client, evaluateTask(p.Criteria), messages, &data); err != nil {
 			return nil, err
 		}
 		progress(80)
 
-		result, err := parseEvaluateJSON(raw, p.Criteria)
-		if err != nil {
-			return nil, err
+		result := normalizeEvaluate(data, p.Criteria)
+		if sink != nil && p.SubmissionID != 0 {
+			if err := sink.SaveAIRubric(p.SubmissionID, job.UserID, rubricInputs(result, p.Criteria), result.OverallFeedback); err != nil {
+				result.RubricError = err.Error()
+			} else {
+				result.RubricSaved = true
+			}
 		}
 		progress(100)
 		return result, nil
@@ -96,26 +112,62 @@ func buildEvaluateMessages(p EvaluatePayload) []Message {
 	}
 }
 
-var jsonBlockRe = regexp.MustCompile(`(?s)\{.*\}`)
+type evaluateOutput struct {
+	Criteria        []CriterionResult `json:"criteria"`
+	OverallFeedback string            `json:"overall_feedback"`
+}
 
-func parseEvaluateJSON(raw string, criteria []EvaluateCriterion) (*EvaluateResult, error) {
-	// Срезаем код-блоки и markdown
-	clean := strings.TrimSpace(raw)
-	clean = strings.TrimPrefix(clean, "```json")
-	clean = strings.TrimPrefix(clean, "```")
-	clean = strings.TrimSuffix(clean, "```")
-	if m := jsonBlockRe.FindString(clean); m != "" {
-		clean = m
+// evaluateTask — схема зависит от критериев: имена из запроса, по одному результату на критерий.
+// Схема ограничивает балл общим максимумом; предел каждого критерия и отсутствие
+// повторов проверяет Check — нарушения уходят модели на исправление.
+func evaluateTask(criteria []EvaluateCriterion) *Task {
+	names := make([]string, 0, len(criteria))
+	maxScore := 0
+	for _, c := range criteria {
+		names = append(names, c.Name)
+		if c.MaxScore > maxScore {
+			maxScore = c.MaxScore
+		}
 	}
+	return &Task{Name: FeatureEvaluate, Schema: Object([]string{"criteria", "overall_feedback"}, map[string]*Schema{
+		"criteria": ArrayOf(Object([]string{"name", "score", "feedback"}, map[string]*Schema{
+			"name":      String(1, names...),
+			"score":     Integer(0, float64(maxScore)),
+			"max_score": {Type: "integer"},
+			"feedback":  String(0),
+		}), len(criteria), len(criteria)),
+		"overall_feedback": String(1),
+	}), Check: func(out any) []string {
+		data, ok := out.(*evaluateOutput)
+		if !ok {
+			return nil
+		}
+		return checkEvaluateOutput(data, criteria)
+	}}
+}
 
-	var data struct {
-		Criteria        []CriterionResult `json:"criteria"`
-		OverallFeedback string            `json:"overall_feedback"`
+// checkEvaluateOutput — каждый критерий ровно один раз и не выше своего max_score.
+func checkEvaluateOutput(data *evaluateOutput, criteria []EvaluateCriterion) []string {
+	maxByName := make(map[string]int, len(criteria))
+	for _, c := range criteria {
+		maxByName[c.Name] = c.MaxScore
 	}
-	if err := json.Unmarshal([]byte(clean), &data); err != nil {
-		return nil, fmt.Errorf("model returned non-JSON: %w (raw: %s)", err, truncate(raw, 200))
+	var errs []string
+	seen := make(map[string]bool, len(data.Criteria))
+	for i, r := range data.Criteria {
+		if seen[r.Name] {
+			errs = append(errs, fmt.Sprintf("$.criteria[%d].name: criterion %q is listed more than once", i, r.Name))
+			continue
+		}
+		seen[r.Name] = true
+		if m, ok := maxByName[r.Name]; ok && r.Score > m {
+			errs = append(errs, fmt.Sprintf("$.criteria[%d].score: must be <= %d for criterion %q", i, m, r.Name))
+		}
 	}
+	return errs
+}
 
+func normalizeEvaluate(data evaluateOutput, criteria []EvaluateCriterion) *EvaluateResult {
 	// Гарантируем порядок и max_score из заявленных критериев
 	maxByName := map[string]int{}
 	for _, c := range criteria {
@@ -145,7 +197,26 @@ func parseEvaluateJSON(raw string, criteria []EvaluateCriterion) (*EvaluateResul
 		Max:             max,
 		OverallFeedback: data.OverallFeedback,
 		Criteria:        data.Criteria,
-	}, nil
+	}
+}
+
+// rubricInputs — результат модели в виде баллов по критериям рубрики (ID берётся из запроса по имени).
+func rubricInputs(result *EvaluateResult, criteria []EvaluateCriterion) []models.CriterionScoreInput {
+	idByName := map[string]int{}
+	for _, c := range criteria {
+		idByName[c.Name] = c.ID
+	}
+	inputs := make([]models.CriterionScoreInput, 0, len(result.Criteria))
+	for _, r := range result.Criteria {
+		score := float64(r.Score)
+		inputs = append(inputs, models.CriterionScoreInput{
+			CriterionID: idByName[r.Name],
+			Name:        r.Name,
+			Score:       &score,
+			Comment:     r.Feedback,
+		})
+	}
+	return inputs
 }
 
 func truncate(s string, n int) string {
@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/queue"
+)
+
+type rubricRecorder struct {
+	submissionID uint
+	teacherID    uint
+	criteria     []models.CriterionScoreInput
+	feedback     string
+}
+
+func (r *rubricRecorder) SaveAIRubric(submissionID, teacherID uint, criteria []models.CriterionScoreInput, feedback string) error {
+	r.submissionID, r.teacherID, r.criteria, r.feedback = submissionID, teacherID, criteria, feedback
+	return nil
+}
+
+// Бірінші жауап схемаға сай емес — handler жөндеу сұрауын жіберіп, екінші жауапты қабылдауы керек.
+func TestEvaluateHandlerRepairsInvalidReply(t *testing.T) {
+	mock := NewMockProvider(
+		MockRule{
+			Contains: "does not match the required JSON schema",
+			Reply:    `{"criteria":[{"name":"Structure","score":7,"feedback":"clear"},{"name":"Grammar","score":4,"feedback":"few typos"}],"overall_feedback":"Good work"}`,
+		},
+		MockRule{Contains: "Submission:", Reply: `{"criteria":[{"name":"Structure","score":7}]}`},
+	)
+	sink := &rubricRecorder{}
+	handler := MakeEvaluateHandler(mock, sink)
+
+	payload, _ := json.Marshal(EvaluatePayload{
+		SubmissionID:   42,
+		SubmissionText: "Essay text",
+		AssignmentName: "Essay",
+		Criteria: []EvaluateCriterion{
+			{ID: 1, Name: "Structure", MaxScore: 10},
+			{ID: 2, Name: "Grammar", MaxScore: 5},
+		},
+	})
+	out, err := handler(context.Background(), &queue.Job{ID: "job-1", Payload: payload, UserID: 7}, func(int) {})
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+
+	result := out.(*EvaluateResult)
+	if result.Total != 11 || result.Max != 15 {
+		t.Errorf("total/max = %d/%d, want 11/15", result.Total, result.Max)
+	}
+	if result.OverallFeedback != "Good work" || !result.RubricSaved {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if sink.submissionID != 42 || sink.teacherID != 7 || len(sink.criteria) != 2 || sink.criteria[1].CriterionID != 2 {
+		t.Errorf("rubric not saved as expected: %+v", sink)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("calls = %d, want 2 (initial + repair)", len(calls))
+	}
+	repair := calls[1]
+	if got := repair[len(repair)-2]; got.Role != "assistant" || !strings.Contains(got.Content, `"Structure"`) {
+		t.Errorf("repair request must echo the invalid reply, got %+v", got)
+	}
+	if !strings.Contains(lastUserMessage(repair), "overall_feedback") {
+		t.Errorf("repair prompt must list schema errors: %q", lastUserMessage(repair))
+	}
+}
+
+func TestEvaluateHandlerFailsAfterRepairAttempts(t *testing.T) {
+	mock := NewMockProvider(MockRule{Reply: "I think the essay is fine."})
+	handler := MakeEvaluateHandler(mock, nil)
+
+	payload, _ := json.Marshal(EvaluatePayload{
+		SubmissionText: "Essay text",
+		Criteria:       []EvaluateCriterion{{Name: "Structure", MaxScore: 10}},
+	})
+	_, err := handler(context.Background(), &queue.Job{ID: "job-2", Payload: payload}, func(int) {})
+	if err == nil || !strings.Contains(err.Error(), ErrInvalidOutput.Error()) {
+		t.Fatalf("err = %v, want ErrInvalidOutput", err)
+	}
+	if n := len(mock.Calls()); n != maxRepairAttempts+1 {
+		t.Errorf("calls = %d, want %d", n, maxRepairAttempts+1)
+	}
+}
+
+// Схемаға сай, бірақ критерийдің өз шегінен асқан балл мен қайталанған критерий жөндеуге жіберілуі керек.
+func TestEvaluateHandlerRepairsPerCriterionViolations(t *testing.T) {
+	mock := NewMockProvider(
+		MockRule{
+			Contains: "does not match the required JSON schema",
+			Reply:    `{"criteria":[{"name":"Structure","score":9,"feedback":"ok"},{"name":"Grammar","score":2,"feedback":"ok"}],"overall_feedback":"Fine"}`,
+		},
+		MockRule{
+			Contains: "Submission:",
+			Reply:    `{"criteria":[{"name":"Structure","score":9,"feedback":"ok"},{"name":"Structure","score":10,"feedback":"again"}],"overall_feedback":"Fine"}`,
+		},
+	)
+	handler := MakeEvaluateHandler(mock, nil)
+	payload, _ := json.Marshal(EvaluatePayload{
+		SubmissionText: "Essay text",
+		Criteria: []EvaluateCriterion{
+			{Name: "Structure", MaxScore: 10},
+			{Name: "Grammar", MaxScore: 2},
+		},
+	})
+	out, err := handler(context.Background(), &queue.Job{ID: "job-3", Payload: payload}, func(int) {})
+	if err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	if result := out.(*EvaluateResult); result.Total != 11 || result.Max != 12 {
+		t.Errorf("total/max = %d/%d, want 11/12", result.Total, result.Max)
+	}
+	calls := mock.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("calls = %d, want 2 (initial + repair)", len(calls))
+	}
+	if prompt := lastUserMessage(calls[1]); !strings.Contains(prompt, "more than once") {
+		t.Errorf("repair prompt must mention the duplicate criterion: %q", prompt)
+	}
+
+	if errs := checkEvaluateOutput(&evaluateOutput{Criteria: []CriterionResult{{Name: "Grammar", Score: 5}}},
+		[]EvaluateCriterion{{Name: "Grammar", MaxScore: 2}}); len(errs) != 1 || !strings.Contains(errs[0], "<= 2") {
+		t.Errorf("score above the criterion max must be rejected, got %v", errs)
+	}
+}
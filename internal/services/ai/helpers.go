@@ -1,9 +1,12 @@
 package ai
 
 import (
+	"regexp"
 	"strings"
 )
 
+var jsonBlockRe = regexp.MustCompile(`(?s)\{.*\}`)
+
 // extractJSON — LLM жауабынан таза JSON блогын алады.
 // Markdown ```json...``` тегтерін кесіп, бірінші { бастап соңғы } аралықты қайтарады.
 func extractJSON(raw string) string {
@@ -28,10 +28,19 @@ type ImproveResult struct {
 	OverallScore int      `json:"overall_score,omitempty"` // 0-100 эвристикалық бағалау
 }
 
-func MakeImproveHandler(client *OpenRouterClient) queue.Handler {
+var improveTask = &Task{Name: FeatureImprove, Schema: Object([]string{"strengths", "weaknesses", "suggestions"}, map[string]*Schema{
+	"strengths":     ArrayOf(String(1), 1, 5),
+	"weaknesses":    ArrayOf(String(1), 1, 5),
+	"suggestions":   ArrayOf(String(1), 1, 5),
+	"examples":      ArrayOf(String(0), 0, 0),
+	"tone":          String(0),
+	"overall_score": Integer(0, 100),
+})}
+
+func MakeImproveHandler(client Provider) queue.Handler {
 	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
 		if client == nil {
-			return nil, errors.New("openrouter client disabled")
+			return nil, errors.New("llm provider disabled")
 		}
 		var p ImprovePayload
 		if err := json.Unmarshal(job.Payload, &p); err != nil {
@@ -46,22 +55,10 @@ func MakeImproveHandler(client *OpenRouterClient) queue.Handler {
 		progress(20)
 
 		msgs := buildImproveMessages(p)
-		raw, err := client.Chat(ctx, msgs)
-		if err != nil {
-			return nil, err
-		}
-		progress(80)
-
-		clean := extractJSON(raw)
+		ctx = WithCall(ctx, CallInfo{UserID: job.UserID, Feature: FeatureImprove})
 		var result ImproveResult
-		if err := json.Unmarshal([]byte(clean), &result); err != nil {
-			return nil, fmt.Errorf("model returned non-JSON: %w (raw: %s)", err, truncate(raw, 200))
-		}
-		if result.OverallScore < 0 {
-			result.OverallScore = 0
-		}
-		if result.OverallScore > 100 {
-			result.OverallScore = 100
+		if err := ChatStructured(ctx, client, improveTask, msgs, &result); err != nil {
+			return nil, err
 		}
 		progress(100)
 		return &result, nil
@@ -36,11 +36,25 @@ type LessonStep struct {
 	Activity string `json:"activity"`
 }
 
+var lessonPlanTask = &Task{Name: FeatureLessonPlan, Schema: Object([]string{"title", "objectives", "outline"}, map[string]*Schema{
+	"title":      String(1),
+	"objectives": ArrayOf(String(1), 1, 8),
+	"outline": ArrayOf(Object([]string{"stage", "duration", "activity"}, map[string]*Schema{
+		"stage":    String(1, "intro", "explain", "practice", "check", "closure"),
+		"duration": Integer(1, 240),
+		"activity": String(1),
+	}), 1, 0),
+	"materials":  ArrayOf(String(0), 0, 0),
+	"assessment": String(0),
+	"homework":   String(0),
+	"reflection": String(0),
+})}
+
 // MakeLessonPlanHandler — queue handler.
-func MakeLessonPlanHandler(client *OpenRouterClient) queue.Handler {
+func MakeLessonPlanHandler(client Provider) queue.Handler {
 	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
 		if client == nil {
-			return nil, errors.New("openrouter client disabled")
+			return nil, errors.New("llm provider disabled")
 		}
 		var p LessonPlanPayload
 		if err := json.Unmarshal(job.Payload, &p); err != nil {
@@ -58,16 +72,10 @@ func MakeLessonPlanHandler(client *OpenRouterClient) queue.Handler {
 		progress(20)
 
 		msgs := buildLessonPlanMessages(p)
-		raw, err := client.Chat(ctx, msgs)
-		if err != nil {
-			return nil, err
-		}
-		progress(80)
-
-		clean := extractJSON(raw)
+		ctx = WithCall(ctx, CallInfo{UserID: job.UserID, Feature: FeatureLessonPlan})
 		var result LessonPlanResult
-		if err := json.Unmarshal([]byte(clean), &result); err != nil {
-			return nil, fmt.Errorf("model returned non-JSON: %w (raw: %s)", err, truncate(raw, 200))
+		if err := ChatStructured(ctx, client, lessonPlanTask, msgs, &result); err != nil {
+			return nil, err
 		}
 		progress(100)
 		return &result, nil
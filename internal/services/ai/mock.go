@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// MockRule — сценарий жолы: соңғы user хабарламасында Contains болса, Reply қайтарылады.
+// Contains бос болса, кез келген сұрауға сәйкес келеді. Reply бос болса, құрылымды
+// тапсырмаға оның схемасынан жасалған жарамды JSON, қарапайым чатқа defaultMockReply беріледі.
+type MockRule struct {
+	Contains string `json:"contains"`
+	Reply    string `json:"reply"`
+	Error    string `json:"error,omitempty"` // бос емес болса, жауаптың орнына қате
+}
+
+// MockProvider — желісіз детерминделген провайдер (CI, жергілікті тексеру).
+// Ережелер ретімен тексеріледі, бірінші сәйкесі қолданылады.
+type MockProvider struct {
+	mu    sync.Mutex
+	rules []MockRule
+	calls [][]Message
+}
+
+func NewMockProvider(rules ...MockRule) *MockProvider {
+	return &MockProvider{rules: rules}
+}
+
+// defaultMockReply — Reply бос ережеде құрылымсыз сұрауға (тьютор) жауап
+const defaultMockReply = "This is a mock reply."
+
+// NewMockFromEnv — LLM_MOCK_SCRIPT: []MockRule JSON файлы. Жоқ болса, әр сұрауға
+// әдепкі жауап: AI тапсырмаларына — схемаға сай JSON.
+func NewMockFromEnv() (*MockProvider, error) {
+	path := os.Getenv("LLM_MOCK_SCRIPT")
+	if path == "" {
+		return NewMockProvider(MockRule{}), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LLM_MOCK_SCRIPT: %w", err)
+	}
+	var rules []MockRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return nil, fmt.Errorf("LLM_MOCK_SCRIPT: %w", err)
+	}
+	return NewMockProvider(rules...), nil
+}
+
+func (m *MockProvider) Name() string { return ProviderMock }
+
+// Calls — провайдерге жіберілген барлық сұраулардың көшірмесі.
+func (m *MockProvider) Calls() [][]Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([][]Message, len(m.calls))
+	copy(out, m.calls)
+	return out
+}
+
+func (m *MockProvider) Chat(ctx context.Context, messages []Message) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	m.calls = append(m.calls, append([]Message(nil), messages...))
+	m.mu.Unlock()
+
+	prompt := lastUserMessage(messages)
+	for _, r := range m.rules {
+		if r.Contains != "" && !strings.Contains(prompt, r.Contains) {
+			continue
+		}
+		if r.Error != "" {
+			return "", errors.New(r.Error)
+		}
+		reply := r.Reply
+		if reply == "" {
+			reply = defaultReply(ctx)
+		}
+		reportUsage(ctx, estimateUsage(ProviderMock, messages, utf8.RuneCountInString(reply)))
+		return reply, nil
+	}
+	return "", errors.New("mock: no scripted reply")
+}
+
+// ChatStream — сценарий жауабын сөз бойынша бөліп жібереді.
+func (m *MockProvider) ChatStream(ctx context.Context, messages []Message, ch chan<- string) error {
+	reply, err := m.Chat(ctx, messages)
+	if err != nil {
+		return err
+	}
+	for _, part := range strings.SplitAfter(reply, " ") {
+		if part == "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- part:
+		}
+	}
+	return nil
+}
+
+// defaultReply — ChatStructured ішінде схемадан жасалған JSON, әйтпесе defaultMockReply.
+func defaultReply(ctx context.Context) string {
+	task := responseFormatFrom(ctx)
+	if task == nil || task.Schema == nil {
+		return defaultMockReply
+	}
+	raw, err := json.Marshal(task.Schema.sample(0))
+	if err != nil {
+		return defaultMockReply
+	}
+	return string(raw)
+}
+
+func lastUserMessage(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
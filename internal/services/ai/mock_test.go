@@ -0,0 +1,41 @@
+package ai
+
+import (
+	"context"
+	"testing"
+)
+
+// Әдепкі mock әр AI тапсырмасына жөндеусіз, бірінші жауаппен схемадан өтуі керек.
+func TestDefaultMockSatisfiesTaskSchemas(t *testing.T) {
+	t.Setenv("LLM_MOCK_SCRIPT", "")
+	tasks := []*Task{
+		evaluateTask([]EvaluateCriterion{{Name: "Structure", MaxScore: 10}, {Name: "Grammar", MaxScore: 5}}),
+		lessonPlanTask,
+		testGenerateTask(5),
+		improveTask,
+		EssayReviewTask(20),
+	}
+	for _, task := range tasks {
+		mock, err := NewMockFromEnv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]any
+		msgs := []Message{{Role: "user", Content: "anything"}}
+		if err := ChatStructured(context.Background(), mock, task, msgs, &out); err != nil {
+			t.Errorf("%s: %v", task.Name, err)
+			continue
+		}
+		if n := len(mock.Calls()); n != 1 {
+			t.Errorf("%s: calls = %d, want 1 (no repair)", task.Name, n)
+		}
+	}
+}
+
+func TestDefaultMockPlainChat(t *testing.T) {
+	mock := NewMockProvider(MockRule{})
+	reply, err := mock.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err != nil || reply != defaultMockReply {
+		t.Fatalf("reply = %q, err = %v", reply, err)
+	}
+}
@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// OllamaClient — жергілікті Ollama сервері (/api/chat, NDJSON stream).
+type OllamaClient struct {
+	host     string
+	model    string
+	jsonMode string
+	http     *http.Client
+}
+
+type ollamaRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Format   any       `json:"format,omitempty"` // "json" немесе JSON Schema
+}
+
+// ollamaChunk — stream-де әр жол, stream=false кезде бүкіл жауап.
+type ollamaChunk struct {
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	Error           string  `json:"error,omitempty"`
+	PromptEvalCount int     `json:"prompt_eval_count,omitempty"` // done=true жолында
+	EvalCount       int     `json:"eval_count,omitempty"`
+}
+
+func (c *OllamaClient) report(ctx context.Context, messages []Message, last ollamaChunk, completionChars int) {
+	if last.PromptEvalCount > 0 || last.EvalCount > 0 {
+		reportUsage(ctx, Usage{Model: c.model, PromptTokens: last.PromptEvalCount, CompletionTokens: last.EvalCount})
+		return
+	}
+	if completionChars > 0 {
+		reportUsage(ctx, estimateUsage(c.model, messages, completionChars))
+	}
+}
+
+func NewOllamaClient(host, model string) *OllamaClient {
+	return &OllamaClient{
+		host:     strings.TrimRight(host, "/"),
+		model:    model,
+		jsonMode: jsonModeFromEnv(),
+		// Жергілікті модель баяу жүктелуі мүмкін
+		http: &http.Client{Timeout: 180 * time.Second},
+	}
+}
+
+// NewOllamaFromEnv — OLLAMA_HOST (әдепкі http://localhost:11434), OLLAMA_MODEL (әдепкі llama3.1).
+func NewOllamaFromEnv() *OllamaClient {
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		host = "http://localhost:11434"
+	}
+	model := os.Getenv("OLLAMA_MODEL")
+	if model == "" {
+		model = "llama3.1"
+	}
+	return NewOllamaClient(host, model)
+}
+
+func (c *OllamaClient) Name() string { return ProviderOllama }
+
+func (c *OllamaClient) Model() string { return c.model }
+
+func (c *OllamaClient) WithModel(model string) Provider {
+	cp := *c
+	cp.model = model
+	return &cp
+}
+
+func (c *OllamaClient) do(ctx context.Context, messages []Message, stream bool) (*http.Response, error) {
+	req := ollamaRequest{Model: c.model, Messages: messages, Stream: stream}
+	if task := responseFormatFrom(ctx); task != nil && !stream {
+		switch c.jsonMode {
+		case JSONModeSchema:
+			req.Format = schemaJSON(task.Schema)
+		case JSONModeObject:
+			req.Format = "json"
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		raw, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, newHTTPError(ProviderOllama, resp, raw)
+	}
+	return resp, nil
+}
+
+func (c *OllamaClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	resp, err := c.do(ctx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var parsed ollamaChunk
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", err
+	}
+	if parsed.Error != "" {
+		return "", errors.New(parsed.Error)
+	}
+	if parsed.Message.Content == "" {
+		return "", errors.New("empty response")
+	}
+	c.report(ctx, messages, parsed, utf8.RuneCountInString(parsed.Message.Content))
+	return parsed.Message.Content, nil
+}
+
+func (c *OllamaClient) ChatStream(ctx context.Context, messages []Message, ch chan<- string) error {
+	resp, err := c.do(ctx, messages, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var last ollamaChunk
+	completionChars := 0
+	defer func() { c.report(ctx, messages, last, completionChars) }()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue
+		}
+		if chunk.Error != "" {
+			return errors.New(chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			completionChars += utf8.RuneCountInString(chunk.Message.Content)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ch <- chunk.Message.Content:
+			}
+		}
+		if chunk.Done {
+			last = chunk
+			break
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,304 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Message — формат OpenAI-совместимых чатов.
+type Message struct {
+	Role    string `json:"role"`    // "system" | "user" | "assistant"
+	Content string `json:"content"`
+}
+
+type ChatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Stream         bool            `json:"stream"`
+	StreamOptions  *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat — JSON mode: json_schema или json_object.
+type responseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *jsonSchemaSpec `json:"json_schema,omitempty"`
+}
+
+type jsonSchemaSpec struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
+}
+
+// streamOptions — include_usage: последний чанк стрима содержит usage.
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// tokenUsage — поле usage ответа chat completions.
+type tokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message Message `json:"message"`
+	} `json:"choices"`
+	Usage *tokenUsage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+const openRouterBaseURL = "https://openrouter.ai/api/v1"
+
+// OpenAIClient — клиент к любому OpenAI-совместимому /chat/completions
+// (OpenRouter, OpenAI, vLLM, LM Studio и т.п.).
+type OpenAIClient struct {
+	name     string
+	baseURL  string // без завершающего "/", например https://api.openai.com/v1
+	apiKey   string // может быть пустым для локальных серверов
+	model    string
+	jsonMode string // LLM_JSON_MODE: schema | object | off
+	headers  map[string]string
+	http     *http.Client
+}
+
+// NewOpenAIClient — клиент к произвольному OpenAI-совместимому серверу.
+func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		name:     ProviderOpenAI,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		jsonMode: jsonModeFromEnv(),
+		http:     &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// NewOpenAIFromEnv — LLM_BASE_URL, LLM_MODEL обязательны, LLM_API_KEY — нет.
+func NewOpenAIFromEnv() (*OpenAIClient, error) {
+	baseURL := os.Getenv("LLM_BASE_URL")
+	if baseURL == "" {
+		return nil, errors.New("LLM_BASE_URL is not set")
+	}
+	model := os.Getenv("LLM_MODEL")
+	if model == "" {
+		return nil, errors.New("LLM_MODEL is not set")
+	}
+	return NewOpenAIClient(baseURL, os.Getenv("LLM_API_KEY"), model), nil
+}
+
+func NewOpenRouterFromEnv() (*OpenAIClient, error) {
+	key := os.Getenv("OPENROUTER_API_KEY")
+	if key == "" {
+		return nil, errors.New("OPENROUTER_API_KEY is not set")
+	}
+	model := os.Getenv("OPENROUTER_MODEL")
+	if model == "" {
+		model = "openai/gpt-oss-120b:free"
+	}
+	c := NewOpenAIClient(openRouterBaseURL, key, model)
+	c.name = ProviderOpenRouter
+	c.headers = map[string]string{
+		"HTTP-Referer": "https://smartcourse.local",
+		"X-Title":      "SmartCourse",
+	}
+	return c, nil
+}
+
+func (c *OpenAIClient) Name() string { return c.name }
+
+func (c *OpenAIClient) Model() string { return c.model }
+
+// WithModel — копия клиента с другой моделью (резервные модели).
+func (c *OpenAIClient) WithModel(model string) Provider {
+	cp := *c
+	cp.model = model
+	return &cp
+}
+
+// responseFormat — JSON mode для ChatStructured; nil для обычных запросов или LLM_JSON_MODE=off.
+func (c *OpenAIClient) responseFormat(task *Task) *responseFormat {
+	if task == nil {
+		return nil
+	}
+	switch c.jsonMode {
+	case JSONModeOff:
+		return nil
+	case JSONModeObject:
+		return &responseFormat{Type: "json_object"}
+	}
+	return &responseFormat{
+		Type:       "json_schema",
+		JSONSchema: &jsonSchemaSpec{Name: task.Name, Schema: schemaJSON(task.Schema)},
+	}
+}
+
+// newRequest — POST {baseURL}/chat/completions с авторизацией и доп. заголовками.
+func (c *OpenAIClient) newRequest(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.baseURL+"/chat/completions",
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// Chat выполняет один синхронный запрос и возвращает ответ модели.
+func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	if c == nil {
+		return "", errors.New("llm client is nil")
+	}
+	body, err := json.Marshal(ChatRequest{
+		Model:          c.model,
+		Messages:       messages,
+		ResponseFormat: c.responseFormat(responseFormatFrom(ctx)),
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := c.newRequest(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 400 {
+		return "", newHTTPError(c.name, resp, raw)
+	}
+	var parsed chatResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return "", err
+	}
+	if parsed.Error != nil {
+		return "", errors.New(parsed.Error.Message)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", errors.New("empty response")
+	}
+	out := parsed.Choices[0].Message.Content
+	if parsed.Usage != nil {
+		reportUsage(ctx, Usage{Model: c.model, PromptTokens: parsed.Usage.PromptTokens, CompletionTokens: parsed.Usage.CompletionTokens})
+	} else {
+		reportUsage(ctx, estimateUsage(c.model, messages, utf8.RuneCountInString(out)))
+	}
+	return out, nil
+}
+
+// streamChunk — SSE delta формат.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *tokenUsage `json:"usage,omitempty"`
+}
+
+// ChatStream — SSE streaming запрос. Дельталарды ch арналы арқылы жібереді.
+// ch жабылғаннан кейін функция қайтарылады.
+func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message, ch chan<- string) error {
+	if c == nil {
+		return errors.New("llm client is nil")
+	}
+	body, err := json.Marshal(ChatRequest{
+		Model:         c.model,
+		Messages:      messages,
+		Stream:        true,
+		StreamOptions: &streamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	streamClient := &http.Client{Timeout: 120 * time.Second}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		raw, _ := io.ReadAll(resp.Body)
+		return newHTTPError(c.name+" stream", resp, raw)
+	}
+
+	// usage приходит последним чанком (после finish_reason); без него — оценка по тексту
+	var usage *tokenUsage
+	completionChars := 0
+	defer func() {
+		if usage != nil {
+			reportUsage(ctx, Usage{Model: c.model, PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens})
+		} else if completionChars > 0 {
+			reportUsage(ctx, estimateUsage(c.model, messages, completionChars))
+		}
+	}()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+		if strings.TrimSpace(payload) == "[DONE]" {
+			break
+		}
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		completionChars += utf8.RuneCountInString(delta)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- delta:
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Provider — LLM бэкенді. Воркерлер, tutor және эссе тексеру тек осы интерфейсті біледі.
+type Provider interface {
+	// Chat — бір синхронды сұрау, модельдің толық жауабын қайтарады.
+	Chat(ctx context.Context, messages []Message) (string, error)
+	// ChatStream — жауапты бөліктермен ch арнасына жібереді; ch-ты жаппайды.
+	ChatStream(ctx context.Context, messages []Message, ch chan<- string) error
+	// Name — логтар мен қателерге арналған атау: "openrouter", "openai", "ollama", "mock".
+	Name() string
+}
+
+// Провайдер түрлері (LLM_PROVIDER)
+const (
+	ProviderOpenRouter = "openrouter"
+	ProviderOpenAI     = "openai" // кез келген OpenAI-compatible base URL (vLLM, LM Studio, Azure proxy ...)
+	ProviderOllama     = "ollama"
+	ProviderMock       = "mock"
+)
+
+// NewProviderFromEnv — LLM_PROVIDER бойынша провайдер құрады.
+// Бос болса бұрынғыдай OpenRouter қолданылады. Баптау жоқ болса қате қайтарады;
+// ол кезде AI мүмкіндіктері өшіріледі. Желілік провайдерлер Resilient-ке оралады
+// (қайталау, резервтік модельдер, circuit breaker); mock — жоқ, CI-да кідіріс болмасын.
+func NewProviderFromEnv() (Provider, error) {
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	var base Provider
+	switch kind {
+	case "", ProviderOpenRouter:
+		c, err := NewOpenRouterFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		base = c
+	case ProviderOpenAI:
+		c, err := NewOpenAIFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		base = c
+	case ProviderOllama:
+		base = NewOllamaFromEnv()
+	case ProviderMock:
+		m, err := NewMockFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q", kind)
+	}
+	return NewResilient(base, ResiliencePolicyFromEnv()), nil
+}
@@ -0,0 +1,385 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rest-project/internal/services/metrics"
+)
+
+// ErrCircuitOpen — все модели провайдера временно отключены circuit breaker-ом.
+var ErrCircuitOpen = errors.New("llm provider is temporarily unavailable")
+
+// HTTPError — ответ провайдера с кодом >= 400.
+type HTTPError struct {
+	Provider   string
+	Status     int
+	Body       string
+	RetryAfter time.Duration // из заголовка Retry-After, 0 если нет
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %d: %s", e.Provider, e.Status, e.Body)
+}
+
+func newHTTPError(provider string, resp *http.Response, raw []byte) *HTTPError {
+	return &HTTPError{
+		Provider:   provider,
+		Status:     resp.StatusCode,
+		Body:       string(raw),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter — секунды или HTTP-дата.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			return 0
+		}
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// ResiliencePolicy — повторы, таймауты, резервные модели и circuit breaker.
+type ResiliencePolicy struct {
+	MaxRetries       int           // повторы сверх первой попытки на каждую модель
+	BaseDelay        time.Duration // начальная задержка экспоненциального backoff
+	MaxDelay         time.Duration // потолок задержки; больший Retry-After — сразу к резервной модели
+	Timeout          time.Duration // таймаут одной попытки Chat
+	StreamTimeout    time.Duration // таймаут одной попытки ChatStream
+	FallbackModels   []string
+	BreakerThreshold int           // подряд неудачных запросов до размыкания
+	BreakerCooldown  time.Duration // сколько breaker разомкнут до пробного запроса
+}
+
+// ResiliencePolicyFromEnv — LLM_MAX_RETRIES, LLM_RETRY_BASE_MS, LLM_RETRY_MAX_MS, LLM_TIMEOUT_SEC,
+// LLM_STREAM_TIMEOUT_SEC, LLM_FALLBACK_MODELS, LLM_BREAKER_THRESHOLD, LLM_BREAKER_COOLDOWN_SEC.
+func ResiliencePolicyFromEnv() ResiliencePolicy {
+	p := ResiliencePolicy{
+		MaxRetries:       envInt("LLM_MAX_RETRIES", 2),
+		BaseDelay:        time.Duration(envInt("LLM_RETRY_BASE_MS", 500)) * time.Millisecond,
+		MaxDelay:         time.Duration(envInt("LLM_RETRY_MAX_MS", 10000)) * time.Millisecond,
+		Timeout:          time.Duration(envInt("LLM_TIMEOUT_SEC", 60)) * time.Second,
+		StreamTimeout:    time.Duration(envInt("LLM_STREAM_TIMEOUT_SEC", 120)) * time.Second,
+		BreakerThreshold: envInt("LLM_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  time.Duration(envInt("LLM_BREAKER_COOLDOWN_SEC", 30)) * time.Second,
+	}
+	for _, m := range strings.Split(os.Getenv("LLM_FALLBACK_MODELS"), ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			p.FallbackModels = append(p.FallbackModels, m)
+		}
+	}
+	return p
+}
+
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
+
+// modelProvider — провайдер, который умеет переключать модель.
+type modelProvider interface {
+	Provider
+	Model() string
+	WithModel(model string) Provider
+}
+
+type backend struct {
+	model    string
+	provider Provider
+	breaker  *breaker
+}
+
+// Resilient — обёртка над провайдером: повторы с jitter и Retry-After,
+// переход на резервные модели и circuit breaker на каждую модель.
+type Resilient struct {
+	name     string
+	policy   ResiliencePolicy
+	backends []backend
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilient — резервные модели подключаются, только если провайдер умеет WithModel.
+func NewResilient(p Provider, policy ResiliencePolicy) *Resilient {
+	r := &Resilient{name: p.Name(), policy: policy, sleep: sleepCtx}
+	model := ""
+	if mp, ok := p.(modelProvider); ok {
+		model = mp.Model()
+		r.backends = append(r.backends, r.newBackend(model, p))
+		for _, fb := range policy.FallbackModels {
+			if fb != model {
+				r.backends = append(r.backends, r.newBackend(fb, mp.WithModel(fb)))
+			}
+		}
+	} else {
+		r.backends = append(r.backends, r.newBackend(model, p))
+	}
+	return r
+}
+
+func (r *Resilient) newBackend(model string, p Provider) backend {
+	return backend{
+		model:    model,
+		provider: p,
+		breaker:  &breaker{provider: r.name, model: model, threshold: r.policy.BreakerThreshold, cooldown: r.policy.BreakerCooldown},
+	}
+}
+
+func (r *Resilient) Name() string { return r.name }
+
+func (r *Resilient) Chat(ctx context.Context, messages []Message) (string, error) {
+	var out string
+	err := r.run(ctx, r.policy.Timeout, func(ctx context.Context, p Provider) (bool, error) {
+		var err error
+		out, err = p.Chat(ctx, messages)
+		return false, err
+	})
+	return out, err
+}
+
+// ChatStream — повтор возможен, только пока в ch ещё ничего не отправлено.
+func (r *Resilient) ChatStream(ctx context.Context, messages []Message, ch chan<- string) error {
+	return r.run(ctx, r.policy.StreamTimeout, func(ctx context.Context, p Provider) (bool, error) {
+		return relayStream(ctx, p, messages, ch)
+	})
+}
+
+// relayStream — пересылает дельты в ch и сообщает, была ли отправлена хотя бы одна.
+func relayStream(ctx context.Context, p Provider, messages []Message, ch chan<- string) (bool, error) {
+	inner := make(chan string)
+	done := make(chan struct{})
+	sent := false
+	go func() {
+		defer close(done)
+		for d := range inner {
+			if ctx.Err() != nil {
+				continue
+			}
+			select {
+			case ch <- d:
+				sent = true
+			case <-ctx.Done():
+			}
+		}
+	}()
+	err := p.ChatStream(ctx, messages, inner)
+	close(inner)
+	<-done
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return sent, err
+}
+
+// run — модели по порядку; на каждой до MaxRetries повторов.
+// call возвращает committed=true, если результат уже частично ушёл клиенту.
+func (r *Resilient) run(ctx context.Context, timeout time.Duration, call func(ctx context.Context, p Provider) (bool, error)) error {
+	var lastErr error
+	for i, b := range r.backends {
+		if i > 0 && lastErr != nil {
+			metrics.AIFallbacksTotal.WithLabelValues(r.name, r.backends[i-1].model).Inc()
+		}
+		for attempt := 0; attempt <= r.policy.MaxRetries; attempt++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !b.breaker.allow(time.Now()) {
+				metrics.AIRequestsTotal.WithLabelValues(r.name, b.model, "rejected").Inc()
+				if lastErr == nil {
+					lastErr = ErrCircuitOpen
+				}
+				break
+			}
+			if attempt > 0 {
+				metrics.AIRetriesTotal.WithLabelValues(r.name, b.model).Inc()
+			}
+
+			committed, err := r.attempt(ctx, timeout, b, call)
+			if err == nil {
+				b.breaker.success()
+				return nil
+			}
+			if ctx.Err() != nil {
+				b.breaker.release()
+				return err
+			}
+			lastErr = err
+			if !retryable(err) {
+				b.breaker.release()
+				if committed || !fallbackable(err) {
+					return err
+				}
+				break
+			}
+			b.breaker.failure(time.Now())
+			if committed {
+				return err
+			}
+			if attempt == r.policy.MaxRetries {
+				break
+			}
+			delay, ok := r.backoff(attempt, err)
+			if !ok {
+				break // Retry-After дольше потолка — сразу к резервной модели
+			}
+			if err := r.sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+	}
+	if lastErr == nil {
+		lastErr = ErrCircuitOpen
+	}
+	return lastErr
+}
+
+func (r *Resilient) attempt(ctx context.Context, timeout time.Duration, b backend, call func(ctx context.Context, p Provider) (bool, error)) (bool, error) {
+	actx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		actx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	committed, err := call(actx, b.provider)
+	metrics.AIRequestDuration.WithLabelValues(r.name, b.model).Observe(time.Since(start).Seconds())
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	metrics.AIRequestsTotal.WithLabelValues(r.name, b.model, status).Inc()
+	return committed, err
+}
+
+// backoff — full jitter: случайно в [0, min(MaxDelay, BaseDelay*2^attempt)],
+// но не меньше Retry-After. false — ждать дольше MaxDelay не будем.
+func (r *Resilient) backoff(attempt int, err error) (time.Duration, bool) {
+	ceiling := r.policy.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > r.policy.MaxDelay {
+		ceiling = r.policy.MaxDelay
+	}
+	var delay time.Duration
+	if ceiling > 0 {
+		delay = time.Duration(rand.Int64N(int64(ceiling) + 1))
+	}
+	var he *HTTPError
+	if errors.As(err, &he) && he.RetryAfter > 0 {
+		if he.RetryAfter > r.policy.MaxDelay {
+			return 0, false
+		}
+		if delay < he.RetryAfter {
+			delay = he.RetryAfter
+		}
+	}
+	return delay, true
+}
+
+// retryable — 408, 425, 429, 5xx, таймаут попытки и сетевые ошибки.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		switch {
+		case he.Status == http.StatusRequestTimeout, he.Status == http.StatusTooEarly,
+			he.Status == http.StatusTooManyRequests, he.Status >= 500:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// fallbackable — ошибку другая модель может не повторить (например, модель не найдена).
+func fallbackable(err error) bool {
+	var he *HTTPError
+	return errors.As(err, &he) && he.Status == http.StatusNotFound
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// breaker — после threshold неудач подряд размыкается на cooldown,
+// затем пропускает один пробный запрос (half-open).
+type breaker struct {
+	mu        sync.Mutex
+	provider  string
+	model     string
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures >= b.threshold && b.threshold > 0 {
+		metrics.AICircuitOpen.WithLabelValues(b.provider, b.model).Set(0)
+	}
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+		metrics.AICircuitOpen.WithLabelValues(b.provider, b.model).Set(1)
+	}
+}
+
+// release — попытка завершилась без вердикта о здоровье провайдера (4xx, отмена клиентом).
+func (b *breaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema — JSON Schema-ның шағын жиыны: провайдерге response_format ретінде жіберіледі
+// және жауап осы жиын бойынша тексеріледі.
+type Schema struct {
+	Type        string             `json:"type"` // object | array | string | integer | number | boolean
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"` // тек string үшін
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+}
+
+func Object(required []string, props map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: props, Required: required}
+}
+
+func ArrayOf(items *Schema, minItems, maxItems int) *Schema {
+	s := &Schema{Type: "array", Items: items}
+	if minItems > 0 {
+		s.MinItems = &minItems
+	}
+	if maxItems > 0 {
+		s.MaxItems = &maxItems
+	}
+	return s
+}
+
+// String — minLength > 0 болса, бос жол қабылданбайды.
+func String(minLength int, enum ...string) *Schema {
+	s := &Schema{Type: "string", Enum: enum}
+	if minLength > 0 {
+		s.MinLength = &minLength
+	}
+	return s
+}
+
+func Integer(min, max float64) *Schema {
+	return &Schema{Type: "integer", Minimum: &min, Maximum: &max}
+}
+
+func Number(min, max float64) *Schema {
+	return &Schema{Type: "number", Minimum: &min, Maximum: &max}
+}
+
+// Validate — мәнді (json.Decoder.UseNumber арқылы алынған) схемамен тексереді,
+// барлық қателерді жолымен қайтарады: "criteria[1].score: must be <= 10".
+func (s *Schema) Validate(v any) []string {
+	var errs []string
+	s.validate("$", v, &errs)
+	return errs
+}
+
+func (s *Schema) validate(path string, v any, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if val, ok := obj[name]; ok {
+				s.Properties[name].validate(path+"."+name, val, errs)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if s.MinLength != nil && len([]rune(strings.TrimSpace(str))) < *s.MinLength {
+			fail("must not be empty")
+		}
+		if len(s.Enum) > 0 {
+			for _, e := range s.Enum {
+				if str == e {
+					return
+				}
+			}
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("must be a %s", s.Type)
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail("must be a %s", s.Type)
+			return
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			fail("must be an integer")
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be <= %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// sample — схемаға сай ең қарапайым мән (mock провайдердің әдепкі жауабы).
+// i — массивтегі реті: enum мәндері кезекпен алынады.
+func (s *Schema) sample(i int) any {
+	switch s.Type {
+	case "object":
+		obj := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			obj[name] = prop.sample(0)
+		}
+		return obj
+	case "array":
+		n := 1
+		if s.MinItems != nil {
+			n = *s.MinItems
+		}
+		if s.MaxItems != nil && n > *s.MaxItems {
+			n = *s.MaxItems
+		}
+		arr := make([]any, 0, n)
+		for j := 0; j < n; j++ {
+			if s.Items == nil {
+				arr = append(arr, nil)
+				continue
+			}
+			arr = append(arr, s.Items.sample(j))
+		}
+		return arr
+	case "string":
+		if len(s.Enum) > 0 {
+			return s.Enum[i%len(s.Enum)]
+		}
+		str := "mock"
+		if s.MinLength != nil && *s.MinLength > len(str) {
+			str = strings.Repeat("m", *s.MinLength)
+		}
+		return str
+	case "integer", "number":
+		v := 0.0
+		switch {
+		case s.Minimum != nil:
+			v = *s.Minimum
+		case s.Maximum != nil && *s.Maximum < 0:
+			v = *s.Maximum
+		}
+		if s.Type == "integer" {
+			v = math.Ceil(v)
+		}
+		return v
+	case "boolean":
+		return false
+	}
+	return nil
+}
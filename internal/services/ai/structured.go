@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"rest-project/internal/services/metrics"
+)
+
+// ErrInvalidOutput — модель жөндеу әрекеттерінен кейін де схемаға сай JSON қайтармады.
+var ErrInvalidOutput = errors.New("model returned invalid structured output")
+
+// maxRepairAttempts — қате жауаптан кейін модельге қайта сұрау саны.
+const maxRepairAttempts = 2
+
+// JSON mode (LLM_JSON_MODE)
+const (
+	JSONModeSchema = "schema" // response_format json_schema / Ollama format: <schema>
+	JSONModeObject = "object" // response_format json_object / Ollama format: "json"
+	JSONModeOff    = "off"    // тек промпт арқылы
+)
+
+// Task — құрылымды жауап күтетін тапсырма: атауы (метрикалар, json_schema.name) және схемасы.
+// Check — схемамен жазуға келмейтін ережелер (out-қа жазылғаннан кейін); қателері
+// схема қателері сияқты жөндеу сұрауына кетеді.
+type Task struct {
+	Name   string
+	Schema *Schema
+	Check  func(out any) []string
+}
+
+type responseFormatKey struct{}
+
+// responseFormatFrom — ChatStructured ішінде провайдерге JSON mode сұрау.
+func responseFormatFrom(ctx context.Context) *Task {
+	t, _ := ctx.Value(responseFormatKey{}).(*Task)
+	return t
+}
+
+func jsonModeFromEnv() string {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_JSON_MODE"))); mode {
+	case JSONModeObject, JSONModeOff:
+		return mode
+	}
+	return JSONModeSchema
+}
+
+// ChatStructured — JSON mode-пен сұрайды, жауапты схемамен тексеріп out-қа жазады.
+// Жарамсыз болса, қателер тізімімен maxRepairAttempts рет қайта сұрайды.
+func ChatStructured(ctx context.Context, p Provider, task *Task, messages []Message, out any) error {
+	ctx = context.WithValue(ctx, responseFormatKey{}, task)
+	convo := append([]Message(nil), messages...)
+	var lastErrs []string
+	var raw string
+	for attempt := 0; attempt <= maxRepairAttempts; attempt++ {
+		if attempt > 0 {
+			convo = append(convo,
+				Message{Role: "assistant", Content: raw},
+				Message{Role: "user", Content: repairPrompt(task, lastErrs)},
+			)
+		}
+		var err error
+		raw, err = p.Chat(ctx, convo)
+		if err != nil {
+			return err
+		}
+		lastErrs = decodeStructured(raw, task, out)
+		if len(lastErrs) == 0 {
+			result := "ok"
+			if attempt > 0 {
+				result = "repaired"
+			}
+			metrics.AIStructuredTotal.WithLabelValues(task.Name, result).Inc()
+			return nil
+		}
+	}
+	metrics.AIStructuredTotal.WithLabelValues(task.Name, "failed").Inc()
+	return fmt.Errorf("%w (%s): %s (raw: %s)", ErrInvalidOutput, task.Name, strings.Join(lastErrs, "; "), truncate(raw, 200))
+}
+
+// decodeStructured — markdown-ды кесіп, JSON-ды схемамен тексереді, out-қа жазады
+// және task.Check ережелерін қолданады.
+func decodeStructured(raw string, task *Task, out any) []string {
+	clean := extractJSON(raw)
+	dec := json.NewDecoder(strings.NewReader(clean))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return []string{"not valid JSON: " + err.Error()}
+	}
+	if errs := task.Schema.Validate(generic); len(errs) > 0 {
+		return errs
+	}
+	if err := json.Unmarshal([]byte(clean), out); err != nil {
+		return []string{err.Error()}
+	}
+	if task.Check != nil {
+		return task.Check(out)
+	}
+	return nil
+}
+
+func repairPrompt(task *Task, errs []string) string {
+	schema, _ := json.Marshal(task.Schema)
+	var sb strings.Builder
+	sb.WriteString("Your previous reply does not match the required JSON schema:\n")
+	for _, e := range errs {
+		sb.WriteString("- " + e + "\n")
+	}
+	sb.WriteString("Reply again with ONLY the corrected JSON object, no markdown and no extra text. Schema:\n")
+	sb.Write(schema)
+	return sb.String()
+}
+
+// schemaJSON — Ollama format және OpenAI json_schema үшін.
+func schemaJSON(s *Schema) json.RawMessage {
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(s)
+	return json.RawMessage(bytes.TrimSpace(buf.Bytes()))
+}
@@ -32,10 +32,24 @@ type TestMCQ struct {
 	Difficulty   string   `json:"difficulty,omitempty"`
 }
 
-func MakeTestGenerateHandler(client *OpenRouterClient) queue.Handler {
+// testGenerateTask — әр сұрақта дәл 4 нұсқа, сұрақ саны сұралғаннан аспайды.
+func testGenerateTask(count int) *Task {
+	return &Task{Name: FeatureTestGenerate, Schema: Object([]string{"questions"}, map[string]*Schema{
+		"topic": String(0),
+		"questions": ArrayOf(Object([]string{"question", "options", "correct_index"}, map[string]*Schema{
+			"question":      String(1),
+			"options":       ArrayOf(String(1), 4, 4),
+			"correct_index": Integer(0, 3),
+			"explanation":   String(0),
+			"difficulty":    String(0, "easy", "medium", "hard"),
+		}), 1, count),
+	})}
+}
+
+func MakeTestGenerateHandler(client Provider) queue.Handler {
 	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
 		if client == nil {
-			return nil, errors.New("openrouter client disabled")
+			return nil, errors.New("llm provider disabled")
 		}
 		var p TestGeneratePayload
 		if err := json.Unmarshal(job.Payload, &p); err != nil {
@@ -59,26 +73,12 @@ func MakeTestGenerateHandler(client *OpenRouterClient) queue.Handler {
 		progress(20)
 
 		msgs := buildTestMessages(p)
-		raw, err := client.Chat(ctx, msgs)
-		if err != nil {
+		ctx = WithCall(ctx, CallInfo{UserID: job.UserID, Feature: FeatureTestGenerate})
+		var result TestGenerateResult
+		if err := ChatStructured(ctx, client, testGenerateTask(p.Count), msgs, &result); err != nil {
 			return nil, err
 		}
 		progress(80)
-
-		clean := extractJSON(raw)
-		var result TestGenerateResult
-		if err := json.Unmarshal([]byte(clean), &result); err != nil {
-			return nil, fmt.Errorf("model returned non-JSON: %w (raw: %s)", err, truncate(raw, 200))
-		}
-		// Sanity validation
-		for i := range result.Questions {
-			if len(result.Questions[i].Options) != 4 {
-				return nil, fmt.Errorf("question %d must have exactly 4 options", i+1)
-			}
-			if result.Questions[i].CorrectIndex < 0 || result.Questions[i].CorrectIndex > 3 {
-				result.Questions[i].CorrectIndex = 0
-			}
-		}
 		if result.Topic == "" {
 			result.Topic = p.Topic
 		}
@@ -0,0 +1,157 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrQuotaExceeded — пайдаланушының күндік немесе айлық токен лимиті таусылды.
+var ErrQuotaExceeded = errors.New("ai token quota exceeded")
+
+// AI мүмкіндіктері — шығынды есептеу және есептер үшін
+const (
+	FeatureTutor        = "tutor"
+	FeatureEvaluate     = "evaluate"
+	FeatureLessonPlan   = "lesson_plan"
+	FeatureTestGenerate = "test_generate"
+	FeatureImprove      = "improve"
+	FeatureEssayReview  = "essay_review"
+)
+
+// Usage — бір жауаптың токендері. Провайдер санын қайтармаса, мәтін ұзындығынан бағаланады.
+type Usage struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	Estimated        bool
+}
+
+// CallInfo — шақыру кімнің атынан және қай мүмкіндік үшін. Курс id-лардан анықталады.
+type CallInfo struct {
+	UserID       uint
+	Feature      string
+	CourseID     uint
+	AssignmentID uint
+	SubmissionID uint
+}
+
+// UsageRecord — сақталатын жазба.
+type UsageRecord struct {
+	CallInfo
+	Provider string
+	Usage
+}
+
+// UsageMeter — лимитті тексереді және шығынды сақтайды (aiusage.Service).
+type UsageMeter interface {
+	Allow(info CallInfo) error
+	Record(rec UsageRecord) error
+}
+
+type callKey struct{}
+type usageSinkKey struct{}
+
+// WithCall — ctx-ке шақыру туралы мәлімет қосады.
+func WithCall(ctx context.Context, info CallInfo) context.Context {
+	return context.WithValue(ctx, callKey{}, info)
+}
+
+func callFrom(ctx context.Context) CallInfo {
+	info, _ := ctx.Value(callKey{}).(CallInfo)
+	return info
+}
+
+// usageSink — бір шақырудағы барлық әрекеттердің (қайталау, резервтік модель) токендері.
+type usageSink struct {
+	mu    sync.Mutex
+	items []Usage
+}
+
+// reportUsage — провайдерлер жауаптан кейін шақырады; Metered жоқ болса ештеңе істемейді.
+func reportUsage(ctx context.Context, u Usage) {
+	sink, ok := ctx.Value(usageSinkKey{}).(*usageSink)
+	if !ok || (u.PromptTokens == 0 && u.CompletionTokens == 0) {
+		return
+	}
+	sink.mu.Lock()
+	sink.items = append(sink.items, u)
+	sink.mu.Unlock()
+}
+
+// estimateUsage — шамамен 4 таңба = 1 токен.
+func estimateUsage(model string, messages []Message, completionChars int) Usage {
+	prompt := 0
+	for _, m := range messages {
+		prompt += utf8.RuneCountInString(m.Content)
+	}
+	return Usage{
+		Model:            model,
+		PromptTokens:     (prompt + 3) / 4,
+		CompletionTokens: (completionChars + 3) / 4,
+		Estimated:        true,
+	}
+}
+
+// Metered — лимитті шақыру алдында тексеріп, токендерді кейін сақтайтын сыртқы қабат.
+type Metered struct {
+	Provider
+	meter UsageMeter
+}
+
+func NewMetered(p Provider, meter UsageMeter) *Metered {
+	return &Metered{Provider: p, meter: meter}
+}
+
+// Allow — ағын басталмай тұрып лимитті тексеру (tutor SSE).
+func (m *Metered) Allow(info CallInfo) error {
+	return m.meter.Allow(info)
+}
+
+func (m *Metered) Chat(ctx context.Context, messages []Message) (string, error) {
+	info := callFrom(ctx)
+	if err := m.meter.Allow(info); err != nil {
+		return "", err
+	}
+	ctx, sink := withSink(ctx)
+	out, err := m.Provider.Chat(ctx, messages)
+	m.record(info, sink)
+	return out, err
+}
+
+func (m *Metered) ChatStream(ctx context.Context, messages []Message, ch chan<- string) error {
+	info := callFrom(ctx)
+	if err := m.meter.Allow(info); err != nil {
+		return err
+	}
+	ctx, sink := withSink(ctx)
+	err := m.Provider.ChatStream(ctx, messages, ch)
+	m.record(info, sink)
+	return err
+}
+
+func withSink(ctx context.Context) (context.Context, *usageSink) {
+	sink := &usageSink{}
+	return context.WithValue(ctx, usageSinkKey{}, sink), sink
+}
+
+func (m *Metered) record(info CallInfo, sink *usageSink) {
+	sink.mu.Lock()
+	items := sink.items
+	sink.mu.Unlock()
+	for _, u := range items {
+		if err := m.meter.Record(UsageRecord{CallInfo: info, Provider: m.Name(), Usage: u}); err != nil {
+			log.Printf("[ai] usage record failed: %v", err)
+		}
+	}
+}
+
+// CheckQuota — провайдер лимитті қолдаса (Metered), шақыру алдында тексереді.
+func CheckQuota(p Provider, info CallInfo) error {
+	if m, ok := p.(interface{ Allow(CallInfo) error }); ok {
+		return m.Allow(info)
+	}
+	return nil
+}
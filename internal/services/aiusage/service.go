@@ -0,0 +1,320 @@
+package aiusage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/ai"
+)
+
+// Есептерді топтау тәсілдері
+const (
+	GroupByUser    = "user"
+	GroupByCourse  = "course"
+	GroupByFeature = "feature"
+	GroupByModel   = "model"
+	GroupByDay     = "day"
+)
+
+// Service — LLM токендерін сақтайды, күндік/айлық лимиттерді тексереді және
+// әкімшіге есеп береді. ai.UsageMeter интерфейсін іске асырады.
+type Service struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db, now: time.Now}
+}
+
+// Quota — пайдаланушыға қолданылатын лимит және оның көзі ("user", "role" немесе бос).
+type Quota struct {
+	DailyTokens   int64  `json:"daily_tokens"`
+	MonthlyTokens int64  `json:"monthly_tokens"`
+	Source        string `json:"source,omitempty"`
+}
+
+// UsageRow — есептің бір жолы.
+type UsageRow struct {
+	Key              string `json:"key"`
+	Label            string `json:"label,omitempty"`
+	Requests         int64  `json:"requests"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+	Estimated        int64  `json:"estimated_requests"`
+}
+
+// ReportFilter — есеп кезеңі (From қоса, To қоспай) және сүзгілер.
+type ReportFilter struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+	UserID   uint
+	CourseID uint
+	Feature  string
+}
+
+// UserSummary — пайдаланушының лимиті, бүгінгі және осы айдағы шығыны.
+type UserSummary struct {
+	UserID    uint       `json:"user_id"`
+	Username  string     `json:"username"`
+	Role      string     `json:"role"`
+	Quota     Quota      `json:"quota"`
+	UsedToday int64      `json:"used_today"`
+	UsedMonth int64      `json:"used_month"`
+	ByFeature []UsageRow `json:"by_feature"` // осы ай
+}
+
+// Allow — шақыру алдында: күндік немесе айлық лимит таусылса ai.ErrQuotaExceeded.
+// Тексеру шақыруға дейін жасалады, сондықтан соңғы жауап лимиттен сәл асуы мүмкін.
+func (s *Service) Allow(info ai.CallInfo) error {
+	if info.UserID == 0 {
+		return nil
+	}
+	quota, err := s.quotaFor(info.UserID)
+	if err != nil {
+		return err
+	}
+	if quota.DailyTokens == 0 && quota.MonthlyTokens == 0 {
+		return nil
+	}
+	day, month := s.periodStarts()
+	if quota.DailyTokens > 0 {
+		used, err := s.usedSince(info.UserID, day)
+		if err != nil {
+			return err
+		}
+		if used >= quota.DailyTokens {
+			return fmt.Errorf("%w: daily limit of %d tokens reached", ai.ErrQuotaExceeded, quota.DailyTokens)
+		}
+	}
+	if quota.MonthlyTokens > 0 {
+		used, err := s.usedSince(info.UserID, month)
+		if err != nil {
+			return err
+		}
+		if used >= quota.MonthlyTokens {
+			return fmt.Errorf("%w: monthly limit of %d tokens reached", ai.ErrQuotaExceeded, quota.MonthlyTokens)
+		}
+	}
+	return nil
+}
+
+// Record — бір жауаптың токендерін сақтайды; курс тапсырма не жұмыс арқылы анықталады.
+func (s *Service) Record(rec ai.UsageRecord) error {
+	row := models.AIUsage{
+		Feature:          rec.Feature,
+		Provider:         rec.Provider,
+		Model:            rec.Model,
+		PromptTokens:     rec.PromptTokens,
+		CompletionTokens: rec.CompletionTokens,
+		TotalTokens:      rec.PromptTokens + rec.CompletionTokens,
+		Estimated:        rec.Estimated,
+	}
+	if rec.UserID != 0 {
+		uid := rec.UserID
+		row.UserID = &uid
+	}
+	if courseID := s.resolveCourse(rec.CallInfo); courseID != 0 {
+		row.CourseID = &courseID
+	}
+	return s.db.Create(&row).Error
+}
+
+// Report — кезең бойынша шығын, таңдалған өріс бойынша топталған.
+func (s *Service) Report(f ReportFilter) ([]UsageRow, error) {
+	var key, label string
+	q := s.db.Table("ai_usage")
+	switch f.GroupBy {
+	case GroupByUser, "":
+		key = "COALESCE(CAST(ai_usage.user_id AS TEXT), '')"
+		label = "COALESCE(MAX(users.username), '')"
+		q = q.Joins("LEFT JOIN users ON users.id = ai_usage.user_id")
+	case GroupByCourse:
+		key = "COALESCE(CAST(ai_usage.course_id AS TEXT), '')"
+		label = "COALESCE(MAX(courses.title), '')"
+		q = q.Joins("LEFT JOIN courses ON courses.id = ai_usage.course_id")
+	case GroupByFeature:
+		key, label = "ai_usage.feature", "''"
+	case GroupByModel:
+		key, label = "ai_usage.provider || '/' || ai_usage.model", "''"
+	case GroupByDay:
+		key, label = "TO_CHAR(DATE(ai_usage.created_at), 'YYYY-MM-DD')", "''"
+	default:
+		return nil, errors.New("group_by must be one of user, course, feature, model, day")
+	}
+	if !f.From.IsZero() {
+		q = q.Where("ai_usage.created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("ai_usage.created_at < ?", f.To)
+	}
+	if f.UserID != 0 {
+		q = q.Where("ai_usage.user_id = ?", f.UserID)
+	}
+	if f.CourseID != 0 {
+		q = q.Where("ai_usage.course_id = ?", f.CourseID)
+	}
+	if f.Feature != "" {
+		q = q.Where("ai_usage.feature = ?", f.Feature)
+	}
+
+	rows := []UsageRow{}
+	err := q.Select(key + " AS key, " + label + " AS label, " +
+		"COUNT(*) AS requests, " +
+		"COALESCE(SUM(ai_usage.prompt_tokens), 0) AS prompt_tokens, " +
+		"COALESCE(SUM(ai_usage.completion_tokens), 0) AS completion_tokens, " +
+		"COALESCE(SUM(ai_usage.total_tokens), 0) AS total_tokens, " +
+		"COUNT(*) FILTER (WHERE ai_usage.estimated) AS estimated").
+		Group(key).
+		Order("total_tokens DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// UserUsage — бір пайдаланушының лимиті мен шығыны.
+func (s *Service) UserUsage(userID uint) (*UserSummary, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	quota, err := s.quotaFor(userID)
+	if err != nil {
+		return nil, err
+	}
+	day, month := s.periodStarts()
+	summary := &UserSummary{UserID: user.ID, Username: user.Username, Role: string(user.Role), Quota: *quota}
+	if summary.UsedToday, err = s.usedSince(userID, day); err != nil {
+		return nil, err
+	}
+	if summary.UsedMonth, err = s.usedSince(userID, month); err != nil {
+		return nil, err
+	}
+	summary.ByFeature, err = s.Report(ReportFilter{From: month, GroupBy: GroupByFeature, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ListQuotas — алдымен рөлдер, содан кейін жеке лимиттер.
+func (s *Service) ListQuotas() ([]models.AIQuota, error) {
+	quotas := []models.AIQuota{}
+	err := s.db.Table("ai_quotas").
+		Select("ai_quotas.*, users.username").
+		Joins("LEFT JOIN users ON users.id = ai_quotas.user_id").
+		Order("ai_quotas.user_id NULLS FIRST, ai_quotas.role, users.username").
+		Scan(&quotas).Error
+	return quotas, err
+}
+
+// SetRoleQuota — рөлдің әдепкі лимиті.
+func (s *Service) SetRoleQuota(role string, daily, monthly int64) (*models.AIQuota, error) {
+	switch models.Role(role) {
+	case models.RoleAdmin, models.RoleTeacher, models.RoleStudent:
+	default:
+		return nil, errors.New("role must be admin, teacher or student")
+	}
+	if daily < 0 || monthly < 0 {
+		return nil, errors.New("limits must not be negative")
+	}
+	var q models.AIQuota
+	err := s.db.Where("role = ? AND user_id IS NULL", role).First(&q).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	q.Role = &role
+	q.DailyTokens, q.MonthlyTokens = daily, monthly
+	return &q, s.db.Save(&q).Error
+}
+
+// SetUserQuota — рөл лимитінің орнына жеке лимит.
+func (s *Service) SetUserQuota(userID uint, daily, monthly int64) (*models.AIQuota, error) {
+	if daily < 0 || monthly < 0 {
+		return nil, errors.New("limits must not be negative")
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	var q models.AIQuota
+	err := s.db.Where("user_id = ?", userID).First(&q).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	q.UserID = &user.ID
+	q.DailyTokens, q.MonthlyTokens = daily, monthly
+	if err := s.db.Save(&q).Error; err != nil {
+		return nil, err
+	}
+	q.Username = user.Username
+	return &q, nil
+}
+
+// DeleteUserQuota — жеке лимитті алып тастайды, рөл лимиті қайта қолданылады.
+func (s *Service) DeleteUserQuota(userID uint) error {
+	res := s.db.Where("user_id = ?", userID).Delete(&models.AIQuota{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("user quota not found")
+	}
+	return nil
+}
+
+// quotaFor — жеке лимит, болмаса рөл лимиті, болмаса шексіз.
+func (s *Service) quotaFor(userID uint) (*Quota, error) {
+	var q models.AIQuota
+	err := s.db.Where("user_id = ?", userID).First(&q).Error
+	if err == nil {
+		return &Quota{DailyTokens: q.DailyTokens, MonthlyTokens: q.MonthlyTokens, Source: "user"}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	err = s.db.Where("user_id IS NULL AND role = (SELECT role FROM users WHERE id = ?)", userID).First(&q).Error
+	if err == nil {
+		return &Quota{DailyTokens: q.DailyTokens, MonthlyTokens: q.MonthlyTokens, Source: "role"}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &Quota{}, nil
+}
+
+func (s *Service) usedSince(userID uint, since time.Time) (int64, error) {
+	var used int64
+	err := s.db.Table("ai_usage").
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&used).Error
+	return used, err
+}
+
+// periodStarts — серверлік уақыт бойынша бүгінгі күннің және айдың басы.
+func (s *Service) periodStarts() (time.Time, time.Time) {
+	now := s.now()
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), time.Date(y, m, 1, 0, 0, 0, 0, now.Location())
+}
+
+func (s *Service) resolveCourse(info ai.CallInfo) uint {
+	if info.CourseID != 0 {
+		return info.CourseID
+	}
+	var courseID uint
+	switch {
+	case info.SubmissionID != 0:
+		s.db.Raw(`SELECT a.course_id FROM assignment_submissions s
+			JOIN assignments a ON a.id = s.assignment_id WHERE s.id = ?`, info.SubmissionID).Scan(&courseID)
+	case info.AssignmentID != 0:
+		s.db.Raw("SELECT course_id FROM assignments WHERE id = ?", info.AssignmentID).Scan(&courseID)
+	}
+	return courseID
+}
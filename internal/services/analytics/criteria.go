@@ -0,0 +1,97 @@
+package analytics
+
+import "gorm.io/gorm"
+
+// ── Rubric criteria ───────────────────────────────────────────────────────
+
+// CriterionStat — рубрика критерийі бойынша жиынтық: орташа балл мен пайыз,
+// жартысынан аз алған жұмыстар саны және деңгейлер бөлінісі.
+type CriterionStat struct {
+	CriterionID int            `json:"criterion_id"`
+	Name        string         `json:"name"`
+	Assignments int            `json:"assignments"`
+	Graded      int            `json:"graded"`
+	AvgScore    float64        `json:"avg_score"`
+	AvgPercent  float64        `json:"avg_percent"`
+	BelowHalf   int            `json:"below_half"`
+	Levels      map[string]int `json:"levels"`
+}
+
+// CriteriaStats — мұғалім курстарындағы рубрика бағалары критерий атауы бойынша топталады,
+// ең әлсіз критерийлер бірінші. assignmentID > 0 — бір тапсырма, courseID > 0 — бір курс.
+func (s *Service) CriteriaStats(teacherID, courseID, assignmentID uint) ([]CriterionStat, error) {
+	scoped := func() *gorm.DB {
+		q := s.db.Table("grade_criterion_scores gcs").
+			Joins("JOIN grades g ON g.id = gcs.grade_id AND g.deleted_at IS NULL").
+			Joins("JOIN assignments a ON a.id = gcs.assignment_id AND a.deleted_at IS NULL").
+			Joins("JOIN courses c ON c.id = a.course_id AND c.deleted_at IS NULL").
+			Where("c.teacher_id = ?", teacherID)
+		if courseID > 0 {
+			q = q.Where("a.course_id = ?", courseID)
+		}
+		if assignmentID > 0 {
+			q = q.Where("a.id = ?", assignmentID)
+		}
+		return q
+	}
+
+	var rows []struct {
+		CriterionID int     `gorm:"column:criterion_id"`
+		Name        string  `gorm:"column:name"`
+		Assignments int     `gorm:"column:assignments"`
+		Graded      int     `gorm:"column:graded"`
+		AvgScore    float64 `gorm:"column:avg_score"`
+		AvgPercent  float64 `gorm:"column:avg_percent"`
+		BelowHalf   int     `gorm:"column:below_half"`
+	}
+	if err := scoped().
+		Select(`MIN(gcs.criterion_id) AS criterion_id, gcs.criterion_name AS name,
+			COUNT(DISTINCT gcs.assignment_id) AS assignments, COUNT(*) AS graded,
+			COALESCE(AVG(gcs.score), 0) AS avg_score,
+			COALESCE(AVG(gcs.score * 100.0 / NULLIF(gcs.max_score, 0)), 0) AS avg_percent,
+			SUM(CASE WHEN gcs.score * 2 < gcs.max_score THEN 1 ELSE 0 END) AS below_half`).
+		Group("gcs.criterion_name").
+		Order("avg_percent ASC, name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var levels []struct {
+		Name  string `gorm:"column:name"`
+		Level string `gorm:"column:level"`
+		Count int    `gorm:"column:cnt"`
+	}
+	if err := scoped().
+		Select("gcs.criterion_name AS name, gcs.level AS level, COUNT(*) AS cnt").
+		Where("gcs.level <> ''").
+		Group("gcs.criterion_name, gcs.level").
+		Scan(&levels).Error; err != nil {
+		return nil, err
+	}
+	byName := map[string]map[string]int{}
+	for _, l := range levels {
+		if byName[l.Name] == nil {
+			byName[l.Name] = map[string]int{}
+		}
+		byName[l.Name][l.Level] = l.Count
+	}
+
+	out := make([]CriterionStat, 0, len(rows))
+	for _, r := range rows {
+		dist := byName[r.Name]
+		if dist == nil {
+			dist = map[string]int{}
+		}
+		out = append(out, CriterionStat{
+			CriterionID: r.CriterionID,
+			Name:        r.Name,
+			Assignments: r.Assignments,
+			Graded:      r.Graded,
+			AvgScore:    round2(r.AvgScore),
+			AvgPercent:  round2(r.AvgPercent),
+			BelowHalf:   r.BelowHalf,
+			Levels:      dist,
+		})
+	}
+	return out, nil
+}
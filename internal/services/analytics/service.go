@@ -16,6 +16,10 @@ func NewService(db *gorm.DB) *Service {
 	return &Service{db: db}
 }
 
+// ScorePercentSQL — бағаның тапсырма max_score-на қатысты пайызы (grades g JOIN assignments a).
+// Әр түрлі шкаладағы тапсырмаларды салыстыру үшін орташа мәндер осы пайыздан есептеледі.
+const ScorePercentSQL = "g.score * 100.0 / NULLIF(a.max_score, 0)"
+
 // ── Overview ───────────────────────────────────────────────────────────────
 
 type Overview struct {
@@ -24,7 +28,7 @@ type Overview struct {
 	AssignmentsTotal int     `json:"assignments_total"`
 	SubmissionsTotal int     `json:"submissions_total"`
 	GradesTotal      int     `json:"grades_total"`
-	AvgScore         float64 `json:"avg_score"`
+	AvgScore         float64 `json:"avg_score"`       // орташа пайыз, 0..100 (+бонус)
 	CompletionRate   float64 `json:"completion_rate"` // 0..1
 }
 
@@ -77,9 +81,10 @@ func (s *Service) Overview(teacherID uint) (*Overview, error) {
 		Avg float64
 	}
 	var ag aggRow
-	_ = s.db.Table("grades").
-		Select("COUNT(*) AS cnt, COALESCE(AVG(score),0) AS avg").
-		Where("assignment_id IN ? AND deleted_at IS NULL", assignmentIDs).
+	_ = s.db.Table("grades g").
+		Select("COUNT(*) AS cnt, COALESCE(AVG("+ScorePercentSQL+"),0) AS avg").
+		Joins("JOIN assignments a ON a.id = g.assignment_id").
+		Where("g.assignment_id IN ? AND g.deleted_at IS NULL", assignmentIDs).
 		Scan(&ag).Error
 	o.GradesTotal = int(ag.Cnt)
 	o.AvgScore = round2(ag.Avg)
@@ -96,7 +101,7 @@ func (s *Service) Overview(teacherID uint) (*Overview, error) {
 
 type GradeBucket struct {
 	Date     string  `json:"date"`      // YYYY-MM-DD
-	AvgScore float64 `json:"avg_score"`
+	AvgScore float64 `json:"avg_score"` // орташа пайыз
 	Count    int     `json:"count"`
 }
 
@@ -127,7 +132,7 @@ func (s *Service) GradesOverTime(teacherID uint, courseID uint, days int) ([]Gra
 	var rows []row
 	if err := s.db.Raw(`
 		SELECT DATE_TRUNC('day', g.created_at) AS day,
-		       COALESCE(AVG(g.score),0) AS avg,
+		       COALESCE(AVG(`+ScorePercentSQL+`),0) AS avg,
 		       COUNT(*) AS count
 		FROM grades g
 		JOIN assignments a ON a.id = g.assignment_id
@@ -159,14 +164,15 @@ type HeatmapCell struct {
 	AssignmentTitle string  `json:"assignment_title"`
 	Score           float64 `json:"score"`
 	MaxScore        float64 `json:"max_score"`
-	Status          string  `json:"status"` // graded|submitted|missing
+	Percent         float64 `json:"percent"` // score / max_score × 100
+	Status          string  `json:"status"`  // graded|submitted|missing
 }
 
 type HeatmapRow struct {
 	StudentID   uint          `json:"student_id"`
 	StudentName string        `json:"student_name"`
 	Cells       []HeatmapCell `json:"cells"`
-	AvgScore    float64       `json:"avg_score"`
+	AvgScore    float64       `json:"avg_score"` // бағаланған тапсырмалар бойынша орташа пайыз
 }
 
 type HeatmapResponse struct {
@@ -175,12 +181,24 @@ type HeatmapResponse struct {
 }
 
 type HeatmapAssignment struct {
-	ID       uint    `json:"id"`
-	Title    string  `json:"title"`
-	MaxScore float64 `json:"max_score"`
+	ID           uint    `json:"id"`
+	Title        string  `json:"title"`
+	MaxScore     float64 `json:"max_score"`
+	ScoresHidden bool    `json:"scores_hidden,omitempty"` // анонимді тексеру: аттар ашылғанша тек статус көрінеді
 }
 
+// Heatmap — мұғалімге арналған: анонимді тапсырмалардың баллдары аттар ашылғанша жасырылады.
 func (s *Service) Heatmap(teacherID uint, courseID uint) (*HeatmapResponse, error) {
+	return s.heatmap(teacherID, courseID, false)
+}
+
+// StudentHeatmap — студенттің өз журнал жолы үшін: анонимділік баллдарды жасырмайды.
+// Нәтижені мұғалімге қайтаруға болмайды.
+func (s *Service) StudentHeatmap(teacherID uint, courseID uint) (*HeatmapResponse, error) {
+	return s.heatmap(teacherID, courseID, true)
+}
+
+func (s *Service) heatmap(teacherID uint, courseID uint, revealAnonymous bool) (*HeatmapResponse, error) {
 	resp := &HeatmapResponse{Assignments: []HeatmapAssignment{}, Rows: []HeatmapRow{}}
 
 	var courseIDs []uint
@@ -200,17 +218,23 @@ func (s *Service) Heatmap(teacherID uint, courseID uint) (*HeatmapResponse, erro
 		ID       uint
 		Title    string
 		MaxScore float64 `gorm:"column:max_score"`
+		Hidden   bool    `gorm:"column:hidden"`
 	}
 	var aRows []aRow
 	if err := s.db.Table("assignments").
-		Select("id, title, max_score").
+		Select("id, title, max_score, (anonymous_grading AND deanonymized_at IS NULL) AS hidden").
 		Where("course_id IN ? AND deleted_at IS NULL", courseIDs).
 		Order("due_date ASC, id ASC").
 		Scan(&aRows).Error; err != nil {
 		return nil, err
 	}
 	for _, a := range aRows {
-		resp.Assignments = append(resp.Assignments, HeatmapAssignment{ID: a.ID, Title: a.Title, MaxScore: a.MaxScore})
+		resp.Assignments = append(resp.Assignments, HeatmapAssignment{
+			ID:           a.ID,
+			Title:        a.Title,
+			MaxScore:     a.MaxScore,
+			ScoresHidden: a.Hidden && !revealAnonymous,
+		})
 	}
 	if len(aRows) == 0 {
 		return resp, nil
@@ -286,7 +310,7 @@ func (s *Service) Heatmap(teacherID uint, courseID uint) (*HeatmapResponse, erro
 	for _, st := range sRows {
 		row := HeatmapRow{StudentID: st.ID, StudentName: st.Username}
 		var sum, cnt float64
-		for _, a := range aRows {
+		for i, a := range aRows {
 			cell := HeatmapCell{
 				AssignmentID:    a.ID,
 				AssignmentTitle: a.Title,
@@ -294,10 +318,17 @@ func (s *Service) Heatmap(teacherID uint, courseID uint) (*HeatmapResponse, erro
 				Status:          "missing",
 			}
 			if score, ok := gradeMap[st.ID][a.ID]; ok {
-				cell.Score = score
 				cell.Status = "graded"
-				sum += score
-				cnt++
+				if resp.Assignments[i].ScoresHidden {
+					row.Cells = append(row.Cells, cell)
+					continue
+				}
+				cell.Score = score
+				if a.MaxScore > 0 {
+					cell.Percent = round2(score / a.MaxScore * 100)
+					sum += cell.Percent
+					cnt++
+				}
 			} else if subStatus, ok := subMap[st.ID][a.ID]; ok {
 				cell.Status = subStatus
 			}
@@ -323,6 +354,7 @@ type AtRiskStudent struct {
 	Reason          string  `json:"reason"`
 }
 
+// AtRisk — орташа пайызы threshold-тан төмен немесе тапсырмалардың 40%-дан көбі жоқ студенттер.
 func (s *Service) AtRisk(teacherID uint, courseID uint, threshold float64) ([]AtRiskStudent, error) {
 	if threshold <= 0 {
 		threshold = 60
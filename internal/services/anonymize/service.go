@@ -0,0 +1,153 @@
+package anonymize
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service — анонимді тексеру: студенттерді тұрақты бүркеншік аттармен ауыстырады.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+type pseudonym struct {
+	AssignmentID uint   `gorm:"primaryKey"`
+	StudentID    uint   `gorm:"primaryKey"`
+	Pseudonym    string `gorm:"column:pseudonym"`
+}
+
+func (pseudonym) TableName() string { return "assignment_pseudonyms" }
+
+// pseudonymAlphabet — шатастыратын таңбаларсыз (0/O, 1/I)
+const pseudonymAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// IsActive — тапсырмада анонимді тексеру қосулы және әлі ашылмаған ба
+func (s *Service) IsActive(assignmentID uint) bool {
+	var active bool
+	s.db.Table("assignments").
+		Select("anonymous_grading AND deanonymized_at IS NULL").
+		Where("id = ?", assignmentID).
+		Scan(&active)
+	return active
+}
+
+// Labels — анонимді режимде студенттердің бүркеншік аттары (student_id -> "Student-XXXX").
+// Режим өшірулі болса, ok=false.
+func (s *Service) Labels(assignmentID uint, studentIDs []uint) (map[uint]string, bool) {
+	if !s.IsActive(assignmentID) {
+		return nil, false
+	}
+	labels, err := s.ensure(assignmentID, studentIDs)
+	if err != nil {
+		// Атын ашып қоймау үшін — тұрақсыз болса да жалпы белгі
+		labels = make(map[uint]string, len(studentIDs))
+		for _, id := range studentIDs {
+			labels[id] = "Student"
+		}
+	}
+	return labels, true
+}
+
+// AssignmentOfSubmission — жұмыс қай тапсырмаға жатады
+func (s *Service) AssignmentOfSubmission(submissionID uint) uint {
+	var assignmentID uint
+	s.db.Table("assignment_submissions").
+		Select("assignment_id").
+		Where("id = ?", submissionID).
+		Scan(&assignmentID)
+	return assignmentID
+}
+
+// Deanonymize — барлық тапсырылған жұмыстар бағаланған соң ғана аттарды ашады.
+func (s *Service) Deanonymize(assignmentID uint) error {
+	if !s.IsActive(assignmentID) {
+		return errors.New("assignment is not graded anonymously")
+	}
+	var pending int64
+	err := s.db.Raw(`
+		SELECT COUNT(*)
+		FROM assignment_submissions s
+		WHERE s.assignment_id = ?
+		  AND s.deleted_at IS NULL
+		  AND s.status IN ('submitted', 'late', 'returned')
+		  AND NOT EXISTS (
+			SELECT 1 FROM grades g
+			WHERE g.assignment_id = s.assignment_id
+			  AND g.student_id = s.student_id
+			  AND g.deleted_at IS NULL
+		  )
+	`, assignmentID).Scan(&pending).Error
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return errors.New("grading is not finished yet")
+	}
+	return s.db.Exec("UPDATE assignments SET deanonymized_at = NOW() WHERE id = ? AND deanonymized_at IS NULL", assignmentID).Error
+}
+
+// ensure — жоқ бүркеншік аттарды жасайды және барлығын қайтарады.
+func (s *Service) ensure(assignmentID uint, studentIDs []uint) (map[uint]string, error) {
+	labels := make(map[uint]string, len(studentIDs))
+	if len(studentIDs) == 0 {
+		return labels, nil
+	}
+	var rows []pseudonym
+	if err := s.db.Where("assignment_id = ? AND student_id IN ?", assignmentID, studentIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		labels[r.StudentID] = r.Pseudonym
+	}
+
+	for _, id := range studentIDs {
+		if _, ok := labels[id]; ok {
+			continue
+		}
+		// Бүркеншік ат тапсырма ішінде бірегей — соқтығысса, қайта жасаймыз
+		for attempt := 0; attempt < 5; attempt++ {
+			label, err := randomLabel()
+			if err != nil {
+				return nil, err
+			}
+			res := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&pseudonym{AssignmentID: assignmentID, StudentID: id, Pseudonym: label})
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			var stored pseudonym
+			err = s.db.Where("assignment_id = ? AND student_id = ?", assignmentID, id).First(&stored).Error
+			if err == nil {
+				labels[id] = stored.Pseudonym
+				break
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+		}
+		if _, ok := labels[id]; !ok {
+			return nil, errors.New("could not allocate pseudonym")
+		}
+	}
+	return labels, nil
+}
+
+func randomLabel() (string, error) {
+	buf := make([]byte, 4)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(pseudonymAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = pseudonymAlphabet[n.Int64()]
+	}
+	return "Student-" + string(buf), nil
+}
@@ -0,0 +1,308 @@
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/storage"
+	"rest-project/internal/utils"
+)
+
+// Service — тапсырманың барлық жұмыстарын ZIP-ке жинап, storage-ке сақтайды.
+type Service struct {
+	db      *gorm.DB
+	storage storage.StorageService
+	anon    *anonymize.Service
+}
+
+func NewService(db *gorm.DB, st storage.StorageService) *Service {
+	return &Service{db: db, storage: st, anon: anonymize.NewService(db)}
+}
+
+// LinkTTL — дайын архивке presigned сілтеменің жарамдылығы
+const LinkTTL = 24 * time.Hour
+
+// Result — job_status арқылы берілетін нәтиже
+type Result struct {
+	AssignmentID uint      `json:"assignment_id"`
+	ObjectKey    string    `json:"object_key"`
+	URL          string    `json:"url"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Submissions  int       `json:"submissions"`
+	Files        int       `json:"files"`
+	SizeBytes    int64     `json:"size_bytes"`
+}
+
+type submissionRow struct {
+	ID          uint       `gorm:"column:id"`
+	StudentID   uint       `gorm:"column:student_id"`
+	Username    string     `gorm:"column:username"`
+	Content     string     `gorm:"column:content"`
+	Answers     string     `gorm:"column:answers"`
+	Status      string     `gorm:"column:status"`
+	WordCount   int        `gorm:"column:word_count"`
+	SubmittedAt *time.Time `gorm:"column:submitted_at"`
+	GroupName   *string    `gorm:"column:group_name"`
+	Score       *float64   `gorm:"column:score"`
+	LatePenalty *float64   `gorm:"column:late_penalty"`
+}
+
+// TeacherOwns — тапсырма мұғалімнің курсына тиісті ме
+func (s *Service) TeacherOwns(assignmentID, teacherID uint) bool {
+	var owner uint
+	s.db.Table("assignments a").
+		Select("c.teacher_id").
+		Joins("JOIN courses c ON c.id = a.course_id").
+		Where("a.id = ? AND a.deleted_at IS NULL", assignmentID).
+		Scan(&owner)
+	return owner != 0 && owner == teacherID
+}
+
+// BuildSubmissionsZip — мәтіндер, тіркелген файлдар және manifest.csv бар архив.
+func (s *Service) BuildSubmissionsZip(ctx context.Context, assignmentID uint, progress func(int)) (*Result, error) {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, assignmentID).Error; err != nil {
+		return nil, errors.New("assignment not found")
+	}
+
+	var rows []submissionRow
+	err := s.db.Raw(`
+		SELECT
+			s.id, s.student_id, u.username,
+			s.content, s.answers, s.status, s.word_count, s.submitted_at,
+			ag.name AS group_name,
+			g.score, g.late_penalty
+		FROM assignment_submissions s
+		JOIN users u ON u.id = s.student_id
+		LEFT JOIN grades g ON g.student_id = s.student_id AND g.assignment_id = s.assignment_id AND g.deleted_at IS NULL
+		LEFT JOIN assignment_groups ag ON ag.id = s.group_id
+		WHERE s.assignment_id = ?
+		  AND s.deleted_at IS NULL
+		ORDER BY u.username, s.id
+	`, assignmentID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Анонимді тексеруде архивте де тек бүркеншік аттар. Реті де бүркеншік атпен:
+	// username бойынша сұрыпталған тізім кімнің кім екенін ашып қояды.
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.StudentID)
+	}
+	if labels, ok := s.anon.Labels(assignmentID, ids); ok {
+		for i := range rows {
+			rows[i].Username = labels[rows[i].StudentID]
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].Username != rows[j].Username {
+				return rows[i].Username < rows[j].Username
+			}
+			return rows[i].ID < rows[j].ID
+		})
+	}
+
+	tmp, err := os.CreateTemp("", "submissions-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	manifest := &strings.Builder{}
+	cw := csv.NewWriter(manifest)
+	_ = cw.Write([]string{"submission_id", "student", "group", "status", "submitted_at", "word_count", "score", "late_penalty", "files"})
+
+	fileTotal := 0
+	for i, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		dir := folderName(r)
+		if err := writeText(zw, dir, &assignment, r); err != nil {
+			return nil, err
+		}
+		n, err := s.writeAttachments(ctx, zw, dir, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		fileTotal += n
+
+		_ = cw.Write([]string{
+			strconv.FormatUint(uint64(r.ID), 10),
+			utils.CSVSafe(r.Username),
+			utils.CSVSafe(deref(r.GroupName)),
+			r.Status,
+			formatTime(r.SubmittedAt),
+			strconv.Itoa(r.WordCount),
+			formatFloat(r.Score),
+			formatFloat(r.LatePenalty),
+			strconv.Itoa(n),
+		})
+		if len(rows) > 0 {
+			progress(10 + 70*(i+1)/len(rows))
+		}
+	}
+	cw.Flush()
+
+	w, err := zw.Create("manifest.csv")
+	if err != nil {
+		return nil, err
+	}
+	// UTF-8 BOM — Excel кириллица/қазақ әріптерін дұрыс ашуы үшін
+	if _, err := io.WriteString(w, "\ufeff"+manifest.String()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	progress(85)
+
+	key := fmt.Sprintf("exports/submissions/%d/%s.zip", assignmentID, uuid.NewString())
+	if err := s.storage.PutObject(ctx, key, tmp, size, "application/zip"); err != nil {
+		return nil, err
+	}
+	url, err := s.storage.PresignGet(ctx, key, LinkTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		AssignmentID: assignmentID,
+		ObjectKey:    key,
+		URL:          url,
+		ExpiresAt:    time.Now().Add(LinkTTL),
+		Submissions:  len(rows),
+		Files:        fileTotal,
+		SizeBytes:    size,
+	}, nil
+}
+
+// writeText — жұмыс мәтіні тапсырма түріне қарай: эссе .txt, код .py/.js, тест answers.json
+func writeText(zw *zip.Writer, dir string, assignment *models.Assignment, r submissionRow) error {
+	name, body := "submission.txt", r.Content
+	switch models.AssignmentType(assignment.Type) {
+	case models.AssignmentTypeTest:
+		name, body = "answers.json", r.Answers
+	case models.AssignmentTypeCode:
+		name = "solution" + codeExtension(assignment.CodeLanguage)
+	}
+	if strings.TrimSpace(body) == "" || body == "null" {
+		return nil
+	}
+	w, err := zw.Create(path.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, body)
+	return err
+}
+
+func (s *Service) writeAttachments(ctx context.Context, zw *zip.Writer, dir string, submissionID uint) (int, error) {
+	var files []models.Attachment
+	if err := s.db.Where("target_type = ? AND target_id = ?", "submission", submissionID).
+		Order("id ASC").
+		Find(&files).Error; err != nil {
+		return 0, err
+	}
+	used := map[string]int{}
+	for _, f := range files {
+		name := safeName(f.Filename)
+		if n := used[name]; n > 0 {
+			ext := path.Ext(name)
+			name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), n+1, ext)
+		}
+		used[safeName(f.Filename)]++
+
+		obj, err := s.storage.GetObject(ctx, f.ObjectKey)
+		if err != nil {
+			return 0, err
+		}
+		w, err := zw.Create(path.Join(dir, "files", name))
+		if err != nil {
+			obj.Close()
+			return 0, err
+		}
+		_, err = io.Copy(w, obj)
+		obj.Close()
+		if err != nil {
+			return 0, fmt.Errorf("read %s: %w", f.Filename, err)
+		}
+	}
+	return len(files), nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// safeName — архив ішіндегі файл аты (жол бөлгіштерсіз)
+func safeName(name string) string {
+	name = unsafeChars.ReplaceAllString(path.Base(strings.ReplaceAll(name, "\\", "/")), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+func folderName(r submissionRow) string {
+	owner := r.Username
+	if r.GroupName != nil && *r.GroupName != "" {
+		owner = *r.GroupName
+	}
+	return fmt.Sprintf("%s_%d", safeName(owner), r.ID)
+}
+
+func codeExtension(lang string) string {
+	switch lang {
+	case "python":
+		return ".py"
+	case "javascript":
+		return ".js"
+	}
+	return ".txt"
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
@@ -0,0 +1,46 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"rest-project/internal/services/queue"
+)
+
+// JobType — queue-дегі тапсырма түрі
+const JobType = "submissions_zip"
+
+// JobTimeout — үлкен курстың барлық файлдарын жинауға жалпы 2 минут жетпейді
+const JobTimeout = 30 * time.Minute
+
+// Payload — Redis-queue payload.
+type Payload struct {
+	AssignmentID uint `json:"assignment_id"`
+}
+
+// MakeZipHandler — queue handler "submissions_zip" үшін; нәтиже (сілтеме) job_status-пен келеді.
+func MakeZipHandler(svc *Service) queue.Handler {
+	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
+		var p Payload
+		if err := json.Unmarshal(job.Payload, &p); err != nil {
+			return nil, fmt.Errorf("invalid payload: %w", err)
+		}
+		if p.AssignmentID == 0 {
+			return nil, errors.New("assignment_id is required")
+		}
+		// Job можно поставить и через общий /jobs — проверяем владельца ещё раз
+		if !svc.TeacherOwns(p.AssignmentID, job.UserID) {
+			return nil, errors.New("forbidden: teacher does not own this assignment")
+		}
+		progress(10)
+		result, err := svc.BuildSubmissionsZip(ctx, p.AssignmentID, progress)
+		if err != nil {
+			return nil, err
+		}
+		progress(100)
+		return result, nil
+	}
+}
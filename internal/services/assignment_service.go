@@ -10,18 +10,21 @@ import (
 )
 
 type AssignmentService struct {
-	repo       repository.AssignmentRepository
-	courseRepo repository.CourseRepository
-	userRepo   repository.UserRepository
+	repo          repository.AssignmentRepository
+	courseRepo    repository.CourseRepository
+	userRepo      repository.UserRepository
+	extensionRepo repository.AssignmentExtensionRepository
 }
 
 func NewAssignmentService(assignmentRepo repository.AssignmentRepository,
 	courseRepo repository.CourseRepository,
-	userRepo repository.UserRepository) *AssignmentService {
+	userRepo repository.UserRepository,
+	extensionRepo repository.AssignmentExtensionRepository) *AssignmentService {
 	return &AssignmentService{
-		repo:       assignmentRepo,
-		courseRepo: courseRepo,
-		userRepo:   userRepo,
+		repo:          assignmentRepo,
+		courseRepo:    courseRepo,
+		userRepo:      userRepo,
+		extensionRepo: extensionRepo,
 	}
 }
 
@@ -32,10 +35,32 @@ type CreateAssignmentRequest struct {
 	CourseID    uint                    `json:"course_id"`
 	DueDate     time.Time               `json:"due_date"`
 	MaxScore    float64                 `json:"max_score"`
-	Type        string                  `json:"type"` // "essay" | "test"
+	MaxBonus    float64                 `json:"max_bonus"` // max_score-тан жоғары рұқсат етілген балл
+	Type        string                  `json:"type"` // "essay" | "test" | "file" | "code"
 	Criteria    []models.EssayCriterion `json:"criteria"`
 	Questions   []models.TestQuestion   `json:"questions"`
 	WordCount   int                     `json:"word_count"`
+	LatePolicy  string                  `json:"late_policy"` // "none" | "percent_per_day" | "fixed"
+	LatePenalty float64                 `json:"late_penalty"`
+	CloseDate   *time.Time              `json:"close_date"`
+	IsDraft     bool                    `json:"is_draft"`
+	PublishAt   *time.Time              `json:"publish_at"` // nil — бірден жарияланады
+	GroupMode   string                  `json:"group_mode"` // "none" | "teacher" | "self_signup"
+	MaxGroupSize int                    `json:"max_group_size"`
+	AllowAttachments  bool              `json:"allow_attachments"`
+	AllowedExtensions string            `json:"allowed_extensions"` // ".pdf,.docx"
+	MaxFileSizeMB     int               `json:"max_file_size_mb"`
+	CodeLanguage string                 `json:"code_language"` // code: "python" | "javascript"
+	StarterCode  string                 `json:"starter_code"`
+	TestCases    []models.CodeTestCase  `json:"test_cases"`
+	AnonymousGrading bool               `json:"anonymous_grading"`
+}
+
+// GrantExtensionRequest — студентке жеке deadline беру
+type GrantExtensionRequest struct {
+	StudentID uint      `json:"student_id"`
+	DueDate   time.Time `json:"due_date"`
+	Reason    string    `json:"reason"`
 }
 
 // GetAllAssignments возвращает все задания
@@ -83,6 +108,9 @@ func (s *AssignmentService) GetAssignmentForStudent(id uint, studentID uint) (*m
 	if !assignment.DeletedAt.Time.IsZero() {
 		return nil, errors.New("assignment was deleted")
 	}
+	if !assignment.IsVisibleToStudents(time.Now()) {
+		return nil, errors.New("assignment not found")
+	}
 
 	courses, err := s.courseRepo.GetCoursesByStudentID(studentID)
 	if err != nil {
@@ -90,7 +118,11 @@ func (s *AssignmentService) GetAssignmentForStudent(id uint, studentID uint) (*m
 	}
 	for _, course := range courses {
 		if course.ID == assignment.CourseID {
-			return toResponse(assignment, false), nil
+			resp := toResponse(assignment, false)
+			if ext, err := s.extensionRepo.GetByStudentAndAssignment(studentID, id); err == nil {
+				resp.ExtendedDueDate = &ext.DueDate
+			}
+			return resp, nil
 		}
 	}
 
@@ -118,17 +150,36 @@ func (s *AssignmentService) CreateAssignment(req CreateAssignmentRequest, teache
 	}
 
 	// Тип тексеру
-	if req.Type != string(models.AssignmentTypeEssay) && req.Type != string(models.AssignmentTypeTest) {
+	if !models.IsValidAssignmentType(req.Type) {
 		req.Type = string(models.AssignmentTypeEssay) // default
 	}
 
 	if req.MaxScore <= 0 {
 		req.MaxScore = 100
 	}
+	if err := validateMaxBonus(&req); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateRubricLevels(req.Criteria); err != nil {
+		return nil, err
+	}
+	if err := normalizeLatePolicy(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeGroupMode(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeFileSettings(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeCodeSettings(&req); err != nil {
+		return nil, err
+	}
 
 	// JSON-ге айналдыру
 	criteriaJSON, _ := json.Marshal(req.Criteria)
 	questionsJSON, _ := json.Marshal(req.Questions)
+	testCasesJSON, _ := json.Marshal(req.TestCases)
 
 	assignment := &models.Assignment{
 		Title:       req.Title,
@@ -136,10 +187,25 @@ func (s *AssignmentService) CreateAssignment(req CreateAssignmentRequest, teache
 		CourseID:    req.CourseID,
 		DueDate:     req.DueDate,
 		MaxScore:    req.MaxScore,
+		MaxBonus:    req.MaxBonus,
 		Type:        req.Type,
 		Criteria:    string(criteriaJSON),
 		Questions:   string(questionsJSON),
 		WordCount:   req.WordCount,
+		LatePolicy:  req.LatePolicy,
+		LatePenalty: req.LatePenalty,
+		CloseDate:   req.CloseDate,
+		IsDraft:     req.IsDraft,
+		PublishAt:   req.PublishAt,
+		GroupMode:   req.GroupMode,
+		MaxGroupSize: req.MaxGroupSize,
+		AllowAttachments:  req.AllowAttachments,
+		AllowedExtensions: req.AllowedExtensions,
+		MaxFileSizeMB:     req.MaxFileSizeMB,
+		CodeLanguage: req.CodeLanguage,
+		StarterCode:  req.StarterCode,
+		TestCases:    string(testCasesJSON),
+		AnonymousGrading: req.AnonymousGrading,
 	}
 
 	err = s.repo.Create(assignment)
@@ -165,24 +231,68 @@ func (s *AssignmentService) UpdateAssignment(id uint, req CreateAssignmentReques
 		return nil, errors.New("teacher is not assigned to this course")
 	}
 
+	if err := models.ValidateRubricLevels(req.Criteria); err != nil {
+		return nil, err
+	}
 	criteriaJSON, _ := json.Marshal(req.Criteria)
 	questionsJSON, _ := json.Marshal(req.Questions)
 
-	if req.Type != string(models.AssignmentTypeEssay) && req.Type != string(models.AssignmentTypeTest) {
+	if !models.IsValidAssignmentType(req.Type) {
 		req.Type = assignment.Type
 	}
 	if req.MaxScore <= 0 {
 		req.MaxScore = assignment.MaxScore
 	}
+	if err := validateMaxBonus(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeLatePolicy(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeGroupMode(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeFileSettings(&req); err != nil {
+		return nil, err
+	}
+	if err := normalizeCodeSettings(&req); err != nil {
+		return nil, err
+	}
 
 	assignment.Title = req.Title
 	assignment.Description = req.Description
 	assignment.DueDate = req.DueDate
 	assignment.MaxScore = req.MaxScore
+	assignment.MaxBonus = req.MaxBonus
 	assignment.Type = req.Type
 	assignment.Criteria = string(criteriaJSON)
 	assignment.Questions = string(questionsJSON)
 	assignment.WordCount = req.WordCount
+	assignment.LatePolicy = req.LatePolicy
+	assignment.LatePenalty = req.LatePenalty
+	assignment.CloseDate = req.CloseDate
+	assignment.IsDraft = req.IsDraft
+	assignment.PublishAt = req.PublishAt
+	assignment.GroupMode = req.GroupMode
+	assignment.MaxGroupSize = req.MaxGroupSize
+	assignment.AllowAttachments = req.AllowAttachments
+	assignment.AllowedExtensions = req.AllowedExtensions
+	assignment.MaxFileSizeMB = req.MaxFileSizeMB
+	testCasesJSON, _ := json.Marshal(req.TestCases)
+	assignment.CodeLanguage = req.CodeLanguage
+	assignment.StarterCode = req.StarterCode
+	assignment.TestCases = string(testCasesJSON)
+	if assignment.AnonymousGrading && assignment.DeanonymizedAt == nil && !req.AnonymousGrading {
+		return nil, errors.New("anonymous grading can only be lifted by de-anonymizing after grading")
+	}
+	if req.AnonymousGrading && !assignment.AnonymousGrading {
+		assignment.DeanonymizedAt = nil
+	}
+	assignment.AnonymousGrading = req.AnonymousGrading
+	if !assignment.IsVisibleToStudents(time.Now()) {
+		// Қайта жасырылды — келесі жариялауда хабарлама қайта жіберіледі
+		assignment.PublishedAt = nil
+	}
 
 	err = s.repo.Update(id, assignment)
 	if err != nil {
@@ -211,6 +321,9 @@ func (s *AssignmentService) UpdateAssignmentCriteria(id uint, criteria []models.
 		return nil, errors.New("teacher is not assigned to this course")
 	}
 
+	if err := models.ValidateRubricLevels(criteria); err != nil {
+		return nil, err
+	}
 	criteriaJSON, err := json.Marshal(criteria)
 	if err != nil {
 		return nil, err
@@ -246,6 +359,105 @@ func (s *AssignmentService) DeleteAssignment(id uint, teacherID uint) error {
 	return s.repo.Delete(id)
 }
 
+// PublishAssignment — черновикті бірден жариялайды (publish_at тазартылады)
+func (s *AssignmentService) PublishAssignment(id, teacherID uint) (*models.AssignmentResponse, error) {
+	assignment, err := s.getOwnedAssignment(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	assignment.IsDraft = false
+	assignment.PublishAt = nil
+	if err := s.repo.Update(id, assignment); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toResponse(updated, true), nil
+}
+
+// ListExtensions — тапсырма бойынша берілген ұзартулар (teacher)
+func (s *AssignmentService) ListExtensions(assignmentID, teacherID uint) ([]models.AssignmentExtension, error) {
+	if _, err := s.getOwnedAssignment(assignmentID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.extensionRepo.GetByAssignmentID(assignmentID)
+}
+
+// GrantExtension — студентке жеке deadline береді (қайта берсе, жаңартылады)
+func (s *AssignmentService) GrantExtension(assignmentID uint, req GrantExtensionRequest, teacherID uint) (*models.AssignmentExtension, error) {
+	assignment, err := s.getOwnedAssignment(assignmentID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.DueDate.After(assignment.DueDate) {
+		return nil, errors.New("extension due_date must be after the assignment due_date")
+	}
+
+	student, err := s.userRepo.GetByID(req.StudentID)
+	if err != nil {
+		return nil, errors.New("student not found")
+	}
+	if student.Role != models.RoleStudent {
+		return nil, errors.New("user is not a student")
+	}
+	if err := s.ensureEnrolled(assignment.CourseID, req.StudentID); err != nil {
+		return nil, err
+	}
+
+	extension := &models.AssignmentExtension{
+		AssignmentID: assignmentID,
+		StudentID:    req.StudentID,
+		DueDate:      req.DueDate,
+		Reason:       req.Reason,
+		GrantedBy:    teacherID,
+	}
+	if err := s.extensionRepo.Upsert(extension); err != nil {
+		return nil, err
+	}
+	return s.extensionRepo.GetByStudentAndAssignment(req.StudentID, assignmentID)
+}
+
+// RevokeExtension — ұзартуды алып тастайды
+func (s *AssignmentService) RevokeExtension(assignmentID, studentID, teacherID uint) error {
+	if _, err := s.getOwnedAssignment(assignmentID, teacherID); err != nil {
+		return err
+	}
+	return s.extensionRepo.Delete(assignmentID, studentID)
+}
+
+// getOwnedAssignment — тапсырма мен мұғалімнің курсқа иелігін тексереді
+func (s *AssignmentService) getOwnedAssignment(id, teacherID uint) (*models.Assignment, error) {
+	assignment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	course, err := s.courseRepo.GetByID(assignment.CourseID)
+	if err != nil {
+		return nil, errors.New("course not found")
+	}
+	if course.TeacherID != teacherID {
+		return nil, errors.New("teacher is not assigned to this course")
+	}
+	return assignment, nil
+}
+
+// ensureEnrolled — студент курсқа жазылған ба
+func (s *AssignmentService) ensureEnrolled(courseID, studentID uint) error {
+	courses, err := s.courseRepo.GetCoursesByStudentID(studentID)
+	if err != nil {
+		return err
+	}
+	for _, course := range courses {
+		if course.ID == courseID {
+			return nil
+		}
+	}
+	return errors.New("student is not enrolled in this course")
+}
+
 // GetAssignmentsByCourse — teacher үшін (correctIndex көрінеді)
 func (s *AssignmentService) GetAssignmentsByCourse(courseID uint) ([]models.AssignmentResponse, error) {
 	assignments, err := s.repo.GetAssignmentsByCourseID(courseID)
@@ -259,15 +471,19 @@ func (s *AssignmentService) GetAssignmentsByCourse(courseID uint) ([]models.Assi
 	return result, nil
 }
 
-// GetAssignmentsByCourseForStudent — student үшін (correctIndex жасырылған)
+// GetAssignmentsByCourseForStudent — student үшін (correctIndex жасырылған, черновиктер көрсетілмейді)
 func (s *AssignmentService) GetAssignmentsByCourseForStudent(courseID uint) ([]models.AssignmentResponse, error) {
 	assignments, err := s.repo.GetAssignmentsByCourseID(courseID)
 	if err != nil {
 		return nil, err
 	}
-	result := make([]models.AssignmentResponse, len(assignments))
-	for i, a := range assignments {
-		result[i] = *toResponse(&a, false) // false = correctIndex жасырылады
+	now := time.Now()
+	result := make([]models.AssignmentResponse, 0, len(assignments))
+	for _, a := range assignments {
+		if !a.IsVisibleToStudents(now) {
+			continue
+		}
+		result = append(result, *toResponse(&a, false)) // false = correctIndex жасырылады
 	}
 	return result, nil
 }
@@ -287,8 +503,31 @@ func toResponse(a *models.Assignment, showCorrect bool) *models.AssignmentRespon
 		CourseID:    a.CourseID,
 		DueDate:     a.DueDate,
 		MaxScore:    a.MaxScore,
+		MaxBonus:    a.MaxBonus,
 		Type:        a.Type,
 		WordCount:   a.WordCount,
+		LatePolicy:  a.LatePolicy,
+		LatePenalty: a.LatePenalty,
+		CloseDate:   a.CloseDate,
+		IsDraft:     a.IsDraft,
+		PublishAt:   a.PublishAt,
+		PublishedAt: a.PublishedAt,
+		GroupMode:   a.GroupMode,
+		MaxGroupSize: a.MaxGroupSize,
+		AllowAttachments:  a.AllowAttachments,
+		AllowedExtensions: a.AllowedExtensions,
+		MaxFileSizeMB:     a.MaxFileSizeMB,
+		CodeLanguage: a.CodeLanguage,
+		StarterCode:  a.StarterCode,
+		AnonymousGrading: a.AnonymousGrading,
+		DeanonymizedAt: a.DeanonymizedAt,
+		GradebookCategoryID: a.GradebookCategoryID,
+		RubricID:    a.RubricID,
+		RubricVersion: a.RubricVersion,
+		GradeReleaseMode: a.GradeReleaseMode,
+		GradesReleaseAt:  a.GradesReleaseAt,
+		GradesReleasedAt: a.GradesReleasedAt,
+		RegradeWindowDays: a.RegradeWindowDays,
 		CreatedAt:   a.CreatedAt,
 		UpdatedAt:   a.UpdatedAt,
 	}
@@ -321,5 +560,13 @@ func toResponse(a *models.Assignment, showCorrect bool) *models.AssignmentRespon
 		}
 	}
 
+	// Code тест-кейстері (студентке жасырындары көрсетілмейді)
+	if tests := parseCodeTestCases(a.TestCases); len(tests) > 0 {
+		if !showCorrect {
+			tests = visibleTestCases(tests)
+		}
+		resp.TestCases = tests
+	}
+
 	return resp
 }
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/sandbox"
+)
+
+// maxCodeTestCases — әр тест жеке процесте жүреді; queue job 2 минутқа шектелген
+const maxCodeTestCases = 20
+
+// normalizeCodeSettings — code тапсырмасының тілі мен тест-кейстерін тексереді.
+func normalizeCodeSettings(req *CreateAssignmentRequest) error {
+	if req.Type != string(models.AssignmentTypeCode) {
+		req.CodeLanguage = ""
+		req.StarterCode = ""
+		req.TestCases = nil
+		return nil
+	}
+
+	req.CodeLanguage = strings.ToLower(strings.TrimSpace(req.CodeLanguage))
+	if !sandbox.IsSupported(req.CodeLanguage) {
+		return errors.New("code_language must be one of: " + strings.Join(sandbox.SupportedLanguages(), ", "))
+	}
+	if len(req.TestCases) == 0 {
+		return errors.New("code assignment requires at least one test case")
+	}
+	if len(req.TestCases) > maxCodeTestCases {
+		return errors.New("code assignment supports at most 20 test cases")
+	}
+	for i := range req.TestCases {
+		if req.TestCases[i].ID == 0 {
+			req.TestCases[i].ID = i + 1
+		}
+		if req.TestCases[i].Points < 0 {
+			return errors.New("test case points cannot be negative")
+		}
+		if req.TestCases[i].Points == 0 {
+			req.TestCases[i].Points = 1
+		}
+	}
+	return nil
+}
+
+func parseCodeTestCases(raw string) []models.CodeTestCase {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	var tests []models.CodeTestCase
+	if err := json.Unmarshal([]byte(raw), &tests); err != nil {
+		return nil
+	}
+	return tests
+}
+
+func parseCodeTestResults(raw string) []models.CodeTestResult {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	var results []models.CodeTestResult
+	if err := json.Unmarshal([]byte(raw), &results); err != nil {
+		return nil
+	}
+	return results
+}
+
+// visibleTestCases — студентке жасырын тесттерсіз
+func visibleTestCases(tests []models.CodeTestCase) []models.CodeTestCase {
+	out := make([]models.CodeTestCase, 0, len(tests))
+	for _, t := range tests {
+		if !t.Hidden {
+			out = append(out, t)
+		}
+	}
+	return out
+}
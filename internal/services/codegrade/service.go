@@ -0,0 +1,181 @@
+package codegrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/sandbox"
+)
+
+// GradeSink — сохраняет итоговую оценку (штраф за опоздание, групповые работы).
+type GradeSink interface {
+	SaveAutoGrade(submissionID uint, score float64, feedback string) error
+}
+
+// Service — прогоняет код студента по тест-кейсам задания в песочнице.
+type Service struct {
+	db     *gorm.DB
+	runner *sandbox.Runner
+	grades GradeSink
+}
+
+func NewService(db *gorm.DB, runner *sandbox.Runner, grades GradeSink) *Service {
+	return &Service{db: db, runner: runner, grades: grades}
+}
+
+// Result — результат проверки; hidden-тесты без входа/выхода.
+type Result struct {
+	SubmissionID uint                    `json:"submission_id"`
+	Score        *float64                `json:"score,omitempty"`
+	MaxScore     float64                 `json:"max_score"`
+	Passed       *int                    `json:"passed,omitempty"`
+	Total        int                     `json:"total"`
+	Tests        []models.CodeTestResult `json:"tests,omitempty"`
+	GradesHidden bool                    `json:"grades_hidden,omitempty"` // оценки ещё не опубликованы
+}
+
+// GradeSubmission — запускает все тесты, сохраняет результаты и оценку.
+func (s *Service) GradeSubmission(ctx context.Context, submissionID uint, progress func(int)) (*Result, error) {
+	var submission models.AssignmentSubmission
+	if err := s.db.First(&submission, submissionID).Error; err != nil {
+		return nil, errors.New("submission not found")
+	}
+	if submission.Status == models.SubmissionStatusDraft || submission.Status == models.SubmissionStatusReturned {
+		return nil, errors.New("submission is not submitted")
+	}
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, submission.AssignmentID).Error; err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	if assignment.Type != string(models.AssignmentTypeCode) {
+		return nil, errors.New("assignment is not a code assignment")
+	}
+
+	var tests []models.CodeTestCase
+	if err := json.Unmarshal([]byte(assignment.TestCases), &tests); err != nil || len(tests) == 0 {
+		return nil, errors.New("assignment has no test cases")
+	}
+
+	results := make([]models.CodeTestResult, 0, len(tests))
+	passed := 0
+	earned, total := 0.0, 0.0
+	for i, tc := range tests {
+		points := tc.Points
+		if points <= 0 {
+			points = 1
+		}
+		total += points
+
+		res, err := s.runner.Run(ctx, assignment.CodeLanguage, submission.Content, tc.Input)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox: %w", err)
+		}
+		r := models.CodeTestResult{
+			TestID:     tc.ID,
+			Name:       tc.Name,
+			Hidden:     tc.Hidden,
+			PointsMax:  points,
+			DurationMs: res.Duration.Milliseconds(),
+		}
+		switch {
+		case res.TimedOut || res.ExitCode < 0:
+			r.Status = models.CodeTestTimeout
+		case res.ExitCode != 0:
+			r.Status = models.CodeTestError
+		case normalizeOutput(res.Stdout) == normalizeOutput(tc.ExpectedOutput):
+			r.Status = models.CodeTestPassed
+		default:
+			r.Status = models.CodeTestFailed
+		}
+		if r.Status == models.CodeTestPassed {
+			r.Points = points
+			earned += points
+			passed++
+		}
+		if !tc.Hidden {
+			r.Input = tc.Input
+			r.Expected = tc.ExpectedOutput
+			r.Actual = res.Stdout
+			r.Stderr = res.Stderr
+		}
+		results = append(results, r)
+		progress(10 + 80*(i+1)/len(tests))
+	}
+
+	score := 0.0
+	if total > 0 {
+		score = math.Round(earned/total*assignment.MaxScore*100) / 100
+	}
+
+	resultsJSON, _ := json.Marshal(results)
+	if err := s.db.Model(&models.AssignmentSubmission{}).
+		Where("id = ?", submission.ID).
+		Update("test_results", string(resultsJSON)).Error; err != nil {
+		return nil, err
+	}
+	if err := s.grades.SaveAutoGrade(submission.ID, score, buildFeedback(results, passed)); err != nil {
+		return nil, err
+	}
+
+	// Результат уходит студенту через job_status — до публикации оценок без баллов и тестов
+	result := &Result{
+		SubmissionID: submission.ID,
+		MaxScore:     assignment.MaxScore,
+		Total:        len(tests),
+	}
+	if assignment.GradesVisible(time.Now()) {
+		result.Score = &score
+		result.Passed = &passed
+		result.Tests = results
+	} else {
+		result.GradesHidden = true
+	}
+	return result, nil
+}
+
+// buildFeedback — текстовый отчёт по каждому тесту для Grade.Feedback.
+func buildFeedback(results []models.CodeTestResult, passed int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Код автоматты түрде тексерілді: %d/%d тест өтті.\n", passed, len(results))
+	for _, r := range results {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("Тест %d", r.TestID)
+		}
+		if r.Hidden {
+			name += " (жасырын)"
+		}
+		fmt.Fprintf(&b, "- %s: %s (%.2f/%.2f)\n", name, statusLabel(r.Status), r.Points, r.PointsMax)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func statusLabel(status string) string {
+	switch status {
+	case models.CodeTestPassed:
+		return "өтті"
+	case models.CodeTestFailed:
+		return "жауап сәйкес емес"
+	case models.CodeTestTimeout:
+		return "уақыт/ресурс шегі асты"
+	default:
+		return "орындау қатесі"
+	}
+}
+
+// normalizeOutput — игнорирует \r и пробелы в конце строк и пустые строки в конце вывода.
+func normalizeOutput(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
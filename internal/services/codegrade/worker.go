@@ -0,0 +1,38 @@
+package codegrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"rest-project/internal/services/queue"
+)
+
+type gradePayload struct {
+	SubmissionID uint `json:"submission_id"`
+}
+
+// MakeGradeHandler — queue handler для "code_grade".
+func MakeGradeHandler(svc *Service) queue.Handler {
+	return func(ctx context.Context, job *queue.Job, progress func(int)) (any, error) {
+		if svc == nil {
+			return nil, errors.New("code grading disabled")
+		}
+		var p gradePayload
+		if err := json.Unmarshal(job.Payload, &p); err != nil {
+			return nil, fmt.Errorf("invalid payload: %w", err)
+		}
+		if p.SubmissionID == 0 {
+			return nil, errors.New("submission_id is required")
+		}
+
+		progress(10)
+		result, err := svc.GradeSubmission(ctx, p.SubmissionID, progress)
+		if err != nil {
+			return nil, err
+		}
+		progress(100)
+		return result, nil
+	}
+}
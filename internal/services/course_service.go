@@ -233,6 +233,7 @@ func toCourseResponse(course *models.Course) *models.CourseResponse {
 		AssignmentsCount: len(assignments),
 		Students:         students,
 		Assignments:      assignments,
+		GradingScaleID:   course.GradingScaleID,
 		CreatedAt:        course.CreatedAt,
 		UpdatedAt:        course.UpdatedAt,
 	}
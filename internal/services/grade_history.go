@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"rest-project/internal/models"
+	"rest-project/internal/repository"
+)
+
+// maxGradeReasonLen — баға өзгерту себебінің шегі (символ)
+const maxGradeReasonLen = 1000
+
+// autoGradeReason — жүйе қойған бағаның журналдағы себебі
+const autoGradeReason = "auto-grading"
+
+// GradeHistoryView — бағаның өзгеру журналы
+type GradeHistoryView struct {
+	GradeID      uint                  `json:"grade_id"`
+	AssignmentID uint                  `json:"assignment_id"`
+	Entries      []models.GradeHistory `json:"entries"`
+}
+
+// gradeChangeReason — жарияланған бағаны өзгерту не жою үшін себеп міндетті.
+func gradeChangeReason(assignment *models.Assignment, reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > maxGradeReasonLen {
+		return "", errors.New("reason is too long")
+	}
+	if reason == "" && assignment.GradesVisible(time.Now()) {
+		return "", errors.New("reason is required to change a released grade")
+	}
+	return reason, nil
+}
+
+// recordGradeChange — журналға бір жазба қосады. before nil — жаңа баға, after nil — жойылған.
+// changedBy nil — өзгерісті жүйе жасады.
+func recordGradeChange(repo repository.GradeHistoryRepository, before, after *models.Grade, reason string, changedBy *uint) error {
+	entry := &models.GradeHistory{Reason: reason, ChangedBy: changedBy}
+	switch {
+	case before == nil:
+		entry.Action = models.GradeActionCreate
+	case after == nil:
+		entry.Action = models.GradeActionDelete
+	default:
+		entry.Action = models.GradeActionUpdate
+	}
+	if before != nil {
+		score := before.Score
+		entry.GradeID, entry.AssignmentID, entry.StudentID = before.ID, before.AssignmentID, before.StudentID
+		entry.OldScore, entry.OldFeedback = &score, before.Feedback
+	}
+	if after != nil {
+		score := after.Score
+		entry.GradeID, entry.AssignmentID, entry.StudentID = after.ID, after.AssignmentID, after.StudentID
+		entry.NewScore, entry.NewFeedback = &score, after.Feedback
+	}
+	return repo.Create(entry)
+}
+
+// GetGradeHistory — бағаның журналы мұғалімге (өз курсы) немесе әкімшіге.
+// Журнал grade_id бойынша оқылады, сондықтан жойылған бағаның тарихы да қолжетімді.
+func (s *GradeService) GetGradeHistory(gradeID, userID uint, isAdmin bool) (*GradeHistoryView, error) {
+	entries, err := s.historyRepo.GetByGradeID(gradeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("grade history not found")
+	}
+	assignment, err := s.assignmentRepo.GetByID(entries[0].AssignmentID)
+	if err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	if !isAdmin {
+		course, err := s.courseRepo.GetByID(assignment.CourseID)
+		if err != nil {
+			return nil, errors.New("course not found")
+		}
+		if course.TeacherID != userID {
+			return nil, errors.New("teacher is not assigned to this course")
+		}
+		// анонимді тексеруде мұғалім студентті көрмейді
+		if assignment.AnonymousGrading && assignment.DeanonymizedAt == nil {
+			for i := range entries {
+				entries[i].StudentID = 0
+			}
+		}
+	}
+	return &GradeHistoryView{GradeID: gradeID, AssignmentID: assignment.ID, Entries: entries}, nil
+}
+
+func gradeSnapshot(g *models.Grade) *models.Grade {
+	if g == nil {
+		return nil
+	}
+	snapshot := *g
+	return &snapshot
+}
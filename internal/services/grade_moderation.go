@@ -0,0 +1,29 @@
+package services
+
+import (
+	"errors"
+
+	"rest-project/internal/models"
+)
+
+// errDoubleMarked — қос тексерудегі тапсырмаға баға тікелей қойылмайды
+var errDoubleMarked = errors.New("assignment uses double marking: grades are set through moderation")
+
+// ModerationChecker — тапсырмада қос тексеру қосулы ма (moderation.Service).
+type ModerationChecker interface {
+	DoubleMarked(assignmentID uint) bool
+}
+
+// SetModeration — қос тексеруді қосады (опционал): тікелей баға қою бұғатталады.
+func (s *GradeService) SetModeration(checker ModerationChecker) {
+	s.moderation = checker
+}
+
+// SetModeratedGrade — модерацияның қорытынды бағасын жазады: бар баға өзгертіледі,
+// болмаса жаңасы жасалады. Барлық тексерулер мен баға тарихы әдеттегідей.
+func (s *GradeService) SetModeratedGrade(assignmentID, studentID uint, score float64, feedback, reason string, teacherID uint) (*models.Grade, error) {
+	if existing, err := s.repo.GetGradeByStudentAndAssignment(studentID, assignmentID); err == nil {
+		return s.updateGrade(existing.ID, score, nil, feedback, reason, teacherID)
+	}
+	return s.createGrade(assignmentID, studentID, score, nil, feedback, reason, teacherID)
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"math"
+	"strings"
+	"time"
 	"rest-project/internal/models"
 	"rest-project/internal/repository"
 )
@@ -11,6 +14,26 @@ type GradeService struct {
 	assignmentRepo  repository.AssignmentRepository
 	courseRepo      repository.CourseRepository
 	userRepo        repository.UserRepository
+	submissionRepo  repository.AssignmentSubmissionRepository
+	extensionRepo   repository.AssignmentExtensionRepository
+	groupRepo       repository.AssignmentGroupRepository
+	revisionRepo    repository.SubmissionRevisionRepository
+	historyRepo     repository.GradeHistoryRepository
+	peerScores      PeerScoreProvider // optional
+	moderation      ModerationChecker // optional
+}
+
+// PeerScoreProvider — средняя peer-оценка работы студента и её доля в итоговой оценке.
+type PeerScoreProvider interface {
+	PeerScore(assignmentID, studentID uint) (score float64, weight float64, ok bool)
+}
+
+// GroupGradeRequest — оценка группы с индивидуальными поправками (student_id -> баллы)
+type GroupGradeRequest struct {
+	Score       float64          `json:"score"`
+	Feedback    string           `json:"feedback"`
+	Adjustments map[uint]float64 `json:"adjustments"`
+	Reason      string           `json:"reason"` // обязателен, если оценки уже опубликованы
 }
 
 func NewGradeService(
@@ -18,15 +41,30 @@ func NewGradeService(
 	assignmentRepo repository.AssignmentRepository,
 	courseRepo repository.CourseRepository,
 	userRepo repository.UserRepository,
+	submissionRepo repository.AssignmentSubmissionRepository,
+	extensionRepo repository.AssignmentExtensionRepository,
+	groupRepo repository.AssignmentGroupRepository,
+	revisionRepo repository.SubmissionRevisionRepository,
+	historyRepo repository.GradeHistoryRepository,
 ) *GradeService {
 	return &GradeService{
 		repo:           gradeRepo,
 		assignmentRepo: assignmentRepo,
 		courseRepo:     courseRepo,
 		userRepo:       userRepo,
+		submissionRepo: submissionRepo,
+		extensionRepo:  extensionRepo,
+		groupRepo:      groupRepo,
+		revisionRepo:   revisionRepo,
+		historyRepo:    historyRepo,
 	}
 }
 
+// SetPeerScores — подключает peer review для смешивания оценок (опционально).
+func (s *GradeService) SetPeerScores(provider PeerScoreProvider) {
+	s.peerScores = provider
+}
+
 // GetAllGrades возвращает все оценки
 func (s *GradeService) GetAllGrades() ([]models.Grade, error) {
 	return s.repo.GetAll()
@@ -37,8 +75,18 @@ func (s *GradeService) GetGradeByID(id uint) (*models.Grade, error) {
 	return s.repo.GetByID(id)
 }
 
-// CreateGrade создает новую оценку
-func (s *GradeService) CreateGrade(assignmentID, studentID uint, score float64, feedback string, teacherID uint) (*models.Grade, error) {
+// CreateGrade создает новую оценку.
+// Если переданы баллы по критериям рубрики, итоговый балл считается из них.
+// Для заданий с двойной проверкой оценка ставится только через модерацию.
+func (s *GradeService) CreateGrade(assignmentID, studentID uint, score float64, criteria []models.CriterionScoreInput, feedback string, teacherID uint) (*models.Grade, error) {
+	if s.moderation != nil && s.moderation.DoubleMarked(assignmentID) {
+		return nil, errDoubleMarked
+	}
+	return s.createGrade(assignmentID, studentID, score, criteria, feedback, "", teacherID)
+}
+
+// createGrade — создание оценки; reason попадает в историю оценки.
+func (s *GradeService) createGrade(assignmentID, studentID uint, score float64, criteria []models.CriterionScoreInput, feedback, reason string, teacherID uint) (*models.Grade, error) {
 	// Проверяем, что задание существует (включая удаленные)
 	assignment, err := s.assignmentRepo.GetByID(assignmentID)
 	if err != nil {
@@ -89,24 +137,71 @@ func (s *GradeService) CreateGrade(assignmentID, studentID uint, score float64,
 		return nil, errors.New("student is not enrolled in this course")
 	}
 	
-	// Проверяем, что оценка в пределах от 0 до 100
-	if score < 0 || score > 100 {
-		return nil, errors.New("score must be between 0 and 100")
+	var breakdown []models.GradeCriterionScore
+	if len(criteria) > 0 {
+		if breakdown, score, err = rubricBreakdown(assignment, criteria); err != nil {
+			return nil, err
+		}
 	}
 	
+	// Проверяем, что оценка в пределах шкалы задания (0..max_score + бонус)
+	if err := validateScore(assignment, score); err != nil {
+		return nil, err
+	}
+	
+	submission := s.findSubmission(assignment, studentID)
+	if submission != nil && submission.Status == models.SubmissionStatusReturned {
+		return nil, errors.New("submission is returned for revision")
+	}
+	
+	score, peerScore := s.blendPeerScore(assignmentID, studentID, score)
 	grade := &models.Grade{
-		StudentID:    studentID,
-		AssignmentID: assignmentID,
-		Score:        score,
-		Feedback:     feedback,
+		StudentID:      studentID,
+		AssignmentID:   assignmentID,
+		Score:          score,
+		Feedback:       feedback,
+		LatePenalty:    s.latePenaltyFor(assignment, submission, studentID, score),
+		PeerScore:      peerScore,
+		RevisionNumber: s.latestRevision(submission),
 	}
 	
-	err = s.repo.Create(grade)
-	return grade, err
+	err = s.repo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+		if err := grades.Create(grade); err != nil {
+			return err
+		}
+		if len(breakdown) > 0 {
+			if err := grades.ReplaceCriteria(grade.ID, breakdown); err != nil {
+				return err
+			}
+			grade.Criteria = breakdown
+		}
+		return recordGradeChange(history, nil, grade, reason, &teacherID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grade, nil
 }
 
-// UpdateGrade обновляет оценку
-func (s *GradeService) UpdateGrade(id uint, score float64, feedback string, teacherID uint) (*models.Grade, error) {
+// UpdateGrade обновляет оценку.
+// Оценка без критериев заменяет прежний разбор по рубрике (он удаляется).
+// После публикации оценок изменение требует причину (reason) — она попадает в журнал.
+// Для заданий с двойной проверкой оценка меняется только через модерацию.
+func (s *GradeService) UpdateGrade(id uint, score float64, criteria []models.CriterionScoreInput, feedback, reason string, teacherID uint) (*models.Grade, error) {
+	if s.moderation != nil {
+		grade, err := s.repo.GetByID(id)
+		if err != nil {
+			return nil, errors.New("grade not found")
+		}
+		if s.moderation.DoubleMarked(grade.AssignmentID) {
+			return nil, errDoubleMarked
+		}
+	}
+	return s.updateGrade(id, score, criteria, feedback, reason, teacherID)
+}
+
+// updateGrade — изменение оценки без проверки двойной проверки (её вызывает и модерация).
+func (s *GradeService) updateGrade(id uint, score float64, criteria []models.CriterionScoreInput, feedback, reason string, teacherID uint) (*models.Grade, error) {
 	// Проверяем, что оценка существует
 	grade, err := s.repo.GetByID(id)
 	if err != nil {
@@ -130,15 +225,43 @@ func (s *GradeService) UpdateGrade(id uint, score float64, feedback string, teac
 		return nil, errors.New("teacher is not assigned to this course")
 	}
 	
-	// Проверяем, что оценка в пределах от 0 до 100
-	if score < 0 || score > 100 {
-		return nil, errors.New("score must be between 0 and 100")
+	if reason, err = gradeChangeReason(assignment, reason); err != nil {
+		return nil, err
+	}
+	
+	var breakdown []models.GradeCriterionScore
+	if len(criteria) > 0 {
+		if breakdown, score, err = rubricBreakdown(assignment, criteria); err != nil {
+			return nil, err
+		}
+	}
+	
+	// Проверяем, что оценка в пределах шкалы задания (0..max_score + бонус)
+	if err := validateScore(assignment, score); err != nil {
+		return nil, err
+	}
+	
+	before := gradeSnapshot(grade)
+	submission := s.findSubmission(assignment, grade.StudentID)
+	if submission != nil && submission.Status == models.SubmissionStatusReturned {
+		return nil, errors.New("submission is returned for revision")
 	}
 	
+	score, grade.PeerScore = s.blendPeerScore(assignment.ID, grade.StudentID, score)
 	grade.Score = score
 	grade.Feedback = feedback
+	grade.LatePenalty = s.latePenaltyFor(assignment, submission, grade.StudentID, score)
+	grade.RevisionNumber = s.latestRevision(submission)
 	
-	err = s.repo.Update(id, grade)
+	err = s.repo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+		if err := grades.Update(id, grade); err != nil {
+			return err
+		}
+		if err := grades.ReplaceCriteria(id, breakdown); err != nil {
+			return err
+		}
+		return recordGradeChange(history, before, grade, reason, &teacherID)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +269,9 @@ func (s *GradeService) UpdateGrade(id uint, score float64, feedback string, teac
 	return s.repo.GetByID(id)
 }
 
-// DeleteGrade удаляет оценку
-func (s *GradeService) DeleteGrade(id uint, teacherID uint) error {
+// DeleteGrade удаляет оценку; для опубликованной оценки нужна причина.
+// Запись в журнале сохраняется и после удаления.
+func (s *GradeService) DeleteGrade(id uint, teacherID uint, reason string) error {
 	// Проверяем, что оценка существует
 	grade, err := s.repo.GetByID(id)
 	if err != nil {
@@ -171,7 +295,21 @@ func (s *GradeService) DeleteGrade(id uint, teacherID uint) error {
 		return errors.New("teacher is not assigned to this course")
 	}
 	
-	return s.repo.Delete(id)
+	// Итоговую оценку двойной проверки меняет только модерация
+	if s.moderation != nil && s.moderation.DoubleMarked(assignment.ID) {
+		return errDoubleMarked
+	}
+
+	if reason, err = gradeChangeReason(assignment, reason); err != nil {
+		return err
+	}
+	
+	return s.repo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+		if err := grades.Delete(id); err != nil {
+			return err
+		}
+		return recordGradeChange(history, grade, nil, reason, &teacherID)
+	})
 }
 
 // GetGradesByStudent возвращает оценки студента
@@ -179,7 +317,212 @@ func (s *GradeService) GetGradesByStudent(studentID uint) ([]models.Grade, error
 	return s.repo.GetGradesByStudentID(studentID)
 }
 
+// GetVisibleGradesByStudent — оценки студента без тех, что ещё не опубликованы преподавателем
+func (s *GradeService) GetVisibleGradesByStudent(studentID uint) ([]models.Grade, error) {
+	grades, err := s.repo.GetGradesByStudentID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	visible := map[uint]bool{}
+	out := make([]models.Grade, 0, len(grades))
+	for _, g := range grades {
+		shown, ok := visible[g.AssignmentID]
+		if !ok {
+			assignment, err := s.assignmentRepo.GetByID(g.AssignmentID)
+			shown = err == nil && assignment.GradesVisible(now)
+			visible[g.AssignmentID] = shown
+		}
+		if shown {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
 // GetGradesByAssignment возвращает оценки по заданию
 func (s *GradeService) GetGradesByAssignment(assignmentID uint) ([]models.Grade, error) {
 	return s.repo.GetGradesByAssignmentID(assignmentID)
 }
+
+// GradeGroup выставляет одну оценку группе и раздаёт её каждому участнику.
+// Итоговый балл участника = score + его поправка.
+func (s *GradeService) GradeGroup(assignmentID, groupID uint, req GroupGradeRequest, teacherID uint) ([]models.Grade, error) {
+	assignment, err := s.assignmentRepo.GetByID(assignmentID)
+	if err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	if !assignment.DeletedAt.Time.IsZero() {
+		return nil, errors.New("cannot create grade for deleted assignment")
+	}
+	course, err := s.courseRepo.GetByID(assignment.CourseID)
+	if err != nil {
+		return nil, errors.New("course not found")
+	}
+	if course.TeacherID != teacherID {
+		return nil, errors.New("teacher is not assigned to this course")
+	}
+	if s.moderation != nil && s.moderation.DoubleMarked(assignmentID) {
+		return nil, errDoubleMarked
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len([]rune(reason)) > maxGradeReasonLen {
+		return nil, errors.New("reason is too long")
+	}
+
+	group, err := s.groupRepo.GetByID(groupID)
+	if err != nil || group.AssignmentID != assignmentID {
+		return nil, errors.New("group not found")
+	}
+	if len(group.Members) == 0 {
+		return nil, errors.New("group has no members")
+	}
+
+	isMember := make(map[uint]bool, len(group.Members))
+	for _, m := range group.Members {
+		isMember[m.StudentID] = true
+	}
+	for studentID := range req.Adjustments {
+		if !isMember[studentID] {
+			return nil, errors.New("adjustment for student outside the group")
+		}
+	}
+	for _, m := range group.Members {
+		final := req.Score + req.Adjustments[m.StudentID]
+		if err := validateScore(assignment, final); err != nil {
+			return nil, err
+		}
+	}
+
+	// Штраф считается по общей работе группы, но с учётом продления каждого участника
+	var submission *models.AssignmentSubmission
+	if sub, err := s.submissionRepo.GetByGroupAndAssignment(group.ID, assignmentID); err == nil {
+		submission = sub
+	}
+	if submission != nil && submission.Status == models.SubmissionStatusReturned {
+		return nil, errors.New("submission is returned for revision")
+	}
+	revision := s.latestRevision(submission)
+
+	// Переоценка уже опубликованных оценок группы требует причину
+	if reason == "" && assignment.GradesVisible(time.Now()) {
+		for _, m := range group.Members {
+			if _, err := s.repo.GetGradeByStudentAndAssignment(m.StudentID, assignmentID); err == nil {
+				return nil, errors.New("reason is required to change a released grade")
+			}
+		}
+	}
+
+	// Все участники оцениваются одной транзакцией: либо вся группа с журналом, либо никто
+	result := make([]models.Grade, 0, len(group.Members))
+	err = s.repo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+		for _, m := range group.Members {
+			adjustment := req.Adjustments[m.StudentID]
+			score := math.Round((req.Score+adjustment)*100) / 100
+			penalty := 0.0
+			if submission != nil && submission.SubmittedAt != nil {
+				var extension *models.AssignmentExtension
+				if ext, err := s.extensionRepo.GetByStudentAndAssignment(m.StudentID, assignmentID); err == nil {
+					extension = ext
+				}
+				penalty = calcLatePenalty(assignment, submissionDueDate(assignment, extension, submission), *submission.SubmittedAt, score)
+			}
+
+			gid := group.ID
+			grade, err := grades.GetGradeByStudentAndAssignment(m.StudentID, assignmentID)
+			if err != nil {
+				grade = &models.Grade{StudentID: m.StudentID, AssignmentID: assignmentID}
+			}
+			var before *models.Grade
+			if grade.ID != 0 {
+				before = gradeSnapshot(grade)
+			}
+			grade.Score = score
+			grade.Feedback = req.Feedback
+			grade.LatePenalty = penalty
+			grade.GroupID = &gid
+			grade.Adjustment = adjustment
+			grade.RevisionNumber = revision
+
+			if grade.ID == 0 {
+				if err := grades.Create(grade); err != nil {
+					return err
+				}
+			} else {
+				if err := grades.Update(grade.ID, grade); err != nil {
+					return err
+				}
+				if len(grade.Criteria) > 0 {
+					// оценка группы заменяет индивидуальный разбор по рубрике
+					if err := grades.ReplaceCriteria(grade.ID, nil); err != nil {
+						return err
+					}
+					grade.Criteria = nil
+				}
+			}
+			if err := recordGradeChange(history, before, grade, reason, &teacherID); err != nil {
+				return err
+			}
+			result = append(result, *grade)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// blendPeerScore смешивает оценку преподавателя со средней peer-оценкой.
+// Возвращает итоговый балл и peer-оценку (nil, если смешивание не применялось).
+func (s *GradeService) blendPeerScore(assignmentID, studentID uint, score float64) (float64, *float64) {
+	if s.peerScores == nil {
+		return score, nil
+	}
+	peer, weight, ok := s.peerScores.PeerScore(assignmentID, studentID)
+	if !ok {
+		return score, nil
+	}
+	blended := math.Round(((1-weight)*score+weight*peer)*100) / 100
+	return blended, &peer
+}
+
+// latePenaltyFor рассчитывает штраф за опоздание для ручной оценки.
+// Штраф не вычитается из балла преподавателя — он хранится рядом для отображения.
+func (s *GradeService) latePenaltyFor(assignment *models.Assignment, submission *models.AssignmentSubmission, studentID uint, score float64) float64 {
+	if submission == nil || submission.SubmittedAt == nil {
+		return 0
+	}
+	var extension *models.AssignmentExtension
+	if ext, err := s.extensionRepo.GetByStudentAndAssignment(studentID, assignment.ID); err == nil {
+		extension = ext
+	}
+	return calcLatePenalty(assignment, submissionDueDate(assignment, extension, submission), *submission.SubmittedAt, score)
+}
+
+// findSubmission возвращает работу студента (или его группы), nil — если её нет.
+func (s *GradeService) findSubmission(assignment *models.Assignment, studentID uint) *models.AssignmentSubmission {
+	submission, err := s.submissionRepo.GetByStudentAndAssignment(studentID, assignment.ID)
+	if err != nil && assignment.IsGroupAssignment() {
+		// Групповая работа хранится на одного участника — ищем по группе
+		if group, gErr := s.groupRepo.GetByStudentAndAssignment(studentID, assignment.ID); gErr == nil {
+			submission, err = s.submissionRepo.GetByGroupAndAssignment(group.ID, assignment.ID)
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	return submission
+}
+
+// latestRevision — номер последней версии работы, к которой привязывается оценка.
+func (s *GradeService) latestRevision(submission *models.AssignmentSubmission) *int {
+	if submission == nil {
+		return nil
+	}
+	revision, err := s.revisionRepo.GetLatest(submission.ID)
+	if err != nil {
+		return nil
+	}
+	return &revision.Number
+}
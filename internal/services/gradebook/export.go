@@ -0,0 +1,117 @@
+package gradebook
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"rest-project/internal/utils"
+)
+
+// Экспорт форматтары
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ExcusedMark — босатылған ұяшықтың экспорттағы белгісі
+const ExcusedMark = "EX"
+
+// utf8BOM — Excel CSV-дегі кириллицаны дұрыс ашуы үшін
+const utf8BOM = "\ufeff"
+
+// Export — курс журналын CSV немесе XLSX ретінде қайтарады: username, әр тапсырманың балы
+// ("Атауы [#id]" бағаны — импорт осы id бойынша сәйкестендіреді), санаттар және қорытынды.
+func (s *Service) Export(teacherID, courseID uint, format string) ([]byte, string, error) {
+	matrix, err := s.Matrix(teacherID, courseID)
+	if err != nil {
+		return nil, "", err
+	}
+	rows := exportTable(matrix)
+	switch format {
+	case "", FormatCSV:
+		var buf bytes.Buffer
+		buf.WriteString(utf8BOM)
+		w := csv.NewWriter(&buf)
+		// имена и названия приходят от пользователей — не даём Excel выполнить их как формулы
+		for _, row := range rows {
+			for i := range row {
+				row[i] = utils.CSVSafe(row[i])
+			}
+		}
+		if err := w.WriteAll(rows); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "text/csv; charset=utf-8", nil
+	case FormatXLSX:
+		data, err := writeXLSX("Gradebook", rows)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return nil, "", errors.New("format must be csv or xlsx")
+}
+
+func exportTable(matrix *Matrix) [][]string {
+	header := []string{usernameColumn}
+	for _, a := range matrix.Assignments {
+		header = append(header, fmt.Sprintf("%s [#%d]", a.Title, a.ID))
+	}
+	withCategories := len(matrix.Categories) > 0
+	if withCategories {
+		for _, c := range matrix.Categories {
+			header = append(header, c.Name+" %")
+		}
+	}
+	header = append(header, "final_percent")
+	if matrix.Scale != nil {
+		header = append(header, "final_grade")
+	}
+
+	rows := [][]string{header}
+	for _, r := range matrix.Rows {
+		row := []string{r.StudentName}
+		for i, cell := range r.Cells {
+			switch {
+			case cell.Excused:
+				row = append(row, ExcusedMark)
+			case matrix.Assignments[i].ScoresHidden:
+				// анонимді тексеру: бос ұяшық — импорт оны өткізіп жібереді
+				row = append(row, "")
+			case cell.Status == "graded":
+				row = append(row, formatNumber(cell.Score))
+			default:
+				row = append(row, "")
+			}
+		}
+		if withCategories {
+			for _, c := range r.Categories {
+				row = append(row, formatPercent(c.Percent))
+			}
+		}
+		row = append(row, formatPercent(r.FinalPercent))
+		if matrix.Scale != nil {
+			grade := ""
+			if r.FinalGrade != nil {
+				grade = r.FinalGrade.Symbol
+			}
+			row = append(row, grade)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatPercent(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return formatNumber(*v)
+}
@@ -0,0 +1,359 @@
+package gradebook
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"rest-project/internal/models"
+	"rest-project/internal/utils"
+)
+
+// usernameColumn — студент бағанының атауы (экспортта және импортта)
+const usernameColumn = "username"
+
+// maxImportRows — импорттағы студенттер жолының шегі
+const maxImportRows = 5000
+
+// assignmentColumn — "Эссе 1 [#42]" → 42
+var assignmentColumn = regexp.MustCompile(`\[#(\d+)\]\s*$`)
+
+// GradeWriter — бағаларды services.GradeService арқылы жазады: курс иесі, студенттің курсқа
+// жазылуы, балл шегі және баға тарихы сол жерде тексеріледі.
+type GradeWriter interface {
+	CreateGrade(assignmentID, studentID uint, score float64, criteria []models.CriterionScoreInput, feedback string, teacherID uint) (*models.Grade, error)
+	UpdateGrade(id uint, score float64, criteria []models.CriterionScoreInput, feedback, reason string, teacherID uint) (*models.Grade, error)
+}
+
+// SetGrades — журнал импортын қосады (опционал).
+func (s *Service) SetGrades(grades GradeWriter) {
+	s.grades = grades
+}
+
+// ImportColumn — файлдағы тапсырма бағаны
+type ImportColumn struct {
+	AssignmentID uint    `json:"assignment_id"`
+	Title        string  `json:"title"`
+	MaxScore     float64 `json:"max_score"`
+}
+
+// ImportChange — импорт жасайтын бір өзгеріс
+type ImportChange struct {
+	Row             int      `json:"row"`
+	Username        string   `json:"username"`
+	StudentID       uint     `json:"student_id"`
+	AssignmentID    uint     `json:"assignment_id"`
+	AssignmentTitle string   `json:"assignment_title"`
+	Action          string   `json:"action"` // create | update
+	GradeID         uint     `json:"grade_id,omitempty"`
+	OldScore        *float64 `json:"old_score,omitempty"`
+	NewScore        float64  `json:"new_score"`
+	Released        bool     `json:"released"`           // баға студентке көрінеді — өзгертуге себеп керек
+	Conflict        string   `json:"conflict,omitempty"` // бар бағаны басып жазады
+
+	feedback string
+}
+
+// ImportIssue — импортталмайтын ұяшық немесе жол
+type ImportIssue struct {
+	Row      int    `json:"row"`
+	Column   string `json:"column,omitempty"`
+	Username string `json:"username,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ImportPreview — файлды қолданбай тұрып көрсетілетін өзгерістер
+type ImportPreview struct {
+	Columns   []ImportColumn `json:"columns"`
+	Ignored   []string       `json:"ignored_columns"`
+	Changes   []ImportChange `json:"changes"`
+	Creates   int            `json:"creates"`
+	Updates   int            `json:"updates"`
+	Conflicts int            `json:"conflicts"`
+	Unchanged int            `json:"unchanged"`
+	Issues    []ImportIssue  `json:"issues"`
+}
+
+// ImportOptions — импортты қолдану баптаулары
+type ImportOptions struct {
+	Overwrite bool   // бар бағаларды басып жазу (әйтпесе қақтығыстар өткізіледі)
+	Reason    string // жарияланған бағаны өзгерту себебі (баға тарихына жазылады)
+}
+
+// ImportResult — қолданылған импорттың нәтижесі
+type ImportResult struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Failed  []ImportIssue `json:"failed"`
+}
+
+// PreviewImport — CSV/XLSX файлын оқып, студенттерді username бойынша, тапсырмаларды
+// "[#id]" бағаны бойынша сәйкестендіреді. Деректер базасы өзгермейді.
+func (s *Service) PreviewImport(courseID uint, filename string, data []byte) (*ImportPreview, error) {
+	table, err := readTable(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if len(table)-1 > maxImportRows {
+		return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+	}
+
+	var assignments []models.Assignment
+	if err := s.db.Where("course_id = ?", courseID).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.Assignment, len(assignments))
+	for i := range assignments {
+		byID[assignments[i].ID] = &assignments[i]
+	}
+
+	preview := &ImportPreview{
+		Columns: []ImportColumn{},
+		Ignored: []string{},
+		Changes: []ImportChange{},
+		Issues:  []ImportIssue{},
+	}
+	header := table[0]
+	userCol := -1
+	columns := map[int]*models.Assignment{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, usernameColumn) && userCol < 0 {
+			userCol = i
+			continue
+		}
+		m := assignmentColumn.FindStringSubmatch(name)
+		if m == nil {
+			if name != "" {
+				preview.Ignored = append(preview.Ignored, name)
+			}
+			continue
+		}
+		id, _ := strconv.ParseUint(m[1], 10, 64)
+		a, ok := byID[uint(id)]
+		if !ok {
+			preview.Issues = append(preview.Issues, ImportIssue{Row: 1, Column: name, Message: "assignment is not in this course"})
+			continue
+		}
+		columns[i] = a
+		preview.Columns = append(preview.Columns, ImportColumn{AssignmentID: a.ID, Title: a.Title, MaxScore: a.MaxScore})
+	}
+	if userCol < 0 {
+		return nil, errors.New(`file must have a "username" column`)
+	}
+	if len(columns) == 0 {
+		return nil, errors.New(`file has no assignment columns ("Title [#id]")`)
+	}
+
+	students, err := s.enrolledStudents(courseID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.existingGrades(assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := map[string]int{}
+	for r, row := range table[1:] {
+		line := r + 2
+		username := strings.TrimSpace(cell(row, userCol))
+		if username == "" {
+			continue
+		}
+		studentID, ok := students[strings.ToLower(username)]
+		if !ok {
+			preview.Issues = append(preview.Issues, ImportIssue{Row: line, Username: username, Message: "student is not enrolled in this course"})
+			continue
+		}
+		if first, dup := seen[strings.ToLower(username)]; dup {
+			preview.Issues = append(preview.Issues, ImportIssue{Row: line, Username: username, Message: fmt.Sprintf("duplicate of row %d", first)})
+			continue
+		}
+		seen[strings.ToLower(username)] = line
+
+		for col, a := range columns {
+			raw := strings.TrimSpace(cell(row, col))
+			if raw == "" || strings.EqualFold(raw, ExcusedMark) {
+				continue
+			}
+			column := strings.TrimSpace(header[col])
+			score, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+			if err != nil {
+				preview.Issues = append(preview.Issues, ImportIssue{Row: line, Column: column, Username: username, Message: "score is not a number"})
+				continue
+			}
+			if score < 0 || score > a.MaxAllowedScore() {
+				preview.Issues = append(preview.Issues, ImportIssue{Row: line, Column: column, Username: username,
+					Message: fmt.Sprintf("score must be between 0 and %g", a.MaxAllowedScore())})
+				continue
+			}
+			change := ImportChange{
+				Row:             line,
+				Username:        username,
+				StudentID:       studentID,
+				AssignmentID:    a.ID,
+				AssignmentTitle: a.Title,
+				Action:          "create",
+				NewScore:        score,
+			}
+			if g, ok := existing[studentID][a.ID]; ok {
+				if g.Score == score {
+					preview.Unchanged++
+					continue
+				}
+				old := g.Score
+				change.Action = "update"
+				change.GradeID = g.ID
+				change.OldScore = &old
+				change.Released = a.GradesVisible(now)
+				change.Conflict = fmt.Sprintf("overwrites existing score %g", old)
+				change.feedback = g.Feedback
+				preview.Updates++
+				preview.Conflicts++
+			} else {
+				preview.Creates++
+			}
+			preview.Changes = append(preview.Changes, change)
+		}
+	}
+	return preview, nil
+}
+
+// ApplyImport — алдын ала қаралған өзгерістерді GradeWriter арқылы қолданады.
+// Overwrite болмаса, бар бағаны өзгертетін ұяшықтар өткізіледі.
+func (s *Service) ApplyImport(teacherID, courseID uint, filename string, data []byte, opts ImportOptions) (*ImportResult, error) {
+	if s.grades == nil {
+		return nil, errors.New("gradebook import is not configured")
+	}
+	preview, err := s.PreviewImport(courseID, filename, data)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Overwrite && strings.TrimSpace(opts.Reason) == "" {
+		for _, ch := range preview.Changes {
+			if ch.Action == "update" && ch.Released {
+				return nil, errors.New("reason is required to change released grades")
+			}
+		}
+	}
+	result := &ImportResult{Failed: []ImportIssue{}}
+	for _, ch := range preview.Changes {
+		var err error
+		switch {
+		case ch.Action == "create":
+			_, err = s.grades.CreateGrade(ch.AssignmentID, ch.StudentID, ch.NewScore, nil, "", teacherID)
+		case !opts.Overwrite:
+			result.Skipped++
+			continue
+		default:
+			_, err = s.grades.UpdateGrade(ch.GradeID, ch.NewScore, nil, ch.feedback, opts.Reason, teacherID)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, ImportIssue{
+				Row:      ch.Row,
+				Column:   ch.AssignmentTitle,
+				Username: ch.Username,
+				Message:  err.Error(),
+			})
+			continue
+		}
+		if ch.Action == "create" {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// enrolledStudents — курс студенттері: username (кіші әріппен) → id
+func (s *Service) enrolledStudents(courseID uint) (map[string]uint, error) {
+	var rows []struct {
+		ID       uint
+		Username string
+	}
+	if err := s.db.Table("users u").
+		Select("u.id, u.username").
+		Joins("JOIN course_students cs ON cs.user_id = u.id").
+		Where("cs.course_id = ? AND u.role = ?", courseID, models.RoleStudent).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	students := make(map[string]uint, len(rows))
+	for _, r := range rows {
+		students[strings.ToLower(r.Username)] = r.ID
+	}
+	return students, nil
+}
+
+// existingGrades — student_id → assignment_id → баға
+func (s *Service) existingGrades(assignments []models.Assignment) (map[uint]map[uint]models.Grade, error) {
+	out := map[uint]map[uint]models.Grade{}
+	if len(assignments) == 0 {
+		return out, nil
+	}
+	ids := make([]uint, 0, len(assignments))
+	for _, a := range assignments {
+		ids = append(ids, a.ID)
+	}
+	var grades []models.Grade
+	if err := s.db.Select("id", "student_id", "assignment_id", "score", "feedback").
+		Where("assignment_id IN ?", ids).
+		Find(&grades).Error; err != nil {
+		return nil, err
+	}
+	for _, g := range grades {
+		if out[g.StudentID] == nil {
+			out[g.StudentID] = map[uint]models.Grade{}
+		}
+		out[g.StudentID][g.AssignmentID] = g
+	}
+	return out, nil
+}
+
+// readTable — XLSX (zip) немесе CSV (үтір, нүктелі үтір не таб бөлгіш).
+func readTable(filename string, data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, []byte("PK")) || strings.HasSuffix(strings.ToLower(filename), ".xlsx") {
+		return readXLSX(data)
+	}
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	switch {
+	case bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")):
+		r.Comma = ';'
+	case bytes.Count(firstLine, []byte("\t")) > bytes.Count(firstLine, []byte(",")):
+		r.Comma = '\t'
+	}
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid csv file: " + err.Error())
+	}
+	// экспорт экранирует ячейки-формулы апострофом — снимаем его
+	for _, row := range rows {
+		for i := range row {
+			row[i] = utils.CSVUnescape(row[i])
+		}
+	}
+	return rows, nil
+}
+
+func cell(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
@@ -0,0 +1,499 @@
+package gradebook
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/analytics"
+	"rest-project/internal/services/gradescale"
+)
+
+// Service — курс журналы: санаттар мен салмақтар, босатулар, қорытынды пайыз.
+// Бағалар матрицасы analytics.Service.Heatmap деректерінен құрылады.
+type Service struct {
+	db        *gorm.DB
+	analytics *analytics.Service
+	scales    *gradescale.Service // optional
+	grades    GradeWriter         // optional — журнал импорты
+}
+
+func NewService(db *gorm.DB, an *analytics.Service) *Service {
+	return &Service{db: db, analytics: an}
+}
+
+// SetScales — қорытынды бағаны курс шкаласы бойынша көрсету (опционал).
+func (s *Service) SetScales(scales *gradescale.Service) {
+	s.scales = scales
+}
+
+// CategoryRequest — санат жасау/өзгерту
+type CategoryRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	Weight     float64 `json:"weight" binding:"required"`
+	DropLowest int     `json:"drop_lowest"`
+	Position   int     `json:"position"`
+}
+
+// Column — матрицадағы тапсырма бағаны
+type Column struct {
+	analytics.HeatmapAssignment
+	CategoryID     *uint     `json:"category_id,omitempty"`
+	DueDate        time.Time `json:"due_date"`
+	Published      bool      `json:"published"`       // черновик немесе publish_at келмеген тапсырма есепке кірмейді
+	GradesReleased bool      `json:"grades_released"` // бағалар студенттерге жарияланған
+}
+
+// Cell — студент × тапсырма ұяшығы
+type Cell struct {
+	analytics.HeatmapCell
+	LatePenalty float64             `json:"late_penalty,omitempty"`
+	Percent     *float64            `json:"percent,omitempty"` // (score - late_penalty) / max_score; HeatmapCell.Percent-ты ауыстырады
+	Scaled      *models.ScaledGrade `json:"scaled,omitempty"`
+	Excused     bool                `json:"excused,omitempty"`
+	Dropped     bool                `json:"dropped,omitempty"` // drop_lowest ережесімен шығарылды
+	Counted     bool                `json:"counted"`           // қорытындыға кірді
+}
+
+// CategoryScore — студенттің санат бойынша орташа пайызы
+type CategoryScore struct {
+	CategoryID *uint    `json:"category_id"` // nil — санаттар жоқ, барлық тапсырма бір топта
+	Name       string   `json:"name"`
+	Weight     float64  `json:"weight"`
+	Percent    *float64 `json:"percent"`
+	Counted    int      `json:"counted"`
+	Dropped    int      `json:"dropped"`
+}
+
+// Row — бір студенттің жолы
+type Row struct {
+	StudentID    uint                `json:"student_id"`
+	StudentName  string              `json:"student_name"`
+	Cells        []Cell              `json:"cells"`
+	Categories   []CategoryScore     `json:"categories"`
+	FinalPercent *float64            `json:"final_percent"`
+	FinalGrade   *models.ScaledGrade `json:"final_grade,omitempty"` // курс шкаласы бойынша
+}
+
+// Matrix — курстың толық журналы
+type Matrix struct {
+	CourseID     uint                         `json:"course_id"`
+	Categories   []models.GradebookCategory   `json:"categories"`
+	WeightsTotal float64                      `json:"weights_total"`
+	Assignments  []Column                     `json:"assignments"`
+	Rows         []Row                        `json:"rows"`
+	ClassAverage *float64                     `json:"class_average"`
+	Scale        *models.GradingScaleResponse `json:"scale,omitempty"`
+}
+
+// ── Санаттар ────────────────────────────────────────────────────────────────
+
+func (s *Service) ListCategories(courseID uint) ([]models.GradebookCategory, error) {
+	var categories []models.GradebookCategory
+	err := s.db.Where("course_id = ?", courseID).
+		Order("position ASC, id ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+func (s *Service) CreateCategory(courseID uint, req CategoryRequest) (*models.GradebookCategory, error) {
+	if err := validateCategory(&req); err != nil {
+		return nil, err
+	}
+	category := &models.GradebookCategory{
+		CourseID:   courseID,
+		Name:       req.Name,
+		Weight:     req.Weight,
+		DropLowest: req.DropLowest,
+		Position:   req.Position,
+	}
+	if err := s.db.Create(category).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, errors.New("category with this name already exists")
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *Service) UpdateCategory(courseID, categoryID uint, req CategoryRequest) (*models.GradebookCategory, error) {
+	if err := validateCategory(&req); err != nil {
+		return nil, err
+	}
+	category, err := s.getCategory(courseID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	category.Name = req.Name
+	category.Weight = req.Weight
+	category.DropLowest = req.DropLowest
+	category.Position = req.Position
+	if err := s.db.Model(category).
+		Select("name", "weight", "drop_lowest", "position", "updated_at").
+		Updates(category).Error; err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			return nil, errors.New("category with this name already exists")
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory — санатты өшіреді; оның тапсырмалары санатсыз қалады (ON DELETE SET NULL).
+func (s *Service) DeleteCategory(courseID, categoryID uint) error {
+	category, err := s.getCategory(courseID, categoryID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(category).Error
+}
+
+// SetAssignmentCategory — тапсырманы санатқа жатқызады; nil — санаттан шығарады.
+func (s *Service) SetAssignmentCategory(assignmentID uint, categoryID *uint) error {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, assignmentID).Error; err != nil {
+		return errors.New("assignment not found")
+	}
+	if categoryID != nil {
+		if _, err := s.getCategory(assignment.CourseID, *categoryID); err != nil {
+			return err
+		}
+	}
+	return s.db.Model(&models.Assignment{}).
+		Where("id = ?", assignmentID).
+		Update("gradebook_category_id", categoryID).Error
+}
+
+// ── Босатулар ───────────────────────────────────────────────────────────────
+
+// Excuse — студентті тапсырмадан босатады (қайта шақырылса себебі жаңарады).
+func (s *Service) Excuse(assignmentID, studentID, teacherID uint, reason string) (*models.GradebookExemption, error) {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, assignmentID).Error; err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	var enrolled int64
+	if err := s.db.Table("course_students").
+		Where("course_id = ? AND user_id = ?", assignment.CourseID, studentID).
+		Count(&enrolled).Error; err != nil {
+		return nil, err
+	}
+	if enrolled == 0 {
+		return nil, errors.New("student is not enrolled in the course")
+	}
+
+	exemption := &models.GradebookExemption{
+		AssignmentID: assignmentID,
+		StudentID:    studentID,
+		Reason:       strings.TrimSpace(reason),
+		CreatedBy:    teacherID,
+	}
+	err := s.db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Assign(map[string]interface{}{"reason": exemption.Reason, "created_by": teacherID}).
+		FirstOrCreate(exemption).Error
+	if err != nil {
+		return nil, err
+	}
+	return exemption, nil
+}
+
+func (s *Service) Unexcuse(assignmentID, studentID uint) error {
+	res := s.db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Delete(&models.GradebookExemption{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("exemption not found")
+	}
+	return nil
+}
+
+// ── Матрица ─────────────────────────────────────────────────────────────────
+
+// Matrix — студент × тапсырма журналы және әр студенттің қорытынды пайызы.
+//
+// Ұяшық пайызы = (score - late_penalty) / max_score. Мерзімі (ұзартуды ескергенде) өткен тапсырылмаған
+// жұмыс 0% болып есептеледі; тексерілмеген жұмыс, босатылған ұяшық және жарияланбаған
+// тапсырма есепке кірмейді.
+// Санат ішінде drop_lowest ең төмен пайыздар шығарылады (кемінде біреуі қалады),
+// қорытынды — бағасы бар санаттар бойынша салмақты орташа. Санаттар болса,
+// санатсыз тапсырмалар журналда көрсетіледі, бірақ қорытындыға кірмейді.
+func (s *Service) Matrix(teacherID, courseID uint) (*Matrix, error) {
+	return s.build(teacherID, courseID, false)
+}
+
+// build — forStudent кезінде жарияланбаған бағалар тапсырылған жұмыс ретінде көрсетіліп,
+// қорытындыға кірмейді. Мұғалімге анонимді тапсырмалардың баллдары аттар ашылғанша көрсетілмейді.
+func (s *Service) build(teacherID, courseID uint, forStudent bool) (*Matrix, error) {
+	heatmap := s.analytics.Heatmap
+	if forStudent {
+		heatmap = s.analytics.StudentHeatmap
+	}
+	hm, err := heatmap(teacherID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.ListCategories(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignmentIDs := make([]uint, 0, len(hm.Assignments))
+	for _, a := range hm.Assignments {
+		assignmentIDs = append(assignmentIDs, a.ID)
+	}
+	meta := map[uint]models.Assignment{}
+	excused := map[uint]map[uint]bool{}
+	extended := map[uint]map[uint]time.Time{}
+	penalties := map[uint]map[uint]float64{}
+	if len(assignmentIDs) > 0 {
+		var assignments []models.Assignment
+		if err := s.db.Select("id", "due_date", "gradebook_category_id", "is_draft", "publish_at",
+			"grade_release_mode", "grades_release_at", "grades_released_at").
+			Where("id IN ?", assignmentIDs).
+			Find(&assignments).Error; err != nil {
+			return nil, err
+		}
+		for _, a := range assignments {
+			meta[a.ID] = a
+		}
+
+		var exemptions []models.GradebookExemption
+		if err := s.db.Where("assignment_id IN ?", assignmentIDs).Find(&exemptions).Error; err != nil {
+			return nil, err
+		}
+		for _, e := range exemptions {
+			if excused[e.StudentID] == nil {
+				excused[e.StudentID] = map[uint]bool{}
+			}
+			excused[e.StudentID][e.AssignmentID] = true
+		}
+
+		var grades []models.Grade
+		if err := s.db.Select("student_id", "assignment_id", "late_penalty").
+			Where("assignment_id IN ? AND late_penalty > 0", assignmentIDs).
+			Find(&grades).Error; err != nil {
+			return nil, err
+		}
+		for _, g := range grades {
+			if penalties[g.StudentID] == nil {
+				penalties[g.StudentID] = map[uint]float64{}
+			}
+			penalties[g.StudentID][g.AssignmentID] = g.LatePenalty
+		}
+
+		var extensions []models.AssignmentExtension
+		if err := s.db.Where("assignment_id IN ?", assignmentIDs).Find(&extensions).Error; err != nil {
+			return nil, err
+		}
+		for _, e := range extensions {
+			if extended[e.StudentID] == nil {
+				extended[e.StudentID] = map[uint]time.Time{}
+			}
+			extended[e.StudentID][e.AssignmentID] = e.DueDate
+		}
+	}
+
+	// санат индексі: санаттар жоқ болса — барлық тапсырма бір топта (салмағы 100)
+	type bucket struct {
+		id         *uint
+		name       string
+		weight     float64
+		dropLowest int
+	}
+	var buckets []bucket
+	bucketOf := map[uint]int{} // category_id → buckets индексі
+	if len(categories) == 0 {
+		buckets = append(buckets, bucket{name: "all", weight: 100})
+	}
+	weightsTotal := 0.0
+	for _, c := range categories {
+		id := c.ID
+		bucketOf[c.ID] = len(buckets)
+		buckets = append(buckets, bucket{id: &id, name: c.Name, weight: c.Weight, dropLowest: c.DropLowest})
+		weightsTotal += c.Weight
+	}
+	columnBucket := make([]int, len(hm.Assignments)) // -1 — қорытындыға кірмейді
+
+	var scale *models.GradingScaleResponse
+	if s.scales != nil {
+		if scale, err = s.scales.ForCourse(courseID); err != nil {
+			return nil, err
+		}
+	}
+
+	matrix := &Matrix{
+		Scale:        scale,
+		CourseID:     courseID,
+		Categories:   categories,
+		WeightsTotal: round2(weightsTotal),
+		Assignments:  make([]Column, 0, len(hm.Assignments)),
+		Rows:         make([]Row, 0, len(hm.Rows)),
+	}
+	if matrix.Categories == nil {
+		matrix.Categories = []models.GradebookCategory{}
+	}
+	now := time.Now()
+	for i, a := range hm.Assignments {
+		m := meta[a.ID]
+		matrix.Assignments = append(matrix.Assignments, Column{
+			HeatmapAssignment: a,
+			CategoryID:        m.GradebookCategoryID,
+			DueDate:           m.DueDate,
+			Published:         m.IsVisibleToStudents(now),
+			GradesReleased:    m.GradesVisible(now),
+		})
+		switch {
+		case len(categories) == 0:
+			columnBucket[i] = 0
+		case m.GradebookCategoryID != nil:
+			if idx, ok := bucketOf[*m.GradebookCategoryID]; ok {
+				columnBucket[i] = idx
+			} else {
+				columnBucket[i] = -1
+			}
+		default:
+			columnBucket[i] = -1
+		}
+	}
+
+	var finalSum float64
+	var finalCount int
+	for _, hr := range hm.Rows {
+		row := Row{StudentID: hr.StudentID, StudentName: hr.StudentName, Cells: make([]Cell, 0, len(hr.Cells))}
+		members := make([][]int, len(buckets)) // санат → қорытындыға кіретін ұяшықтар
+		for i, hc := range hr.Cells {
+			if forStudent && hc.Status == "graded" && !matrix.Assignments[i].GradesReleased {
+				hc.Score, hc.Percent, hc.Status = 0, 0, models.SubmissionStatusSubmitted
+			}
+			cell := Cell{HeatmapCell: hc}
+			dueDate := meta[hc.AssignmentID].DueDate
+			if ext, ok := extended[hr.StudentID][hc.AssignmentID]; ok && ext.After(dueDate) {
+				dueDate = ext
+			}
+			switch {
+			case excused[hr.StudentID][hc.AssignmentID]:
+				cell.Excused = true
+			case hc.MaxScore <= 0 || !matrix.Assignments[i].Published:
+			case hc.Status == "graded" && matrix.Assignments[i].ScoresHidden:
+				// анонимді тексеру: балл да, айыппұл да аттар ашылғанша қорытындыға кірмейді
+			case hc.Status == "graded":
+				cell.LatePenalty = penalties[hr.StudentID][hc.AssignmentID]
+				cell.Percent = floatPtr(round2(math.Max(hc.Score-cell.LatePenalty, 0) / hc.MaxScore * 100))
+				if scale != nil {
+					cell.Scaled = scale.Apply(*cell.Percent)
+				}
+			case (hc.Status == "missing" || hc.Status == string(models.SubmissionStatusDraft)) && now.After(dueDate):
+				cell.Percent = floatPtr(0)
+			}
+			if cell.Percent != nil && columnBucket[i] >= 0 {
+				cell.Counted = true
+				members[columnBucket[i]] = append(members[columnBucket[i]], i)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+
+		var weighted, weights float64
+		for b, bk := range buckets {
+			score := CategoryScore{CategoryID: bk.id, Name: bk.name, Weight: bk.weight}
+			cells := members[b]
+			if drop := min(bk.dropLowest, len(cells)-1); drop > 0 {
+				sort.SliceStable(cells, func(x, y int) bool {
+					return *row.Cells[cells[x]].Percent < *row.Cells[cells[y]].Percent
+				})
+				for _, idx := range cells[:drop] {
+					row.Cells[idx].Dropped = true
+					row.Cells[idx].Counted = false
+				}
+				score.Dropped = drop
+				cells = cells[drop:]
+			}
+			if len(cells) > 0 {
+				sum := 0.0
+				for _, idx := range cells {
+					sum += *row.Cells[idx].Percent
+				}
+				avg := sum / float64(len(cells))
+				score.Percent = floatPtr(round2(avg))
+				score.Counted = len(cells)
+				weighted += avg * bk.weight
+				weights += bk.weight
+			}
+			row.Categories = append(row.Categories, score)
+		}
+		if weights > 0 {
+			row.FinalPercent = floatPtr(round2(weighted / weights))
+			if scale != nil {
+				row.FinalGrade = scale.Apply(*row.FinalPercent)
+			}
+			finalSum += *row.FinalPercent
+			finalCount++
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	if finalCount > 0 {
+		matrix.ClassAverage = floatPtr(round2(finalSum / float64(finalCount)))
+	}
+	return matrix, nil
+}
+
+// StudentRow — студенттің өз журнал жолы (курс мұғалімінің матрицасынан).
+func (s *Service) StudentRow(courseID, studentID uint) (*Row, *models.GradingScaleResponse, error) {
+	var course models.Course
+	if err := s.db.Select("id", "teacher_id").First(&course, courseID).Error; err != nil {
+		return nil, nil, errors.New("course not found")
+	}
+	matrix, err := s.build(course.TeacherID, courseID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range matrix.Rows {
+		if matrix.Rows[i].StudentID != studentID {
+			continue
+		}
+		row := matrix.Rows[i]
+		cells := make([]Cell, 0, len(row.Cells))
+		for j, cell := range row.Cells {
+			if matrix.Assignments[j].Published {
+				cells = append(cells, cell)
+			}
+		}
+		row.Cells = cells
+		return &row, matrix.Scale, nil
+	}
+	return nil, nil, errors.New("student is not enrolled in the course")
+}
+
+func (s *Service) getCategory(courseID, categoryID uint) (*models.GradebookCategory, error) {
+	var category models.GradebookCategory
+	if err := s.db.First(&category, "id = ? AND course_id = ?", categoryID, courseID).Error; err != nil {
+		return nil, errors.New("category not found")
+	}
+	return &category, nil
+}
+
+func validateCategory(req *CategoryRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		return errors.New("category name must be 1-100 characters")
+	}
+	if req.Weight <= 0 || req.Weight > 100 {
+		return errors.New("weight must be between 0 and 100")
+	}
+	if req.DropLowest < 0 {
+		return errors.New("drop_lowest must not be negative")
+	}
+	return nil
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
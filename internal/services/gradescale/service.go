@@ -0,0 +1,273 @@
+package gradescale
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+// maxLevels — шкаладағы деңгейлердің жоғарғы шегі
+const maxLevels = 20
+
+// Service — бағалау шкалалары: ұйым және мұғалім шкалалары, курсқа тағайындау,
+// бағаларды пайыздан символға айналдыру.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ScaleRequest — шкала жасау/өзгерту
+type ScaleRequest struct {
+	Name   string              `json:"name" binding:"required"`
+	Kind   string              `json:"kind"`
+	Levels []models.ScaleLevel `json:"levels" binding:"required"`
+}
+
+// List — ұйым шкалалары және (ownerID > 0 болса) мұғалімнің өз шкалалары.
+func (s *Service) List(ownerID uint) ([]models.GradingScaleResponse, error) {
+	q := s.db.Where("owner_id IS NULL")
+	if ownerID > 0 {
+		q = s.db.Where("owner_id IS NULL OR owner_id = ?", ownerID)
+	}
+	var scales []models.GradingScale
+	if err := q.Order("owner_id NULLS FIRST, id ASC").Find(&scales).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.GradingScaleResponse, 0, len(scales))
+	for i := range scales {
+		out = append(out, toResponse(&scales[i]))
+	}
+	return out, nil
+}
+
+// Create — ownerID nil болса ұйым шкаласы жасалады.
+func (s *Service) Create(ownerID *uint, req ScaleRequest) (*models.GradingScaleResponse, error) {
+	levels, err := validate(&req)
+	if err != nil {
+		return nil, err
+	}
+	scale := &models.GradingScale{
+		Name:    req.Name,
+		Kind:    req.Kind,
+		OwnerID: ownerID,
+		Levels:  levels,
+	}
+	if err := s.db.Create(scale).Error; err != nil {
+		return nil, err
+	}
+	resp := toResponse(scale)
+	return &resp, nil
+}
+
+// Update — тек иесі өзгерте алады (ұйым шкаласын — әкімші).
+func (s *Service) Update(id uint, ownerID *uint, req ScaleRequest) (*models.GradingScaleResponse, error) {
+	scale, err := s.getOwned(id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := validate(&req)
+	if err != nil {
+		return nil, err
+	}
+	scale.Name = req.Name
+	scale.Kind = req.Kind
+	scale.Levels = levels
+	if err := s.db.Model(scale).
+		Select("name", "kind", "levels", "updated_at").
+		Updates(scale).Error; err != nil {
+		return nil, err
+	}
+	resp := toResponse(scale)
+	return &resp, nil
+}
+
+// Delete — шкаланы өшіреді; оны қолданған курстар шкаласыз қалады (ON DELETE SET NULL).
+func (s *Service) Delete(id uint, ownerID *uint) error {
+	scale, err := s.getOwned(id, ownerID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(scale).Error
+}
+
+// SetCourseScale — курсқа шкала тағайындайды; nil — шкаланы алып тастайды.
+// Мұғалім ұйым шкаласын немесе өз шкаласын ғана таңдай алады.
+func (s *Service) SetCourseScale(courseID, teacherID uint, scaleID *uint) error {
+	if scaleID != nil {
+		var scale models.GradingScale
+		if err := s.db.First(&scale, *scaleID).Error; err != nil {
+			return errors.New("grading scale not found")
+		}
+		if scale.OwnerID != nil && *scale.OwnerID != teacherID {
+			return errors.New("grading scale not found")
+		}
+	}
+	return s.db.Model(&models.Course{}).
+		Where("id = ?", courseID).
+		Update("grading_scale_id", scaleID).Error
+}
+
+// ForCourse — курс шкаласы; шкала тағайындалмаса nil.
+func (s *Service) ForCourse(courseID uint) (*models.GradingScaleResponse, error) {
+	var course models.Course
+	if err := s.db.Select("id", "grading_scale_id").First(&course, courseID).Error; err != nil {
+		return nil, err
+	}
+	if course.GradingScaleID == nil {
+		return nil, nil
+	}
+	var scale models.GradingScale
+	if err := s.db.First(&scale, *course.GradingScaleID).Error; err != nil {
+		return nil, err
+	}
+	resp := toResponse(&scale)
+	return &resp, nil
+}
+
+// ApplyToGrades — әр бағаға курс шкаласы бойынша символ қояды (Grade.Scaled).
+// Пайыз = (score - late_penalty) / max_score; шкаласыз курстардың бағалары өзгермейді.
+func (s *Service) ApplyToGrades(grades []models.Grade) {
+	if len(grades) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(grades))
+	for _, g := range grades {
+		ids = append(ids, g.AssignmentID)
+	}
+	var rows []struct {
+		ID       uint    `gorm:"column:id"`
+		CourseID uint    `gorm:"column:course_id"`
+		MaxScore float64 `gorm:"column:max_score"`
+	}
+	if err := s.db.Table("assignments").
+		Select("id, course_id, max_score").
+		Where("id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return
+	}
+	type meta struct {
+		maxScore float64
+		scale    *models.GradingScaleResponse
+	}
+	scales := map[uint]*models.GradingScaleResponse{}
+	assignments := map[uint]meta{}
+	for _, r := range rows {
+		scale, ok := scales[r.CourseID]
+		if !ok {
+			scale, _ = s.ForCourse(r.CourseID)
+			scales[r.CourseID] = scale
+		}
+		assignments[r.ID] = meta{maxScore: r.MaxScore, scale: scale}
+	}
+	for i := range grades {
+		m, ok := assignments[grades[i].AssignmentID]
+		if !ok || m.scale == nil || m.maxScore <= 0 {
+			continue
+		}
+		grades[i].Scaled = m.scale.Apply(Percent(grades[i].Score, grades[i].LatePenalty, m.maxScore))
+	}
+}
+
+// Percent — штрафты ескергендегі пайыз, екі таңбаға дейін дөңгелектенген.
+func Percent(score, latePenalty, maxScore float64) float64 {
+	if maxScore <= 0 {
+		return 0
+	}
+	return math.Round(math.Max(score-latePenalty, 0)/maxScore*10000) / 100
+}
+
+func (s *Service) getOwned(id uint, ownerID *uint) (*models.GradingScale, error) {
+	var scale models.GradingScale
+	if err := s.db.First(&scale, id).Error; err != nil {
+		return nil, errors.New("grading scale not found")
+	}
+	switch {
+	case ownerID == nil && scale.OwnerID == nil:
+	case ownerID != nil && scale.OwnerID != nil && *ownerID == *scale.OwnerID:
+	default:
+		return nil, errors.New("forbidden: grading scale belongs to another owner")
+	}
+	return &scale, nil
+}
+
+// validate — деңгейлерді тексеріп, JSON түрінде қайтарады.
+func validate(req *ScaleRequest) (string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len([]rune(req.Name)) > 100 {
+		return "", errors.New("scale name must be 1-100 characters")
+	}
+	if req.Kind == "" {
+		req.Kind = models.GradingScaleCustom
+	}
+	switch req.Kind {
+	case models.GradingScaleLetter, models.GradingScaleFivePoint, models.GradingScalePassFail, models.GradingScaleCustom:
+	default:
+		return "", errors.New("kind must be letter, five_point, pass_fail or custom")
+	}
+	if len(req.Levels) == 0 || len(req.Levels) > maxLevels {
+		return "", errors.New("scale must have 1-20 levels")
+	}
+
+	symbols := map[string]bool{}
+	thresholds := map[float64]bool{}
+	hasZero := false
+	for i := range req.Levels {
+		level := &req.Levels[i]
+		level.Symbol = strings.TrimSpace(level.Symbol)
+		if level.Symbol == "" || len([]rune(level.Symbol)) > 20 {
+			return "", errors.New("level symbol must be 1-20 characters")
+		}
+		if symbols[level.Symbol] {
+			return "", errors.New("duplicate level symbol: " + level.Symbol)
+		}
+		symbols[level.Symbol] = true
+		if level.MinPercent < 0 || level.MinPercent > 100 {
+			return "", errors.New("min_percent must be between 0 and 100")
+		}
+		if thresholds[level.MinPercent] {
+			return "", errors.New("duplicate min_percent in levels")
+		}
+		thresholds[level.MinPercent] = true
+		if level.MinPercent == 0 {
+			hasZero = true
+		}
+	}
+	// әр пайыз қандай да бір деңгейге түсуі керек
+	if !hasZero {
+		return "", errors.New("scale must have a level with min_percent 0")
+	}
+	if req.Kind == models.GradingScalePassFail && len(req.Levels) != 2 {
+		return "", errors.New("pass_fail scale must have exactly 2 levels")
+	}
+
+	models.SortScaleLevels(req.Levels)
+	data, err := json.Marshal(req.Levels)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func toResponse(scale *models.GradingScale) models.GradingScaleResponse {
+	var levels []models.ScaleLevel
+	_ = json.Unmarshal([]byte(scale.Levels), &levels)
+	models.SortScaleLevels(levels)
+	return models.GradingScaleResponse{
+		ID:        scale.ID,
+		Name:      scale.Name,
+		Kind:      scale.Kind,
+		OwnerID:   scale.OwnerID,
+		IsOrg:     scale.OwnerID == nil,
+		Levels:    levels,
+		CreatedAt: scale.CreatedAt,
+		UpdatedAt: scale.UpdatedAt,
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/repository"
+)
+
+type AssignmentGroupService struct {
+	repo           repository.AssignmentGroupRepository
+	assignmentRepo repository.AssignmentRepository
+	courseRepo     repository.CourseRepository
+	submissionRepo repository.AssignmentSubmissionRepository
+}
+
+func NewAssignmentGroupService(
+	groupRepo repository.AssignmentGroupRepository,
+	assignmentRepo repository.AssignmentRepository,
+	courseRepo repository.CourseRepository,
+	submissionRepo repository.AssignmentSubmissionRepository,
+) *AssignmentGroupService {
+	return &AssignmentGroupService{
+		repo:           groupRepo,
+		assignmentRepo: assignmentRepo,
+		courseRepo:     courseRepo,
+		submissionRepo: submissionRepo,
+	}
+}
+
+// GroupRequest — топ құру/өзгерту сұранысы
+type GroupRequest struct {
+	Name       string `json:"name"`
+	StudentIDs []uint `json:"student_ids"`
+}
+
+// normalizeGroupMode тексереді және әдепкі мәндерді қояды.
+func normalizeGroupMode(req *CreateAssignmentRequest) error {
+	switch req.GroupMode {
+	case "":
+		req.GroupMode = models.GroupModeNone
+	case models.GroupModeNone, models.GroupModeTeacher, models.GroupModeSelfSignup:
+	default:
+		return errors.New("group_mode must be one of: none, teacher, self_signup")
+	}
+	if req.MaxGroupSize < 0 {
+		return errors.New("max_group_size cannot be negative")
+	}
+	if req.GroupMode != models.GroupModeNone && req.Type == string(models.AssignmentTypeTest) {
+		return errors.New("tests cannot be group assignments")
+	}
+	if req.GroupMode == models.GroupModeNone {
+		req.MaxGroupSize = 0
+	}
+	return nil
+}
+
+// ListGroups — мұғалімге тапсырманың барлық топтары
+func (s *AssignmentGroupService) ListGroups(assignmentID, teacherID uint) ([]models.AssignmentGroupResponse, error) {
+	assignment, err := s.getOwnedGroupAssignment(assignmentID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listGroups(assignment)
+}
+
+// CreateGroup — мұғалім топ құрады (self_signup режимінде бос топ та болады)
+func (s *AssignmentGroupService) CreateGroup(assignmentID uint, req GroupRequest, teacherID uint) (*models.AssignmentGroupResponse, error) {
+	assignment, err := s.getOwnedGroupAssignment(assignmentID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, errors.New("group name is required")
+	}
+	if err := s.validateMembers(assignment, 0, req.StudentIDs); err != nil {
+		return nil, err
+	}
+
+	group := &models.AssignmentGroup{AssignmentID: assignment.ID, Name: req.Name}
+	if err := s.repo.Create(group); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceMembers(group, req.StudentIDs); err != nil {
+		return nil, err
+	}
+	return s.getGroupResponse(assignment, group.ID)
+}
+
+// UpdateGroup — атауын және құрамын толық ауыстырады
+func (s *AssignmentGroupService) UpdateGroup(assignmentID, groupID uint, req GroupRequest, teacherID uint) (*models.AssignmentGroupResponse, error) {
+	assignment, err := s.getOwnedGroupAssignment(assignmentID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.getGroupOf(assignment, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		group.Name = name
+		if err := s.repo.Update(group.ID, group); err != nil {
+			return nil, err
+		}
+	}
+	if req.StudentIDs != nil {
+		if err := s.validateMembers(assignment, group.ID, req.StudentIDs); err != nil {
+			return nil, err
+		}
+		if err := s.repo.ReplaceMembers(group, req.StudentIDs); err != nil {
+			return nil, err
+		}
+	}
+	return s.getGroupResponse(assignment, group.ID)
+}
+
+// DeleteGroup — жұмыс тапсырмаған топты өшіреді
+func (s *AssignmentGroupService) DeleteGroup(assignmentID, groupID, teacherID uint) error {
+	assignment, err := s.getOwnedGroupAssignment(assignmentID, teacherID)
+	if err != nil {
+		return err
+	}
+	group, err := s.getGroupOf(assignment, groupID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.submissionRepo.GetByGroupAndAssignment(group.ID, assignment.ID); err == nil {
+		return errors.New("group already has a submission")
+	}
+	return s.repo.Delete(group.ID)
+}
+
+// ListGroupsForStudent — студентке топтар тізімі (қосылу үшін)
+func (s *AssignmentGroupService) ListGroupsForStudent(assignmentID, studentID uint) ([]models.AssignmentGroupResponse, error) {
+	assignment, err := s.getStudentGroupAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listGroups(assignment)
+}
+
+// GetStudentGroup — студенттің осы тапсырмадағы тобы
+func (s *AssignmentGroupService) GetStudentGroup(assignmentID, studentID uint) (*models.AssignmentGroupResponse, error) {
+	assignment, err := s.getStudentGroupAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.repo.GetByStudentAndAssignment(studentID, assignment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("student is not in a group")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(group, assignment), nil
+}
+
+// JoinGroup — self_signup режимінде студент топқа қосылады
+func (s *AssignmentGroupService) JoinGroup(assignmentID, groupID, studentID uint) (*models.AssignmentGroupResponse, error) {
+	assignment, err := s.getStudentGroupAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.GroupMode != models.GroupModeSelfSignup {
+		return nil, errors.New("groups for this assignment are assigned by the teacher")
+	}
+	if time.Now().After(assignment.DueDate) {
+		return nil, errors.New("group signup is closed")
+	}
+	if _, err := s.repo.GetByStudentAndAssignment(studentID, assignment.ID); err == nil {
+		return nil, errors.New("student is already in a group")
+	}
+	group, err := s.getGroupOf(assignment, groupID)
+	if err != nil {
+		return nil, err
+	}
+	// Толық па — топ жолы бұғатталған транзакция ішінде тексеріледі
+	member := &models.AssignmentGroupMember{
+		GroupID:      group.ID,
+		StudentID:    studentID,
+		AssignmentID: assignment.ID,
+	}
+	if err := s.repo.AddMemberLimited(member, assignment.MaxGroupSize); err != nil {
+		return nil, err
+	}
+	return s.getGroupResponse(assignment, group.ID)
+}
+
+// LeaveGroup — self_signup режимінде, жұмыс тапсырылмаған болса ғана
+func (s *AssignmentGroupService) LeaveGroup(assignmentID, studentID uint) error {
+	assignment, err := s.getStudentGroupAssignment(assignmentID, studentID)
+	if err != nil {
+		return err
+	}
+	if assignment.GroupMode != models.GroupModeSelfSignup {
+		return errors.New("groups for this assignment are assigned by the teacher")
+	}
+	group, err := s.repo.GetByStudentAndAssignment(studentID, assignment.ID)
+	if err != nil {
+		return errors.New("student is not in a group")
+	}
+	if submission, err := s.submissionRepo.GetByGroupAndAssignment(group.ID, assignment.ID); err == nil &&
+		submission.Status != models.SubmissionStatusDraft {
+		return errors.New("group has already submitted")
+	}
+	return s.repo.RemoveMember(group.ID, studentID)
+}
+
+func (s *AssignmentGroupService) listGroups(assignment *models.Assignment) ([]models.AssignmentGroupResponse, error) {
+	groups, err := s.repo.GetByAssignmentID(assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.AssignmentGroupResponse, 0, len(groups))
+	for i := range groups {
+		result = append(result, *toGroupResponse(&groups[i], assignment))
+	}
+	return result, nil
+}
+
+func (s *AssignmentGroupService) getGroupResponse(assignment *models.Assignment, groupID uint) (*models.AssignmentGroupResponse, error) {
+	group, err := s.repo.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(group, assignment), nil
+}
+
+func (s *AssignmentGroupService) getGroupOf(assignment *models.Assignment, groupID uint) (*models.AssignmentGroup, error) {
+	group, err := s.repo.GetByID(groupID)
+	if err != nil || group.AssignmentID != assignment.ID {
+		return nil, errors.New("group not found")
+	}
+	return group, nil
+}
+
+// validateMembers — студенттер курсқа жазылған және басқа топта емес болуы керек
+func (s *AssignmentGroupService) validateMembers(assignment *models.Assignment, groupID uint, studentIDs []uint) error {
+	if assignment.MaxGroupSize > 0 && len(studentIDs) > assignment.MaxGroupSize {
+		return errors.New("group exceeds max_group_size")
+	}
+	course, err := s.courseRepo.GetByIDWithDetails(assignment.CourseID)
+	if err != nil {
+		return errors.New("course not found")
+	}
+	enrolled := make(map[uint]bool, len(course.Students))
+	for _, student := range course.Students {
+		enrolled[student.ID] = true
+	}
+
+	seen := make(map[uint]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		if seen[id] {
+			return errors.New("duplicate student in group")
+		}
+		seen[id] = true
+		if !enrolled[id] {
+			return errors.New("student is not enrolled in this course")
+		}
+		if other, err := s.repo.GetByStudentAndAssignment(id, assignment.ID); err == nil && other.ID != groupID {
+			return errors.New("student is already in another group")
+		}
+	}
+	return nil
+}
+
+func (s *AssignmentGroupService) getOwnedGroupAssignment(assignmentID, teacherID uint) (*models.Assignment, error) {
+	assignment, err := s.assignmentRepo.GetByID(assignmentID)
+	if err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	course, err := s.courseRepo.GetByID(assignment.CourseID)
+	if err != nil {
+		return nil, errors.New("course not found")
+	}
+	if course.TeacherID != teacherID {
+		return nil, errors.New("teacher is not assigned to this course")
+	}
+	if !assignment.IsGroupAssignment() {
+		return nil, errors.New("assignment is not a group assignment")
+	}
+	return assignment, nil
+}
+
+func (s *AssignmentGroupService) getStudentGroupAssignment(assignmentID, studentID uint) (*models.Assignment, error) {
+	assignment, err := s.assignmentRepo.GetByID(assignmentID)
+	if err != nil || !assignment.IsVisibleToStudents(time.Now()) {
+		return nil, errors.New("assignment not found")
+	}
+	if !assignment.IsGroupAssignment() {
+		return nil, errors.New("assignment is not a group assignment")
+	}
+	courses, err := s.courseRepo.GetCoursesByStudentID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, course := range courses {
+		if course.ID == assignment.CourseID {
+			return assignment, nil
+		}
+	}
+	return nil, errors.New("student is not enrolled in this course")
+}
+
+func toGroupResponse(group *models.AssignmentGroup, assignment *models.Assignment) *models.AssignmentGroupResponse {
+	members := make([]models.GroupMemberResponse, 0, len(group.Members))
+	for _, m := range group.Members {
+		member := models.GroupMemberResponse{StudentID: m.StudentID}
+		if m.Student != nil {
+			member.Username = m.Student.Username
+		}
+		members = append(members, member)
+	}
+	return &models.AssignmentGroupResponse{
+		ID:           group.ID,
+		AssignmentID: group.AssignmentID,
+		Name:         group.Name,
+		Members:      members,
+		Size:         len(members),
+		MaxSize:      assignment.MaxGroupSize,
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"rest-project/internal/models"
+)
+
+// normalizeLatePolicy тексереді және әдепкі мәндерді қояды.
+func normalizeLatePolicy(req *CreateAssignmentRequest) error {
+	switch req.LatePolicy {
+	case "":
+		req.LatePolicy = models.LatePolicyNone
+	case models.LatePolicyNone, models.LatePolicyPercentPerDay, models.LatePolicyFixed:
+	default:
+		return errors.New("late_policy must be one of: none, percent_per_day, fixed")
+	}
+	if req.LatePenalty < 0 {
+		return errors.New("late_penalty cannot be negative")
+	}
+	if req.LatePolicy == models.LatePolicyPercentPerDay && req.LatePenalty > 100 {
+		return errors.New("late_penalty percent cannot exceed 100")
+	}
+	if req.LatePolicy == models.LatePolicyNone {
+		req.LatePenalty = 0
+	}
+	if req.CloseDate != nil && req.CloseDate.Before(req.DueDate) {
+		return errors.New("close_date must be after due_date")
+	}
+	return nil
+}
+
+// effectiveDueDate — студенттің нақты deadline-ы (ұзарту болса, сол).
+func effectiveDueDate(assignment *models.Assignment, extension *models.AssignmentExtension) time.Time {
+	if extension != nil && extension.DueDate.After(assignment.DueDate) {
+		return extension.DueDate
+	}
+	return assignment.DueDate
+}
+
+// submissionDueDate — қайтарылған жұмыс үшін мұғалім берген жаңа мерзім,
+// әйтпесе студенттің нақты deadline-ы.
+func submissionDueDate(assignment *models.Assignment, extension *models.AssignmentExtension, submission *models.AssignmentSubmission) time.Time {
+	if submission != nil && submission.ReturnDueDate != nil {
+		return *submission.ReturnDueDate
+	}
+	return effectiveDueDate(assignment, extension)
+}
+
+// isSubmissionClosed — close_date өтсе тапсыру жабылады.
+// Ұзарту close_date-тен кейін болса, студент ұзарту мерзіміне дейін тапсыра алады.
+func isSubmissionClosed(assignment *models.Assignment, extension *models.AssignmentExtension, now time.Time) bool {
+	if assignment.CloseDate == nil {
+		return false
+	}
+	closeAt := *assignment.CloseDate
+	if due := effectiveDueDate(assignment, extension); due.After(closeAt) {
+		closeAt = due
+	}
+	return now.After(closeAt)
+}
+
+// calcLatePenalty — кешігу айыппұлы (баллмен), score-дан аспайды.
+func calcLatePenalty(assignment *models.Assignment, dueDate, submittedAt time.Time, score float64) float64 {
+	if !submittedAt.After(dueDate) || score <= 0 {
+		return 0
+	}
+
+	penalty := 0.0
+	switch assignment.LatePolicy {
+	case models.LatePolicyPercentPerDay:
+		days := math.Ceil(submittedAt.Sub(dueDate).Hours() / 24)
+		percent := math.Min(days*assignment.LatePenalty, 100)
+		penalty = score * percent / 100
+	case models.LatePolicyFixed:
+		penalty = assignment.LatePenalty
+	}
+
+	if penalty > score {
+		penalty = score
+	}
+	return math.Round(penalty*100) / 100
+}
@@ -45,6 +45,49 @@ var (
 			Help: "Текущее число WebSocket-клиентов.",
 		},
 	)
+	AIRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_requests_total",
+			Help: "Количество попыток запросов к LLM (status: ok, error, rejected).",
+		},
+		[]string{"provider", "model", "status"},
+	)
+	AIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ai_request_duration_seconds",
+			Help:    "Длительность одной попытки запроса к LLM.",
+			Buckets: []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 60, 120},
+		},
+		[]string{"provider", "model"},
+	)
+	AIRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_retries_total",
+			Help: "Количество повторных попыток запросов к LLM.",
+		},
+		[]string{"provider", "model"},
+	)
+	AIFallbacksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_model_fallbacks_total",
+			Help: "Переходы на резервную модель (model — модель, которая не ответила).",
+		},
+		[]string{"provider", "model"},
+	)
+	AICircuitOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ai_circuit_open",
+			Help: "1 — circuit breaker модели разомкнут, запросы отклоняются сразу.",
+		},
+		[]string{"provider", "model"},
+	)
+	AIStructuredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ai_structured_output_total",
+			Help: "Структурированные ответы LLM по задачам (result: ok, repaired, failed).",
+		},
+		[]string{"task", "result"},
+	)
 )
 
 func Register() {
@@ -54,6 +97,12 @@ func Register() {
 		JobsEnqueuedTotal,
 		JobsProcessedTotal,
 		WSConnectedClients,
+		AIRequestsTotal,
+		AIRequestDuration,
+		AIRetriesTotal,
+		AIFallbacksTotal,
+		AICircuitOpen,
+		AIStructuredTotal,
 	)
 }
 
@@ -0,0 +1,600 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/notifier"
+)
+
+// DefaultThreshold — бағалар айырмасының әдепкі шегі (max_score-тың пайызы)
+const DefaultThreshold = 10
+
+// maxTextLen — түсініктеме мен модератор жазбасының шегі (символ)
+const maxTextLen = 4000
+
+// GradeFinalizer — қорытынды бағаны services.GradeService арқылы жазады (тексерулер мен тарих сақталады).
+type GradeFinalizer interface {
+	SetModeratedGrade(assignmentID, studentID uint, score float64, feedback, reason string, teacherID uint) (*models.Grade, error)
+}
+
+// Service — қос тексеру: екі тексеруші бір-бірінің бағасын көрмей бағалайды, айырма шектен
+// аспаса орташа балл қойылады, асса — курс мұғалімі (модератор) қорытынды бағаны шешеді.
+type Service struct {
+	db     *gorm.DB
+	hub    *notifier.Hub // optional
+	grades GradeFinalizer
+	anon   *anonymize.Service
+}
+
+func NewService(db *gorm.DB, hub *notifier.Hub, grades GradeFinalizer) *Service {
+	return &Service{db: db, hub: hub, grades: grades, anon: anonymize.NewService(db)}
+}
+
+// SettingsRequest — мұғалімнің баптаулары
+type SettingsRequest struct {
+	Enabled        *bool    `json:"enabled"`
+	FirstMarkerID  *uint    `json:"first_marker_id"` // nil — курс мұғалімі
+	SecondMarkerID uint     `json:"second_marker_id" binding:"required"`
+	Threshold      *float64 `json:"threshold"`
+}
+
+// MarkRequest — тексерушінің бағасы
+type MarkRequest struct {
+	SubmissionID uint    `json:"submission_id" binding:"required"`
+	Score        float64 `json:"score"`
+	Feedback     string  `json:"feedback"`
+}
+
+// ResolveRequest — модератордың шешімі; feedback бос болса бірінші тексерушінікі алынады
+type ResolveRequest struct {
+	Score    *float64 `json:"score" binding:"required"`
+	Feedback *string  `json:"feedback"`
+	Note     string   `json:"note"`
+}
+
+// WorkItem — тексерушінің тізіміндегі жұмыс. Екінші бағаны тексеруші көрмейді.
+type WorkItem struct {
+	SubmissionID uint       `json:"submission_id"`
+	StudentName  string     `json:"student_name"`
+	Content      string     `json:"content"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+	Status       string     `json:"status"`
+	MyScore      *float64   `json:"my_score,omitempty"`
+	MyFeedback   string     `json:"my_feedback,omitempty"`
+}
+
+// MarkResult — сақталған баға және іс күйі (басқа тексерушінің бағасынсыз)
+type MarkResult struct {
+	Mark   models.ModerationMark `json:"mark"`
+	Status string                `json:"status"`
+}
+
+func (s *Service) GetSettings(assignmentID uint) (*models.ModerationSettings, error) {
+	var settings models.ModerationSettings
+	if err := s.db.First(&settings, "assignment_id = ?", assignmentID).Error; err != nil {
+		return nil, errors.New("double marking is not configured")
+	}
+	return &settings, nil
+}
+
+// DoubleMarked — тапсырмада қос тексеру қосулы ма.
+// services.ModerationChecker интерфейсін іске асырады.
+func (s *Service) DoubleMarked(assignmentID uint) bool {
+	var enabled bool
+	s.db.Table("moderation_settings").
+		Select("enabled").
+		Where("assignment_id = ?", assignmentID).
+		Scan(&enabled)
+	return enabled
+}
+
+// Configure — баптауларды сақтайды (upsert). Бағалары бар тапсырмада қос тексеруді қосуға болмайды.
+func (s *Service) Configure(assignmentID uint, req SettingsRequest) (*models.ModerationSettings, error) {
+	assignment, teacherID, err := s.loadAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.IsGroupAssignment() {
+		return nil, errors.New("double marking is not available for group assignments")
+	}
+	// code/test бағаны жүйе өзі қояды — екі тексерушіге орын жоқ
+	switch models.AssignmentType(assignment.Type) {
+	case models.AssignmentTypeCode, models.AssignmentTypeTest:
+		return nil, errors.New("double marking is not available for auto-graded assignments")
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+	threshold := float64(DefaultThreshold)
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+	if threshold < 0 || threshold > 100 {
+		return nil, errors.New("threshold must be between 0 and 100")
+	}
+
+	first := teacherID
+	if req.FirstMarkerID != nil {
+		first = *req.FirstMarkerID
+		if !s.isTeacher(first) {
+			return nil, errors.New("first marker must be a teacher")
+		}
+	}
+	if !s.isTeacher(req.SecondMarkerID) {
+		return nil, errors.New("second marker must be a teacher")
+	}
+	if req.SecondMarkerID == first {
+		return nil, errors.New("second marker must differ from the first marker")
+	}
+
+	if enabled && !s.DoubleMarked(assignmentID) {
+		var graded int64
+		if err := s.db.Model(&models.Grade{}).Where("assignment_id = ?", assignmentID).Count(&graded).Error; err != nil {
+			return nil, err
+		}
+		if graded > 0 {
+			return nil, errors.New("assignment already has grades")
+		}
+	}
+
+	settings := &models.ModerationSettings{
+		AssignmentID:   assignmentID,
+		Enabled:        enabled,
+		FirstMarkerID:  req.FirstMarkerID,
+		SecondMarkerID: req.SecondMarkerID,
+		Threshold:      threshold,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "assignment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "first_marker_id", "second_marker_id", "threshold", "updated_at"}),
+	}).Select("*").Create(settings).Error; err != nil {
+		return nil, err
+	}
+	return s.GetSettings(assignmentID)
+}
+
+// Worklist — тексерушіге тапсырылған жұмыстар және оның өз бағасы.
+func (s *Service) Worklist(assignmentID, markerID uint) ([]WorkItem, error) {
+	_, assignment, role, err := s.marker(assignmentID, markerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []struct {
+		ID          uint
+		StudentID   uint       `gorm:"column:student_id"`
+		Username    string     `gorm:"column:username"`
+		Content     string     `gorm:"column:content"`
+		SubmittedAt *time.Time `gorm:"column:submitted_at"`
+	}
+	if err := s.db.Table("assignment_submissions s").
+		Select("s.id, s.student_id, u.username, s.content, s.submitted_at").
+		Joins("JOIN users u ON u.id = s.student_id").
+		Where("s.assignment_id = ? AND s.deleted_at IS NULL AND s.submitted_at IS NOT NULL", assignment.ID).
+		Order("s.submitted_at ASC, s.id ASC").
+		Scan(&subs).Error; err != nil {
+		return nil, err
+	}
+
+	var marks []models.ModerationMark
+	if err := s.db.Where("assignment_id = ? AND role = ?", assignment.ID, role).Find(&marks).Error; err != nil {
+		return nil, err
+	}
+	mine := map[uint]models.ModerationMark{}
+	for _, m := range marks {
+		mine[m.StudentID] = m
+	}
+	var cases []models.ModerationCase
+	if err := s.db.Where("assignment_id = ?", assignment.ID).Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	status := map[uint]string{}
+	for _, c := range cases {
+		status[c.StudentID] = c.Status
+	}
+
+	ids := make([]uint, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.StudentID)
+	}
+	labels, anonymous := s.anon.Labels(assignment.ID, ids)
+
+	items := make([]WorkItem, 0, len(subs))
+	for _, sub := range subs {
+		item := WorkItem{
+			SubmissionID: sub.ID,
+			StudentName:  sub.Username,
+			Content:      sub.Content,
+			SubmittedAt:  sub.SubmittedAt,
+			Status:       models.ModerationPending,
+		}
+		if anonymous {
+			item.StudentName = labels[sub.StudentID]
+		}
+		if st, ok := status[sub.StudentID]; ok {
+			item.Status = st
+		}
+		if m, ok := mine[sub.StudentID]; ok {
+			score := m.Score
+			item.MyScore = &score
+			item.MyFeedback = m.Feedback
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SubmitMark — тексерушінің бағасын сақтайды (іс жабылғанға дейін түзетуге болады).
+// Екі баға да болса: айырма шектен аспаса орташа балл қорытынды баға болады,
+// асса — іс модераторға жіберіледі.
+func (s *Service) SubmitMark(assignmentID, markerID uint, req MarkRequest) (*MarkResult, error) {
+	settings, assignment, role, err := s.marker(assignmentID, markerID)
+	if err != nil {
+		return nil, err
+	}
+	var sub struct {
+		StudentID    uint `gorm:"column:student_id"`
+		AssignmentID uint `gorm:"column:assignment_id"`
+	}
+	if err := s.db.Table("assignment_submissions").
+		Select("student_id, assignment_id").
+		Where("id = ? AND deleted_at IS NULL AND submitted_at IS NOT NULL", req.SubmissionID).
+		Scan(&sub).Error; err != nil || sub.StudentID == 0 || sub.AssignmentID != assignment.ID {
+		return nil, errors.New("submission not found")
+	}
+	if req.Score < 0 || req.Score > assignment.MaxAllowedScore() {
+		return nil, fmt.Errorf("score must be between 0 and %g", assignment.MaxAllowedScore())
+	}
+	feedback := strings.TrimSpace(req.Feedback)
+	if len([]rune(feedback)) > maxTextLen {
+		return nil, errors.New("feedback is too long")
+	}
+
+	mc := models.ModerationCase{AssignmentID: assignment.ID, StudentID: sub.StudentID, Status: models.ModerationPending}
+	if err := s.db.Where("assignment_id = ? AND student_id = ?", assignment.ID, sub.StudentID).
+		FirstOrCreate(&mc).Error; err != nil {
+		return nil, err
+	}
+	if mc.Status == models.ModerationAgreed || mc.Status == models.ModerationResolved {
+		return nil, errors.New("moderation case is closed")
+	}
+
+	mark := models.ModerationMark{
+		AssignmentID: assignment.ID,
+		StudentID:    sub.StudentID,
+		MarkerID:     markerID,
+		Role:         role,
+		Score:        req.Score,
+		Feedback:     feedback,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "assignment_id"}, {Name: "student_id"}, {Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"marker_id", "score", "feedback", "updated_at"}),
+	}).Omit("MarkerName").Create(&mark).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.evaluate(&mc, assignment, settings); err != nil {
+		return nil, err
+	}
+	return &MarkResult{Mark: mark, Status: mc.Status}, nil
+}
+
+// Cases — модераторға тапсырманың барлық істері екі бағамен бірге.
+// status бос болса — барлығы; анонимді тексеруде студент бүркеншік атпен.
+func (s *Service) Cases(assignmentID uint, status string) ([]models.ModerationCase, error) {
+	q := s.db.Where("assignment_id = ?", assignmentID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	cases := []models.ModerationCase{}
+	if err := q.Order("status = 'disputed' DESC, id ASC").Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return cases, nil
+	}
+
+	var marks []models.ModerationMark
+	if err := s.db.Table("moderation_marks m").
+		Select("m.*, u.username AS marker_name").
+		Joins("LEFT JOIN users u ON u.id = m.marker_id").
+		Where("m.assignment_id = ?", assignmentID).
+		Order("m.role ASC").
+		Scan(&marks).Error; err != nil {
+		return nil, err
+	}
+	byStudent := map[uint][]models.ModerationMark{}
+	for _, m := range marks {
+		byStudent[m.StudentID] = append(byStudent[m.StudentID], m)
+	}
+
+	ids := make([]uint, 0, len(cases))
+	for _, c := range cases {
+		ids = append(ids, c.StudentID)
+	}
+	names := map[uint]string{}
+	labels, anonymous := s.anon.Labels(assignmentID, ids)
+	if anonymous {
+		names = labels
+	} else {
+		var users []struct {
+			ID       uint
+			Username string
+		}
+		if err := s.db.Table("users").Select("id, username").Where("id IN ?", ids).Scan(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = u.Username
+		}
+	}
+
+	for i := range cases {
+		cases[i].StudentName = names[cases[i].StudentID]
+		// пока оценок не две, модератор их не видит — проверка остаётся слепой
+		if cases[i].Status != models.ModerationPending {
+			cases[i].Marks = byStudent[cases[i].StudentID]
+		}
+		if anonymous {
+			for j := range cases[i].Marks {
+				cases[i].Marks[j].StudentID = 0
+			}
+			cases[i].StudentID = 0
+		}
+	}
+	return cases, nil
+}
+
+// Resolve — модератор (курс мұғалімі) даулы істің қорытынды бағасын қояды.
+// Екі тексерушінің бағалары өзгермей сақталады.
+func (s *Service) Resolve(caseID, moderatorID uint, req ResolveRequest) (*models.ModerationCase, error) {
+	var mc models.ModerationCase
+	if err := s.db.First(&mc, caseID).Error; err != nil {
+		return nil, errors.New("moderation case not found")
+	}
+	assignment, teacherID, err := s.loadAssignment(mc.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if teacherID != moderatorID {
+		return nil, errors.New("moderation case not found")
+	}
+	if mc.Status != models.ModerationDisputed {
+		return nil, errors.New("only disputed cases can be resolved")
+	}
+	note := strings.TrimSpace(req.Note)
+	if len([]rune(note)) > maxTextLen {
+		return nil, errors.New("note is too long")
+	}
+	feedback := ""
+	if req.Feedback != nil {
+		feedback = strings.TrimSpace(*req.Feedback)
+	} else {
+		feedback = s.markFeedback(mc.AssignmentID, mc.StudentID)
+	}
+
+	// Алдымен істі иеленеміз — параллель шешім бағаны екі рет жазбайды
+	now := time.Now()
+	res := s.db.Model(&models.ModerationCase{}).
+		Where("id = ? AND status = ?", mc.ID, models.ModerationDisputed).
+		Updates(map[string]interface{}{
+			"status":       models.ModerationResolved,
+			"moderator_id": moderatorID,
+			"note":         note,
+			"resolved_at":  now,
+			"updated_at":   now,
+		})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, errors.New("only disputed cases can be resolved")
+	}
+
+	reason := "double marking: moderated"
+	if note != "" {
+		reason += ": " + note
+	}
+	grade, err := s.grades.SetModeratedGrade(mc.AssignmentID, mc.StudentID, *req.Score, feedback, reason, moderatorID)
+	if err != nil {
+		s.db.Model(&models.ModerationCase{}).Where("id = ?", mc.ID).
+			Updates(map[string]interface{}{
+				"status":       models.ModerationDisputed,
+				"moderator_id": nil,
+				"note":         "",
+				"resolved_at":  nil,
+			})
+		return nil, err
+	}
+	if err := s.db.Model(&models.ModerationCase{}).Where("id = ?", mc.ID).
+		Updates(map[string]interface{}{"final_score": grade.Score, "grade_id": grade.ID}).Error; err != nil {
+		return nil, err
+	}
+	s.notifyGrade(assignment, mc.StudentID, grade.Score)
+
+	mc.Status = models.ModerationResolved
+	mc.ModeratorID = &moderatorID
+	mc.Note = note
+	mc.ResolvedAt = &now
+	mc.FinalScore = &grade.Score
+	mc.GradeID = &grade.ID
+	return &mc, nil
+}
+
+// evaluate — екі баға да болса, айырманы шекпен салыстырып істің күйін жаңартады.
+func (s *Service) evaluate(mc *models.ModerationCase, assignment *models.Assignment, settings *models.ModerationSettings) error {
+	var marks []models.ModerationMark
+	if err := s.db.Where("assignment_id = ? AND student_id = ?", mc.AssignmentID, mc.StudentID).
+		Order("role ASC").Find(&marks).Error; err != nil {
+		return err
+	}
+	var first, second *models.ModerationMark
+	for i := range marks {
+		switch marks[i].Role {
+		case models.MarkerFirst:
+			first = &marks[i]
+		case models.MarkerSecond:
+			second = &marks[i]
+		}
+	}
+	if first == nil || second == nil {
+		return nil
+	}
+
+	diff := 0.0
+	if assignment.MaxScore > 0 {
+		diff = round2(math.Abs(first.Score-second.Score) / assignment.MaxScore * 100)
+	}
+	if diff > settings.Threshold {
+		wasDisputed := mc.Status == models.ModerationDisputed
+		if err := s.db.Model(mc).Updates(map[string]interface{}{
+			"status":     models.ModerationDisputed,
+			"difference": diff,
+		}).Error; err != nil {
+			return err
+		}
+		mc.Status, mc.Difference = models.ModerationDisputed, &diff
+		if !wasDisputed {
+			s.notifyModerator(assignment, mc)
+		}
+		return nil
+	}
+
+	// Айырма шектен аспады — орташа балл қорытынды баға болады
+	final := round2((first.Score + second.Score) / 2)
+	feedback := first.Feedback
+	if feedback == "" {
+		feedback = second.Feedback
+	}
+	teacherID, err := s.courseTeacher(assignment.CourseID)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("double marking: marks %g and %g agreed", first.Score, second.Score)
+	grade, err := s.grades.SetModeratedGrade(assignment.ID, mc.StudentID, final, feedback, reason, teacherID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.db.Model(mc).Updates(map[string]interface{}{
+		"status":      models.ModerationAgreed,
+		"difference":  diff,
+		"final_score": grade.Score,
+		"grade_id":    grade.ID,
+		"resolved_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	mc.Status, mc.Difference, mc.FinalScore, mc.GradeID, mc.ResolvedAt = models.ModerationAgreed, &diff, &grade.Score, &grade.ID, &now
+	s.notifyGrade(assignment, mc.StudentID, grade.Score)
+	return nil
+}
+
+// marker — пайдаланушы осы тапсырманың тексерушісі ме және қай рөлде.
+func (s *Service) marker(assignmentID, userID uint) (*models.ModerationSettings, *models.Assignment, string, error) {
+	settings, err := s.GetSettings(assignmentID)
+	if err != nil || !settings.Enabled {
+		return nil, nil, "", errors.New("double marking is not enabled for this assignment")
+	}
+	assignment, teacherID, err := s.loadAssignment(assignmentID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	first := teacherID
+	if settings.FirstMarkerID != nil {
+		first = *settings.FirstMarkerID
+	}
+	switch userID {
+	case first:
+		return settings, assignment, models.MarkerFirst, nil
+	case settings.SecondMarkerID:
+		return settings, assignment, models.MarkerSecond, nil
+	}
+	return nil, nil, "", errors.New("you are not a marker for this assignment")
+}
+
+// loadAssignment — тапсырма және курс мұғалімі
+func (s *Service) loadAssignment(id uint) (*models.Assignment, uint, error) {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, id).Error; err != nil {
+		return nil, 0, errors.New("assignment not found")
+	}
+	teacherID, err := s.courseTeacher(assignment.CourseID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &assignment, teacherID, nil
+}
+
+// courseTeacher — курс мұғалімі; ол модератор болып табылады
+func (s *Service) courseTeacher(courseID uint) (uint, error) {
+	var teacherID uint
+	if err := s.db.Table("courses").Select("teacher_id").
+		Where("id = ? AND deleted_at IS NULL", courseID).
+		Scan(&teacherID).Error; err != nil || teacherID == 0 {
+		return 0, errors.New("course not found")
+	}
+	return teacherID, nil
+}
+
+func (s *Service) isTeacher(userID uint) bool {
+	var count int64
+	s.db.Table("users").Where("id = ? AND role = ?", userID, models.RoleTeacher).Count(&count)
+	return count > 0
+}
+
+// markFeedback — бірінші тексерушінің түсініктемесі, болмаса екіншісінікі
+func (s *Service) markFeedback(assignmentID, studentID uint) string {
+	var marks []models.ModerationMark
+	s.db.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Order("role ASC").Find(&marks)
+	for _, m := range marks {
+		if m.Feedback != "" {
+			return m.Feedback
+		}
+	}
+	return ""
+}
+
+func (s *Service) notifyModerator(assignment *models.Assignment, mc *models.ModerationCase) {
+	if s.hub == nil {
+		return
+	}
+	teacherID, err := s.courseTeacher(assignment.CourseID)
+	if err != nil {
+		return
+	}
+	s.hub.SendToUser(teacherID, "moderation_disputed", map[string]any{
+		"case_id":          mc.ID,
+		"assignment_id":    assignment.ID,
+		"assignment_title": assignment.Title,
+		"difference":       mc.Difference,
+	})
+}
+
+// notifyGrade — жарияланған тапсырмада студентке grade_updated жіберіледі
+func (s *Service) notifyGrade(assignment *models.Assignment, studentID uint, score float64) {
+	if s.hub == nil || !assignment.GradesVisible(time.Now()) {
+		return
+	}
+	var courseTitle string
+	s.db.Table("courses").Select("title").Where("id = ?", assignment.CourseID).Scan(&courseTitle)
+	s.hub.SendToUser(studentID, "grade_updated", map[string]any{
+		"assignment_id":    assignment.ID,
+		"assignment_title": assignment.Title,
+		"course_title":     courseTitle,
+		"score":            score,
+	})
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
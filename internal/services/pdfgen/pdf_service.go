@@ -14,9 +14,17 @@ type CourseReportRow struct {
 	AssignmentTitle string
 	Score           float64
 	MaxScore        float64
+	Symbol          string // оценка по шкале курса, если шкала задана
 	GradedAt        time.Time
 }
 
+// CourseFinalGrade — итоговая оценка студента по журналу курса.
+type CourseFinalGrade struct {
+	StudentName string
+	Percent     *float64
+	Symbol      string
+}
+
 type CourseReport struct {
 	CourseTitle   string
 	TeacherName   string
@@ -24,6 +32,8 @@ type CourseReport struct {
 	StudentsCount int
 	AvgScore      float64
 	Rows          []CourseReportRow
+	ScaleName     string
+	FinalGrades   []CourseFinalGrade
 }
 
 // BuildCourseReportPDF — рендерит отчёт по курсу в PDF и возвращает байты.
@@ -46,7 +56,12 @@ func BuildCourseReportPDF(r CourseReport) ([]byte, error) {
 	pdf.Cell(0, 6, fmt.Sprintf("Teacher: %s", r.TeacherName))
 	pdf.Ln(6)
 	pdf.Cell(0, 6, fmt.Sprintf("Generated: %s", r.GeneratedAt.Format("2006-01-02 15:04")))
-	pdf.Ln(10)
+	pdf.Ln(6)
+	if r.ScaleName != "" {
+		pdf.Cell(0, 6, fmt.Sprintf("Grading scale: %s", r.ScaleName))
+		pdf.Ln(6)
+	}
+	pdf.Ln(4)
 
 	// Сводка
 	pdf.SetFillColor(240, 245, 255)
@@ -66,8 +81,8 @@ func BuildCourseReportPDF(r CourseReport) ([]byte, error) {
 	pdf.SetFont("Arial", "B", 11)
 	pdf.SetFillColor(230, 230, 230)
 	pdf.CellFormat(55, 8, "Student", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(75, 8, "Assignment", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(25, 8, "Score", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(70, 8, "Assignment", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(30, 8, "Score", "1", 0, "C", true, 0, "")
 	pdf.CellFormat(25, 8, "Date", "1", 1, "C", true, 0, "")
 
 	pdf.SetFont("Arial", "", 10)
@@ -75,12 +90,43 @@ func BuildCourseReportPDF(r CourseReport) ([]byte, error) {
 		fill := i%2 == 0
 		pdf.SetFillColor(248, 248, 248)
 		pdf.CellFormat(55, 7, truncate(row.StudentName, 30), "1", 0, "L", fill, 0, "")
-		pdf.CellFormat(75, 7, truncate(row.AssignmentTitle, 42), "1", 0, "L", fill, 0, "")
+		pdf.CellFormat(70, 7, truncate(row.AssignmentTitle, 40), "1", 0, "L", fill, 0, "")
 		score := fmt.Sprintf("%.0f / %.0f", row.Score, row.MaxScore)
-		pdf.CellFormat(25, 7, score, "1", 0, "C", fill, 0, "")
+		if row.Symbol != "" {
+			score += " (" + row.Symbol + ")"
+		}
+		pdf.CellFormat(30, 7, score, "1", 0, "C", fill, 0, "")
 		pdf.CellFormat(25, 7, row.GradedAt.Format("2006-01-02"), "1", 1, "C", fill, 0, "")
 	}
 
+	// Итоговые оценки по журналу
+	if len(r.FinalGrades) > 0 {
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.SetTextColor(30, 30, 30)
+		pdf.Cell(0, 8, "Final grades")
+		pdf.Ln(10)
+
+		pdf.SetFont("Arial", "B", 11)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(100, 8, "Student", "1", 0, "L", true, 0, "")
+		pdf.CellFormat(40, 8, "Final %", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(40, 8, "Grade", "1", 1, "C", true, 0, "")
+
+		pdf.SetFont("Arial", "", 10)
+		for i, g := range r.FinalGrades {
+			fill := i%2 == 0
+			pdf.SetFillColor(248, 248, 248)
+			percent := "-"
+			if g.Percent != nil {
+				percent = fmt.Sprintf("%.1f%%", *g.Percent)
+			}
+			pdf.CellFormat(100, 7, truncate(g.StudentName, 55), "1", 0, "L", fill, 0, "")
+			pdf.CellFormat(40, 7, percent, "1", 0, "C", fill, 0, "")
+			pdf.CellFormat(40, 7, g.Symbol, "1", 1, "C", fill, 0, "")
+		}
+	}
+
 	pdf.Ln(10)
 	pdf.SetFont("Arial", "I", 9)
 	pdf.SetTextColor(150, 150, 150)
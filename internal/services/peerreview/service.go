@@ -0,0 +1,648 @@
+package peerreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/notifier"
+)
+
+// MaxReviewersPerSubmission — бір жұмысқа рецензенттердің жоғарғы шегі.
+const MaxReviewersPerSubmission = 5
+
+// Service — эссе бойынша peer review: рецензенттерді бөлу, бағалау, есеп.
+type Service struct {
+	db   *gorm.DB
+	hub  *notifier.Hub // optional
+	anon *anonymize.Service
+}
+
+func NewService(db *gorm.DB, hub *notifier.Hub) *Service {
+	return &Service{db: db, hub: hub, anon: anonymize.NewService(db)}
+}
+
+// SettingsRequest — мұғалімнің баптаулары
+type SettingsRequest struct {
+	Enabled                *bool     `json:"enabled"`
+	ReviewersPerSubmission int       `json:"reviewers_per_submission"`
+	ReviewDueDate          time.Time `json:"review_due_date"`
+	BlendWeight            float64   `json:"blend_weight"`
+}
+
+// SubmitRequest — рецензенттің бағасы
+type SubmitRequest struct {
+	Scores  []models.PeerCriterionScore `json:"scores"`
+	Comment string                      `json:"comment"`
+}
+
+func (s *Service) GetSettings(assignmentID uint) (*models.PeerReviewSettings, error) {
+	var settings models.PeerReviewSettings
+	if err := s.db.First(&settings, "assignment_id = ?", assignmentID).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Configure — баптауларды сақтайды (upsert).
+func (s *Service) Configure(assignmentID uint, req SettingsRequest) (*models.PeerReviewSettings, error) {
+	assignment, err := s.loadAssignment(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.Type != string(models.AssignmentTypeEssay) {
+		return nil, errors.New("peer review is available only for essays")
+	}
+	if len(parseCriteria(assignment.Criteria)) == 0 {
+		return nil, errors.New("peer review requires essay criteria")
+	}
+	if req.ReviewersPerSubmission == 0 {
+		req.ReviewersPerSubmission = 2
+	}
+	if req.ReviewersPerSubmission < 1 || req.ReviewersPerSubmission > MaxReviewersPerSubmission {
+		return nil, errors.New("reviewers_per_submission must be between 1 and 5")
+	}
+	if req.BlendWeight < 0 || req.BlendWeight > 1 {
+		return nil, errors.New("blend_weight must be between 0 and 1")
+	}
+	if !req.ReviewDueDate.After(submissionDeadline(assignment)) {
+		return nil, errors.New("review_due_date must be after the submission deadline")
+	}
+
+	existing, err := s.GetSettings(assignmentID)
+	if err == nil && existing.AllocatedAt != nil &&
+		existing.ReviewersPerSubmission != req.ReviewersPerSubmission {
+		return nil, errors.New("reviewers are already allocated")
+	}
+
+	settings := &models.PeerReviewSettings{
+		AssignmentID:           assignmentID,
+		Enabled:                req.Enabled == nil || *req.Enabled,
+		ReviewersPerSubmission: req.ReviewersPerSubmission,
+		ReviewDueDate:          req.ReviewDueDate,
+		BlendWeight:            req.BlendWeight,
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "assignment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "reviewers_per_submission", "review_due_date", "blend_weight", "updated_at"}),
+	}).Create(settings).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.GetSettings(assignmentID)
+}
+
+// Run — AllocateDue-ді interval сайын шақырады. Горутинада іске қосу керек.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.AllocateDue(); err != nil {
+			log.Printf("[peer-review] allocation failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AllocateDue — тапсыру мерзімі (close_date, болмаса due_date) өткен тапсырмаларға рецензенттерді бөледі.
+func (s *Service) AllocateDue() (int, error) {
+	var ids []uint
+	err := s.db.Table("peer_review_settings p").
+		Joins("JOIN assignments a ON a.id = p.assignment_id").
+		Where("p.enabled = TRUE AND p.allocated_at IS NULL AND a.deleted_at IS NULL").
+		Where("COALESCE(a.close_date, a.due_date) <= NOW()").
+		Pluck("p.assignment_id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	allocated := 0
+	for _, id := range ids {
+		if _, err := s.Allocate(id); err != nil {
+			log.Printf("[peer-review] assignment %d: %v", id, err)
+			continue
+		}
+		allocated++
+	}
+	return allocated, nil
+}
+
+// Allocate — әр жұмысқа N анонимді рецензент тағайындайды.
+// Жұмыстар араластырылып, шеңбер бойымен ығысады: ешкім өз жұмысын (топтық тапсырмада —
+// өз тобының жұмысын) алмайды, әр жұмыстың авторлары дәл N жұмысты бағалайды,
+// топта олар мүшелер арасында кезекпен бөлінеді. Құрылған рецензиялар санын қайтарады.
+func (s *Service) Allocate(assignmentID uint) (int, error) {
+	assignment, err := s.loadAssignment(assignmentID)
+	if err != nil {
+		return 0, err
+	}
+	settings, err := s.GetSettings(assignmentID)
+	if err != nil {
+		return 0, errors.New("peer review is not configured")
+	}
+	if !settings.Enabled {
+		return 0, errors.New("peer review is disabled")
+	}
+	if time.Now().Before(assignment.DueDate) {
+		return 0, errors.New("peer review can be allocated only after the deadline")
+	}
+
+	type subRow struct {
+		ID        uint  `gorm:"column:id"`
+		StudentID uint  `gorm:"column:student_id"`
+		GroupID   *uint `gorm:"column:group_id"`
+	}
+	var subs []subRow
+	if err := s.db.Table("assignment_submissions").
+		Select("id, student_id, group_id").
+		Where("assignment_id = ? AND deleted_at IS NULL", assignmentID).
+		Where("status IN ?", []string{models.SubmissionStatusSubmitted, models.SubmissionStatusLate, models.SubmissionStatusGraded}).
+		Find(&subs).Error; err != nil {
+		return 0, err
+	}
+
+	perSubmission := settings.ReviewersPerSubmission
+	if perSubmission > len(subs)-1 {
+		perSubmission = len(subs) - 1
+	}
+
+	// Әр жұмыстың авторлары: топ мүшелері, болмаса жіберген студент
+	authors := make([][]uint, len(subs))
+	for i, sub := range subs {
+		if sub.GroupID != nil {
+			if err := s.db.Table("assignment_group_members").
+				Where("group_id = ?", *sub.GroupID).
+				Order("student_id").
+				Pluck("student_id", &authors[i]).Error; err != nil {
+				return 0, err
+			}
+		}
+		if len(authors[i]) == 0 {
+			authors[i] = []uint{sub.StudentID}
+		}
+	}
+
+	reviews := make([]models.PeerReview, 0, len(subs)*max(perSubmission, 0))
+	order := rand.Perm(len(subs))
+	used := make([]int, len(subs)) // авторлар арасында жүктемені кезекпен бөлу
+	for pos, i := range order {
+		for k := 1; k <= perSubmission; k++ {
+			// Рецензент басқа жұмыстың авторы — өз тобының жұмысын алмайды
+			j := order[(pos+k)%len(order)]
+			reviewer := authors[j][used[j]%len(authors[j])]
+			used[j]++
+			reviews = append(reviews, models.PeerReview{
+				AssignmentID: assignmentID,
+				SubmissionID: subs[i].ID,
+				ReviewerID:   reviewer,
+				Status:       models.PeerReviewStatusPending,
+				Scores:       "[]",
+			})
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Шартты UPDATE — параллель екі шақыру екі рет бөлмейді
+		res := tx.Exec("UPDATE peer_review_settings SET allocated_at = NOW() WHERE assignment_id = ? AND allocated_at IS NULL", assignmentID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("reviewers are already allocated")
+		}
+		if len(reviews) == 0 {
+			return nil
+		}
+		return tx.Create(&reviews).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.notifyReviewers(assignment, settings, reviews)
+	return len(reviews), nil
+}
+
+// ListForReviewer — студентке тағайындалған рецензиялар (мазмұнсыз)
+func (s *Service) ListForReviewer(reviewerID uint) ([]models.PeerReviewTask, error) {
+	type row struct {
+		models.PeerReview
+		AssignmentTitle string    `gorm:"column:assignment_title"`
+		ReviewDueDate   time.Time `gorm:"column:review_due_date"`
+	}
+	var rows []row
+	err := s.db.Table("peer_reviews pr").
+		Select("pr.*, a.title AS assignment_title, p.review_due_date").
+		Joins("JOIN assignments a ON a.id = pr.assignment_id").
+		Joins("JOIN peer_review_settings p ON p.assignment_id = pr.assignment_id").
+		Where("pr.reviewer_id = ? AND a.deleted_at IS NULL", reviewerID).
+		Order("p.review_due_date ASC, pr.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]models.PeerReviewTask, 0, len(rows))
+	for _, r := range rows {
+		tasks = append(tasks, models.PeerReviewTask{
+			ID:              r.ID,
+			AssignmentID:    r.AssignmentID,
+			AssignmentTitle: r.AssignmentTitle,
+			Status:          r.Status,
+			Scores:          parseScores(r.Scores),
+			Comment:         r.Comment,
+			TotalScore:      r.TotalScore,
+			ReviewDueDate:   r.ReviewDueDate,
+			SubmittedAt:     r.SubmittedAt,
+		})
+	}
+	return tasks, nil
+}
+
+// GetForReviewer — рецензия жұмыс мәтінімен және критерийлермен (автор көрсетілмейді)
+func (s *Service) GetForReviewer(reviewID, reviewerID uint) (*models.PeerReviewTask, error) {
+	review, assignment, settings, err := s.loadReview(reviewID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	var content string
+	if err := s.db.Table("assignment_submissions").
+		Select("content").
+		Where("id = ?", review.SubmissionID).
+		Scan(&content).Error; err != nil {
+		return nil, err
+	}
+	return toTask(review, assignment, settings, content), nil
+}
+
+// SubmitReview — рецензия мерзімі ішінде бағалау (қайта жіберуге болады)
+func (s *Service) SubmitReview(reviewID, reviewerID uint, req SubmitRequest) (*models.PeerReviewTask, error) {
+	review, assignment, settings, err := s.loadReview(reviewID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.After(settings.ReviewDueDate) {
+		return nil, errors.New("review window is closed")
+	}
+
+	criteria := parseCriteria(assignment.Criteria)
+	if len(criteria) == 0 {
+		return nil, errors.New("assignment has no criteria")
+	}
+	maxByID := make(map[int]float64, len(criteria))
+	totalMax := 0.0
+	for _, c := range criteria {
+		maxByID[c.ID] = float64(criterionMax(c))
+		totalMax += float64(criterionMax(c))
+	}
+	if len(req.Scores) != len(criteria) {
+		return nil, errors.New("every criterion must be scored")
+	}
+	seen := make(map[int]bool, len(req.Scores))
+	sum := 0.0
+	for _, sc := range req.Scores {
+		limit, ok := maxByID[sc.CriterionID]
+		if !ok {
+			return nil, errors.New("score contains unknown criterion")
+		}
+		if seen[sc.CriterionID] {
+			return nil, errors.New("score contains duplicate criterion")
+		}
+		if sc.Score < 0 || sc.Score > limit {
+			return nil, errors.New("criterion score is out of range")
+		}
+		seen[sc.CriterionID] = true
+		sum += sc.Score
+	}
+
+	total := 0.0
+	if totalMax > 0 {
+		total = math.Round(sum/totalMax*assignment.MaxScore*100) / 100
+	}
+	scoresJSON, _ := json.Marshal(req.Scores)
+
+	review.Scores = string(scoresJSON)
+	review.Comment = strings.TrimSpace(req.Comment)
+	review.TotalScore = total
+	review.Status = models.PeerReviewStatusSubmitted
+	review.SubmittedAt = &now
+	if err := s.db.Model(&models.PeerReview{}).
+		Where("id = ?", review.ID).
+		Select("scores", "comment", "total_score", "status", "submitted_at").
+		Updates(review).Error; err != nil {
+		return nil, err
+	}
+	return toTask(review, assignment, settings, ""), nil
+}
+
+// ReceivedReviews — студент өз жұмысына келген рецензияларды мерзім біткеннен кейін көреді.
+func (s *Service) ReceivedReviews(assignmentID, studentID uint) ([]models.ReceivedPeerReview, error) {
+	settings, err := s.GetSettings(assignmentID)
+	if err != nil {
+		return nil, errors.New("peer review is not configured")
+	}
+	if time.Now().Before(settings.ReviewDueDate) {
+		return nil, errors.New("peer reviews are not available yet")
+	}
+	submissionID, err := s.studentSubmissionID(assignmentID, studentID)
+	if err != nil {
+		return nil, errors.New("submission not found")
+	}
+
+	var reviews []models.PeerReview
+	if err := s.db.Where("submission_id = ? AND status = ?", submissionID, models.PeerReviewStatusSubmitted).
+		Order("id ASC").
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.ReceivedPeerReview, 0, len(reviews))
+	for i, r := range reviews {
+		out = append(out, models.ReceivedPeerReview{
+			Label:      "Reviewer " + strconv.Itoa(i+1),
+			Scores:     parseScores(r.Scores),
+			Comment:    r.Comment,
+			TotalScore: r.TotalScore,
+		})
+	}
+	return out, nil
+}
+
+// Report — мұғалімге толық есеп және рецензенттер сапасы.
+func (s *Service) Report(assignmentID uint) (*models.PeerReviewReport, error) {
+	settings, err := s.GetSettings(assignmentID)
+	if err != nil {
+		return nil, errors.New("peer review is not configured")
+	}
+
+	var rows []models.PeerReviewReportRow
+	err = s.db.Raw(`
+		SELECT
+			pr.id, pr.submission_id,
+			s.student_id AS author_id, au.username AS author_name,
+			pr.reviewer_id, ru.username AS reviewer_name,
+			pr.status, pr.total_score,
+			g.score AS teacher_score,
+			LENGTH(pr.comment) AS comment_length,
+			pr.submitted_at
+		FROM peer_reviews pr
+		JOIN assignment_submissions s ON s.id = pr.submission_id
+		JOIN users au ON au.id = s.student_id
+		JOIN users ru ON ru.id = pr.reviewer_id
+		LEFT JOIN grades g ON g.student_id = s.student_id AND g.assignment_id = pr.assignment_id
+		WHERE pr.assignment_id = ?
+		ORDER BY pr.submission_id, pr.id
+	`, assignmentID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Жұмыс бойынша орташа peer баға
+	sumBySub := map[uint]float64{}
+	cntBySub := map[uint]int{}
+	for _, r := range rows {
+		if r.Status == models.PeerReviewStatusSubmitted {
+			sumBySub[r.SubmissionID] += r.TotalScore
+			cntBySub[r.SubmissionID]++
+		}
+	}
+	for i := range rows {
+		if n := cntBySub[rows[i].SubmissionID]; n > 0 {
+			rows[i].PeerMean = round2(sumBySub[rows[i].SubmissionID] / float64(n))
+		}
+	}
+
+	type acc struct {
+		q                   models.ReviewerQuality
+		peerDev, teacherDev float64
+		peerN, teacherN     int
+		commentSum          int
+	}
+	byReviewer := map[uint]*acc{}
+	order := []uint{}
+	for _, r := range rows {
+		a, ok := byReviewer[r.ReviewerID]
+		if !ok {
+			a = &acc{q: models.ReviewerQuality{ReviewerID: r.ReviewerID, ReviewerName: r.ReviewerName}}
+			byReviewer[r.ReviewerID] = a
+			order = append(order, r.ReviewerID)
+		}
+		a.q.Assigned++
+		if r.Status != models.PeerReviewStatusSubmitted {
+			continue
+		}
+		a.q.Completed++
+		a.commentSum += r.CommentLength
+		// Ауытқу — осы жұмысқа берілген басқа рецензиялардың ортасынан
+		if n := cntBySub[r.SubmissionID]; n > 1 {
+			othersMean := (sumBySub[r.SubmissionID] - r.TotalScore) / float64(n-1)
+			a.peerDev += math.Abs(r.TotalScore - othersMean)
+			a.peerN++
+		}
+		if r.TeacherScore != nil {
+			a.teacherDev += math.Abs(r.TotalScore - *r.TeacherScore)
+			a.teacherN++
+		}
+	}
+
+	reviewers := make([]models.ReviewerQuality, 0, len(order))
+	for _, id := range order {
+		a := byReviewer[id]
+		if a.peerN > 0 {
+			a.q.AvgDeviationPeers = round2(a.peerDev / float64(a.peerN))
+		}
+		if a.teacherN > 0 {
+			v := round2(a.teacherDev / float64(a.teacherN))
+			a.q.AvgDeviationTeacher = &v
+		}
+		if a.q.Completed > 0 {
+			a.q.AvgCommentLength = round2(float64(a.commentSum) / float64(a.q.Completed))
+		}
+		reviewers = append(reviewers, a.q)
+	}
+
+	if rows == nil {
+		rows = []models.PeerReviewReportRow{}
+	}
+	s.maskReport(assignmentID, rows, reviewers)
+	return &models.PeerReviewReport{Settings: settings, Reviews: rows, Reviewers: reviewers}, nil
+}
+
+// maskReport — анонимді тексеруде авторлар мен рецензенттер бүркеншік атпен көрсетіледі.
+func (s *Service) maskReport(assignmentID uint, rows []models.PeerReviewReportRow, reviewers []models.ReviewerQuality) {
+	ids := make([]uint, 0, len(rows)*2)
+	for _, r := range rows {
+		ids = append(ids, r.AuthorID, r.ReviewerID)
+	}
+	labels, ok := s.anon.Labels(assignmentID, ids)
+	if !ok {
+		return
+	}
+	for i := range rows {
+		rows[i].AuthorName = labels[rows[i].AuthorID]
+		rows[i].ReviewerName = labels[rows[i].ReviewerID]
+		rows[i].AuthorID, rows[i].ReviewerID = 0, 0
+	}
+	for i := range reviewers {
+		reviewers[i].ReviewerName = labels[reviewers[i].ReviewerID]
+		reviewers[i].ReviewerID = 0
+	}
+}
+
+// PeerScore — студент жұмысының орташа peer бағасы және оның қорытындыдағы салмағы.
+// services.PeerScoreProvider интерфейсін іске асырады.
+func (s *Service) PeerScore(assignmentID, studentID uint) (float64, float64, bool) {
+	settings, err := s.GetSettings(assignmentID)
+	if err != nil || !settings.Enabled || settings.BlendWeight <= 0 {
+		return 0, 0, false
+	}
+	submissionID, err := s.studentSubmissionID(assignmentID, studentID)
+	if err != nil {
+		return 0, 0, false
+	}
+	var agg struct {
+		Avg   float64 `gorm:"column:avg"`
+		Count int     `gorm:"column:cnt"`
+	}
+	if err := s.db.Table("peer_reviews").
+		Select("COALESCE(AVG(total_score), 0) AS avg, COUNT(*) AS cnt").
+		Where("submission_id = ? AND status = ?", submissionID, models.PeerReviewStatusSubmitted).
+		Scan(&agg).Error; err != nil || agg.Count == 0 {
+		return 0, 0, false
+	}
+	return round2(agg.Avg), settings.BlendWeight, true
+}
+
+func (s *Service) loadAssignment(id uint) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, id).Error; err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	return &assignment, nil
+}
+
+func (s *Service) loadReview(reviewID, reviewerID uint) (*models.PeerReview, *models.Assignment, *models.PeerReviewSettings, error) {
+	var review models.PeerReview
+	if err := s.db.First(&review, reviewID).Error; err != nil || review.ReviewerID != reviewerID {
+		return nil, nil, nil, errors.New("review not found")
+	}
+	assignment, err := s.loadAssignment(review.AssignmentID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	settings, err := s.GetSettings(review.AssignmentID)
+	if err != nil {
+		return nil, nil, nil, errors.New("peer review is not configured")
+	}
+	return &review, assignment, settings, nil
+}
+
+// studentSubmissionID — студенттің жеке немесе топтық жұмысы
+func (s *Service) studentSubmissionID(assignmentID, studentID uint) (uint, error) {
+	var id uint
+	err := s.db.Raw(`
+		SELECT s.id FROM assignment_submissions s
+		WHERE s.assignment_id = ? AND s.deleted_at IS NULL
+		  AND (s.student_id = ? OR s.group_id IN (
+		      SELECT group_id FROM assignment_group_members WHERE student_id = ? AND assignment_id = ?))
+		LIMIT 1
+	`, assignmentID, studentID, studentID, assignmentID).Scan(&id).Error
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+func (s *Service) notifyReviewers(assignment *models.Assignment, settings *models.PeerReviewSettings, reviews []models.PeerReview) {
+	if s.hub == nil {
+		return
+	}
+	count := map[uint]int{}
+	for _, r := range reviews {
+		count[r.ReviewerID]++
+	}
+	for reviewerID, n := range count {
+		s.hub.SendToUser(reviewerID, "peer_review_assigned", map[string]any{
+			"assignment_id":    assignment.ID,
+			"assignment_title": assignment.Title,
+			"review_due_date":  settings.ReviewDueDate,
+			"reviews":          n,
+		})
+	}
+}
+
+func toTask(review *models.PeerReview, assignment *models.Assignment, settings *models.PeerReviewSettings, content string) *models.PeerReviewTask {
+	return &models.PeerReviewTask{
+		ID:              review.ID,
+		AssignmentID:    assignment.ID,
+		AssignmentTitle: assignment.Title,
+		Content:         content,
+		Criteria:        parseCriteria(assignment.Criteria),
+		Status:          review.Status,
+		Scores:          parseScores(review.Scores),
+		Comment:         review.Comment,
+		TotalScore:      review.TotalScore,
+		ReviewDueDate:   settings.ReviewDueDate,
+		SubmittedAt:     review.SubmittedAt,
+	}
+}
+
+// submissionDeadline — кешіктіріп тапсыру мүмкін болатын соңғы уақыт
+func submissionDeadline(a *models.Assignment) time.Time {
+	if a.CloseDate != nil {
+		return *a.CloseDate
+	}
+	return a.DueDate
+}
+
+func parseCriteria(raw string) []models.EssayCriterion {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+	var criteria []models.EssayCriterion
+	if err := json.Unmarshal([]byte(raw), &criteria); err != nil {
+		return nil
+	}
+	for i := range criteria {
+		if criteria[i].ID == 0 {
+			criteria[i].ID = i + 1
+		}
+	}
+	return criteria
+}
+
+func criterionMax(c models.EssayCriterion) int {
+	if c.MaxPoints > 0 {
+		return c.MaxPoints
+	}
+	return c.MaxScore
+}
+
+func parseScores(raw string) []models.PeerCriterionScore {
+	scores := []models.PeerCriterionScore{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &scores)
+	}
+	return scores
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
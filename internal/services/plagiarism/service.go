@@ -9,15 +9,17 @@ import (
 	"gorm.io/gorm"
 
 	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
 )
 
 // Service — оркестратор: submissions → TF-IDF → нәтижені БД-ға сақтау.
 type Service struct {
-	db *gorm.DB
+	db   *gorm.DB
+	anon *anonymize.Service
 }
 
 func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+	return &Service{db: db, anon: anonymize.NewService(db)}
 }
 
 // SimilarityThreshold — pair суspіціозды болу үшін минималды cosine.
@@ -125,6 +127,7 @@ func (s *Service) LatestReport(assignmentID uint) (*models.PlagiarismReportRespo
 	if rep.Pairs != "" {
 		_ = json.Unmarshal([]byte(rep.Pairs), &pairs)
 	}
+	s.maskPairs(assignmentID, pairs)
 	return &models.PlagiarismReportResponse{
 		ID:            rep.ID,
 		AssignmentID:  rep.AssignmentID,
@@ -137,6 +140,24 @@ func (s *Service) LatestReport(assignmentID uint) (*models.PlagiarismReportRespo
 	}, nil
 }
 
+// maskPairs — анонимді тексеруде студент аттары мен ID-лерін бүркеншік аттарға ауыстырады.
+func (s *Service) maskPairs(assignmentID uint, pairs []models.PlagiarismPair) {
+	ids := make([]uint, 0, len(pairs)*2)
+	for _, p := range pairs {
+		ids = append(ids, p.StudentA, p.StudentB)
+	}
+	labels, ok := s.anon.Labels(assignmentID, ids)
+	if !ok {
+		return
+	}
+	for i := range pairs {
+		pairs[i].StudentAName = labels[pairs[i].StudentA]
+		pairs[i].StudentBName = labels[pairs[i].StudentB]
+		pairs[i].StudentA = 0
+		pairs[i].StudentB = 0
+	}
+}
+
 // snippet — мәтіннің 240-символдық алғашқы үзіндісі (бір сөздің ортасында үзбеу).
 func snippet(text string) string {
 	const limit = 240
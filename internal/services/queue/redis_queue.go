@@ -34,6 +34,7 @@ type Queue struct {
 	rdb       *redis.Client
 	queueKey  string
 	statusTTL time.Duration
+	lanes     map[string]string // job_type → ключ отдельного списка (общий для всех Lane)
 }
 
 // NewFromEnv создаёт очередь из REDIS_ADDR. Возвращает (nil, nil) если не задан.
@@ -50,7 +51,21 @@ func NewFromEnv() (*Queue, error) {
 		return nil, err
 	}
 	log.Printf("[queue] Redis готов: %s", addr)
-	return &Queue{rdb: rdb, queueKey: "smartcourse:jobs", statusTTL: 24 * time.Hour}, nil
+	return &Queue{rdb: rdb, queueKey: "smartcourse:jobs", statusTTL: 24 * time.Hour, lanes: map[string]string{}}, nil
+}
+
+// Lane — отдельный список задач со своим воркером: долгие задачи (выгрузки)
+// не блокируют проверку кода и AI. Задачи jobTypes ставятся в этот список.
+// Вызывать до запуска воркеров.
+func (q *Queue) Lane(name string, jobTypes ...string) *Queue {
+	if q == nil {
+		return nil
+	}
+	key := "smartcourse:jobs:" + name
+	for _, t := range jobTypes {
+		q.lanes[t] = key
+	}
+	return &Queue{rdb: q.rdb, queueKey: key, statusTTL: q.statusTTL, lanes: q.lanes}
 }
 
 // Enqueue ставит задачу в очередь и сохраняет начальный статус.
@@ -70,7 +85,11 @@ func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any, userID
 		CreatedAt: time.Now(),
 	}
 	data, _ := json.Marshal(job)
-	if err := q.rdb.LPush(ctx, q.queueKey, data).Err(); err != nil {
+	key := q.queueKey
+	if lane, ok := q.lanes[jobType]; ok {
+		key = lane
+	}
+	if err := q.rdb.LPush(ctx, key, data).Err(); err != nil {
 		return "", err
 	}
 	if err := q.SetStatus(ctx, JobStatus{ID: job.ID, Status: "queued", Progress: 0, UpdatedAt: time.Now()}); err != nil {
@@ -10,21 +10,30 @@ import (
 // Handler — обработчик одного типа задач. Возвращает результат и ошибку.
 type Handler func(ctx context.Context, job *Job, progress func(int)) (any, error)
 
+// DefaultJobTimeout — лимит на одну задачу, если для типа не задан свой.
+const DefaultJobTimeout = 2 * time.Minute
+
 // Worker запускает блокирующий цикл обработки задач.
 type Worker struct {
 	q        *Queue
 	handlers map[string]Handler
+	timeouts map[string]time.Duration
 	notifier func(userID uint, status JobStatus) // публикация статуса в WebSocket
 }
 
 func NewWorker(q *Queue) *Worker {
-	return &Worker{q: q, handlers: map[string]Handler{}}
+	return &Worker{q: q, handlers: map[string]Handler{}, timeouts: map[string]time.Duration{}}
 }
 
 func (w *Worker) Register(jobType string, h Handler) {
 	w.handlers[jobType] = h
 }
 
+// SetTimeout — свой лимит времени для типа задач (например, долгие выгрузки).
+func (w *Worker) SetTimeout(jobType string, d time.Duration) {
+	w.timeouts[jobType] = d
+}
+
 // SetNotifier — подключает publish в WS-хаб. Может быть nil.
 func (w *Worker) SetNotifier(fn func(userID uint, status JobStatus)) {
 	w.notifier = fn
@@ -75,7 +84,11 @@ func (w *Worker) process(ctx context.Context, job *Job) {
 		w.update(ctx, job, JobStatus{ID: job.ID, Status: "running", Progress: p})
 	}
 
-	jobCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	timeout := DefaultJobTimeout
+	if d, ok := w.timeouts[job.Type]; ok && d > 0 {
+		timeout = d
+	}
+	jobCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	result, err := handler(jobCtx, job, progress)
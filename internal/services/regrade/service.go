@@ -0,0 +1,416 @@
+package regrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+	"rest-project/internal/services/anonymize"
+	"rest-project/internal/services/notifier"
+)
+
+const (
+	// maxJustificationLen — студент негіздемесінің және мұғалім жауабының шегі (символ)
+	maxJustificationLen = 4000
+	// MaxWindowDays — апелляция мерзімінің жоғарғы шегі
+	MaxWindowDays = 90
+)
+
+// GradeUpdater — бағаны журналға жазылатын жолмен өзгертеді (services.GradeService).
+type GradeUpdater interface {
+	UpdateGrade(id uint, score float64, criteria []models.CriterionScoreInput, feedback, reason string, teacherID uint) (*models.Grade, error)
+}
+
+// Service — бағаға апелляция: студент сұраныс береді, мұғалім қабылдап бағаны түзетеді
+// немесе жауаппен қабылдамайды. Екі жаққа да WS-хабарлама жіберіледі.
+type Service struct {
+	db     *gorm.DB
+	hub    *notifier.Hub // optional
+	grades GradeUpdater
+	anon   *anonymize.Service
+}
+
+func NewService(db *gorm.DB, hub *notifier.Hub, grades GradeUpdater) *Service {
+	return &Service{db: db, hub: hub, grades: grades, anon: anonymize.NewService(db)}
+}
+
+// CreateRequest — студенттің сұранысы. criterion_id немесе question_id — бағаның бір бөлігіне.
+type CreateRequest struct {
+	GradeID       uint   `json:"grade_id" binding:"required"`
+	CriterionID   *int   `json:"criterion_id"`
+	QuestionID    *int   `json:"question_id"`
+	Justification string `json:"justification"`
+}
+
+// AcceptRequest — мұғалімнің шешімі: жаңа балл (немесе критерийлер), feedback өзгермесе бос.
+type AcceptRequest struct {
+	Score    *float64                     `json:"score"`
+	Criteria []models.CriterionScoreInput `json:"criteria"`
+	Feedback *string                      `json:"feedback"`
+	Reply    string                       `json:"reply"`
+}
+
+// Create — студент өз бағасына сұраныс береді. Баға жариялануы керек, мерзім өтпеуі керек,
+// бір бағаға бір уақытта бір ғана ашық сұраныс.
+func (s *Service) Create(studentID uint, req CreateRequest) (*models.RegradeRequest, error) {
+	var grade models.Grade
+	if err := s.db.Where("id = ? AND student_id = ?", req.GradeID, studentID).First(&grade).Error; err != nil {
+		return nil, errors.New("grade not found")
+	}
+	assignment, err := s.loadAssignment(grade.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !assignment.GradesVisible(now) {
+		return nil, errors.New("grade not found")
+	}
+	deadline, open := Deadline(assignment, &grade)
+	if !open {
+		return nil, errors.New("regrade requests are closed for this assignment")
+	}
+	if now.After(deadline) {
+		return nil, errors.New("regrade request deadline has passed")
+	}
+
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" {
+		return nil, errors.New("justification is required")
+	}
+	if len([]rune(justification)) > maxJustificationLen {
+		return nil, errors.New("justification is too long")
+	}
+	if req.CriterionID != nil && req.QuestionID != nil {
+		return nil, errors.New("choose either a criterion or a question")
+	}
+	if req.CriterionID != nil && !hasCriterion(assignment, *req.CriterionID) {
+		return nil, fmt.Errorf("unknown criterion %d", *req.CriterionID)
+	}
+	if req.QuestionID != nil && !hasQuestion(assignment, *req.QuestionID) {
+		return nil, fmt.Errorf("unknown question %d", *req.QuestionID)
+	}
+
+	var pending int64
+	if err := s.db.Model(&models.RegradeRequest{}).
+		Where("grade_id = ? AND status = ?", grade.ID, models.RegradeStatusPending).
+		Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, errors.New("a regrade request for this grade is already pending")
+	}
+
+	request := &models.RegradeRequest{
+		GradeID:       grade.ID,
+		AssignmentID:  assignment.ID,
+		StudentID:     studentID,
+		CriterionID:   req.CriterionID,
+		QuestionID:    req.QuestionID,
+		Justification: justification,
+		Status:        models.RegradeStatusPending,
+		OldScore:      grade.Score,
+	}
+	if err := s.db.Omit("AssignmentTitle", "StudentName").Create(request).Error; err != nil {
+		return nil, err
+	}
+	request.AssignmentTitle = assignment.Title
+	s.notifyTeacher(assignment, request)
+	return request, nil
+}
+
+// ListForStudent — студенттің барлық сұраныстары, жаңалары бірінші.
+func (s *Service) ListForStudent(studentID uint) ([]models.RegradeRequest, error) {
+	requests := []models.RegradeRequest{}
+	err := s.db.Table("regrade_requests r").
+		Select("r.*, a.title AS assignment_title").
+		Joins("JOIN assignments a ON a.id = r.assignment_id").
+		Where("r.student_id = ?", studentID).
+		Order("r.created_at DESC").
+		Scan(&requests).Error
+	return requests, err
+}
+
+// Withdraw — студент ашық сұранысын қайтарып алады.
+func (s *Service) Withdraw(id, studentID uint) error {
+	res := s.db.Model(&models.RegradeRequest{}).
+		Where("id = ? AND student_id = ? AND status = ?", id, studentID, models.RegradeStatusPending).
+		Updates(map[string]interface{}{"status": models.RegradeStatusWithdrawn, "updated_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("pending regrade request not found")
+	}
+	return nil
+}
+
+// ListForTeacher — мұғалім курстарындағы сұраныстар. assignmentID > 0 — бір тапсырма,
+// status бос болса — барлығы. Анонимді тексеруде студент бүркеншік атпен көрсетіледі.
+func (s *Service) ListForTeacher(teacherID, assignmentID uint, status string) ([]models.RegradeRequest, error) {
+	q := s.db.Table("regrade_requests r").
+		Select("r.*, a.title AS assignment_title, u.username AS student_name").
+		Joins("JOIN assignments a ON a.id = r.assignment_id AND a.deleted_at IS NULL").
+		Joins("JOIN courses c ON c.id = a.course_id AND c.deleted_at IS NULL").
+		Joins("LEFT JOIN users u ON u.id = r.student_id").
+		Where("c.teacher_id = ?", teacherID)
+	if assignmentID > 0 {
+		q = q.Where("r.assignment_id = ?", assignmentID)
+	}
+	if status != "" {
+		q = q.Where("r.status = ?", status)
+	}
+	requests := []models.RegradeRequest{}
+	if err := q.Order("r.created_at ASC").Scan(&requests).Error; err != nil {
+		return nil, err
+	}
+	s.mask(requests)
+	return requests, nil
+}
+
+// Accept — мұғалім сұранысты қабылдайды: баға GradeUpdater арқылы өзгереді
+// (тарихқа сұраныс нөмірі себеп ретінде жазылады), студентке хабарлама кетеді.
+func (s *Service) Accept(id, teacherID uint, req AcceptRequest) (*models.RegradeRequest, error) {
+	if req.Score == nil && len(req.Criteria) == 0 {
+		return nil, errors.New("score or criteria is required")
+	}
+	reply := strings.TrimSpace(req.Reply)
+	if len([]rune(reply)) > maxJustificationLen {
+		return nil, errors.New("reply is too long")
+	}
+	request, assignment, err := s.teacherRequest(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	var grade models.Grade
+	if err := s.db.First(&grade, request.GradeID).Error; err != nil {
+		return nil, errors.New("grade not found")
+	}
+	feedback := grade.Feedback
+	if req.Feedback != nil {
+		feedback = *req.Feedback
+	}
+
+	// Алдымен сұранысты иеленеміз — параллель шешім бағаны екі рет өзгертпейді
+	if err := s.resolve(request, models.RegradeStatusAccepted, reply, teacherID); err != nil {
+		return nil, err
+	}
+	score := 0.0
+	if req.Score != nil {
+		score = *req.Score
+	}
+	reason := fmt.Sprintf("regrade request #%d", request.ID)
+	if reply != "" {
+		reason += ": " + reply
+	}
+	updated, err := s.grades.UpdateGrade(grade.ID, score, req.Criteria, feedback, reason, teacherID)
+	if err != nil {
+		s.reopen(request.ID)
+		return nil, err
+	}
+	if err := s.db.Model(&models.RegradeRequest{}).Where("id = ?", request.ID).
+		Update("new_score", updated.Score).Error; err != nil {
+		return nil, err
+	}
+	request.NewScore = &updated.Score
+	s.notifyStudent(assignment, request)
+	return request, nil
+}
+
+// Reject — мұғалім сұранысты жауаппен қабылдамайды; баға өзгермейді.
+func (s *Service) Reject(id, teacherID uint, reply string) (*models.RegradeRequest, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, errors.New("reply is required to reject a request")
+	}
+	if len([]rune(reply)) > maxJustificationLen {
+		return nil, errors.New("reply is too long")
+	}
+	request, assignment, err := s.teacherRequest(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolve(request, models.RegradeStatusRejected, reply, teacherID); err != nil {
+		return nil, err
+	}
+	s.notifyStudent(assignment, request)
+	return request, nil
+}
+
+// SetWindow — баға жарияланғаннан кейін сұраныс беруге болатын күндер (0 — жабық).
+func (s *Service) SetWindow(assignmentID uint, days int) error {
+	if days < 0 || days > MaxWindowDays {
+		return fmt.Errorf("window_days must be between 0 and %d", MaxWindowDays)
+	}
+	return s.db.Model(&models.Assignment{}).Where("id = ?", assignmentID).
+		Update("regrade_window_days", days).Error
+}
+
+// Deadline — сұраныс берудің соңғы уақыты: бағаның соңғы өзгерісі немесе жариялануы
+// (қайсысы кейін) + тапсырманың мерзімі. Мерзім 0 болса, сұраныстар жабық.
+func Deadline(assignment *models.Assignment, grade *models.Grade) (time.Time, bool) {
+	if assignment.RegradeWindowDays <= 0 {
+		return time.Time{}, false
+	}
+	start := grade.UpdatedAt
+	switch {
+	case assignment.GradesReleasedAt != nil:
+		if assignment.GradesReleasedAt.After(start) {
+			start = *assignment.GradesReleasedAt
+		}
+	case assignment.GradeReleaseMode == models.GradeReleaseScheduled && assignment.GradesReleaseAt != nil:
+		if assignment.GradesReleaseAt.After(start) {
+			start = *assignment.GradesReleaseAt
+		}
+	}
+	return start.AddDate(0, 0, assignment.RegradeWindowDays), true
+}
+
+func (s *Service) resolve(request *models.RegradeRequest, status, reply string, teacherID uint) error {
+	now := time.Now()
+	res := s.db.Model(&models.RegradeRequest{}).
+		Where("id = ? AND status = ?", request.ID, models.RegradeStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reply":       reply,
+			"resolved_by": teacherID,
+			"resolved_at": now,
+			"updated_at":  now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("regrade request is already resolved")
+	}
+	request.Status = status
+	request.Reply = reply
+	request.ResolvedBy = &teacherID
+	request.ResolvedAt = &now
+	return nil
+}
+
+// reopen — бағаны өзгерту сәтсіз болса, сұраныс қайта ашылады.
+func (s *Service) reopen(id uint) {
+	s.db.Model(&models.RegradeRequest{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      models.RegradeStatusPending,
+			"reply":       "",
+			"resolved_by": nil,
+			"resolved_at": nil,
+		})
+}
+
+func (s *Service) teacherRequest(id, teacherID uint) (*models.RegradeRequest, *models.Assignment, error) {
+	var request models.RegradeRequest
+	if err := s.db.First(&request, id).Error; err != nil {
+		return nil, nil, errors.New("regrade request not found")
+	}
+	assignment, err := s.loadAssignment(request.AssignmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var owns int64
+	s.db.Table("courses").
+		Where("id = ? AND teacher_id = ? AND deleted_at IS NULL", assignment.CourseID, teacherID).
+		Count(&owns)
+	if owns == 0 {
+		return nil, nil, errors.New("regrade request not found")
+	}
+	if request.Status != models.RegradeStatusPending {
+		return nil, nil, errors.New("regrade request is already resolved")
+	}
+	request.AssignmentTitle = assignment.Title
+	return &request, assignment, nil
+}
+
+func (s *Service) loadAssignment(id uint) (*models.Assignment, error) {
+	var assignment models.Assignment
+	if err := s.db.First(&assignment, id).Error; err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	return &assignment, nil
+}
+
+// mask — анонимді тексерудегі тапсырмаларда студент аты бүркеншік атпен ауыстырылады.
+func (s *Service) mask(requests []models.RegradeRequest) {
+	byAssignment := map[uint][]uint{}
+	for _, r := range requests {
+		byAssignment[r.AssignmentID] = append(byAssignment[r.AssignmentID], r.StudentID)
+	}
+	for assignmentID, ids := range byAssignment {
+		labels, ok := s.anon.Labels(assignmentID, ids)
+		if !ok {
+			continue
+		}
+		for i := range requests {
+			if requests[i].AssignmentID == assignmentID {
+				requests[i].StudentName = labels[requests[i].StudentID]
+				requests[i].StudentID = 0
+			}
+		}
+	}
+}
+
+func (s *Service) notifyTeacher(assignment *models.Assignment, request *models.RegradeRequest) {
+	if s.hub == nil {
+		return
+	}
+	var teacherID uint
+	if err := s.db.Table("courses").Select("teacher_id").
+		Where("id = ?", assignment.CourseID).Scan(&teacherID).Error; err != nil || teacherID == 0 {
+		return
+	}
+	s.hub.SendToUser(teacherID, "regrade_requested", map[string]any{
+		"request_id":       request.ID,
+		"assignment_id":    assignment.ID,
+		"assignment_title": assignment.Title,
+		"criterion_id":     request.CriterionID,
+		"question_id":      request.QuestionID,
+	})
+}
+
+func (s *Service) notifyStudent(assignment *models.Assignment, request *models.RegradeRequest) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.SendToUser(request.StudentID, "regrade_resolved", map[string]any{
+		"request_id":       request.ID,
+		"assignment_id":    assignment.ID,
+		"assignment_title": assignment.Title,
+		"status":           request.Status,
+		"reply":            request.Reply,
+		"old_score":        request.OldScore,
+		"new_score":        request.NewScore,
+	})
+}
+
+func hasCriterion(assignment *models.Assignment, id int) bool {
+	for _, c := range models.ParseEssayCriteria(assignment.Criteria) {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQuestion — тест сұрағы бар ма (ID жоқ сұрақтар реті бойынша нөмірленеді)
+func hasQuestion(assignment *models.Assignment, id int) bool {
+	if assignment.Questions == "" || assignment.Questions == "null" {
+		return false
+	}
+	var questions []models.TestQuestion
+	if err := json.Unmarshal([]byte(assignment.Questions), &questions); err != nil {
+		return false
+	}
+	for i, q := range questions {
+		if q.ID == id || (q.ID == 0 && i+1 == id) {
+			return true
+		}
+	}
+	return false
+}
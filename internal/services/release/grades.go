@@ -0,0 +1,107 @@
+package release
+
+import (
+	"errors"
+	"time"
+
+	"rest-project/internal/models"
+)
+
+// SetGradePolicy — тапсырма бағаларын жариялау тәртібі.
+// manual/scheduled-ке ауысқанда бұрын жарияланған бағалар қайта жасырылады.
+func (s *Service) SetGradePolicy(assignmentID uint, mode string, releaseAt *time.Time) error {
+	var current models.Assignment
+	if err := s.db.Select("id", "grade_release_mode", "grades_release_at", "grades_released_at").
+		First(&current, assignmentID).Error; err != nil {
+		return errors.New("assignment not found")
+	}
+	updates := map[string]interface{}{"grade_release_mode": mode}
+	switch mode {
+	case models.GradeReleaseImmediate:
+		updates["grades_release_at"] = nil
+	case models.GradeReleaseManual:
+		updates["grades_release_at"] = nil
+		updates["grades_released_at"] = nil
+	case models.GradeReleaseScheduled:
+		if releaseAt == nil {
+			return errors.New("release_at is required for scheduled release")
+		}
+		updates["grades_release_at"] = *releaseAt
+		updates["grades_released_at"] = nil
+	default:
+		return errors.New("mode must be immediate, manual or scheduled")
+	}
+	if err := s.db.Model(&models.Assignment{}).Where("id = ?", assignmentID).Updates(updates).Error; err != nil {
+		return err
+	}
+	// жасырылған бағалар енді көрінеді — студенттерге хабарлау керек
+	if mode == models.GradeReleaseImmediate && !current.GradesVisible(time.Now()) {
+		_, err := s.notifyGrades(assignmentID)
+		return err
+	}
+	return nil
+}
+
+// ReleaseGrades — тапсырманың барлық бағаларын жариялап, студенттерге grade_updated жібереді.
+// Хабарланған студенттер санын қайтарады; бұрын жарияланған болса — 0.
+func (s *Service) ReleaseGrades(assignmentID uint) (int, error) {
+	// Шартты UPDATE — параллель шақыру бағаларды екі рет хабарламайды
+	res := s.db.Exec(`UPDATE assignments SET grades_released_at = NOW()
+		WHERE id = ? AND grades_released_at IS NULL AND grade_release_mode <> ?`,
+		assignmentID, models.GradeReleaseImmediate)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, nil
+	}
+	return s.notifyGrades(assignmentID)
+}
+
+// ReleaseDueGrades — grades_release_at уақыты келген тапсырмалардың бағаларын жариялайды.
+func (s *Service) ReleaseDueGrades() (int, error) {
+	var ids []uint
+	if err := s.db.Table("assignments").
+		Where("deleted_at IS NULL AND grade_release_mode = ?", models.GradeReleaseScheduled).
+		Where("grades_released_at IS NULL AND grades_release_at <= NOW()").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	released := 0
+	for _, id := range ids {
+		if _, err := s.ReleaseGrades(id); err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+func (s *Service) notifyGrades(assignmentID uint) (int, error) {
+	var rows []struct {
+		StudentID       uint    `gorm:"column:student_id"`
+		Score           float64 `gorm:"column:score"`
+		AssignmentTitle string  `gorm:"column:assignment_title"`
+		CourseTitle     string  `gorm:"column:course_title"`
+	}
+	if err := s.db.Table("grades g").
+		Select("g.student_id, g.score, a.title AS assignment_title, c.title AS course_title").
+		Joins("JOIN assignments a ON a.id = g.assignment_id").
+		Joins("JOIN courses c ON c.id = a.course_id").
+		Where("g.assignment_id = ? AND g.deleted_at IS NULL", assignmentID).
+		Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+	if s.hub == nil {
+		return len(rows), nil
+	}
+	for _, r := range rows {
+		s.hub.SendToUser(r.StudentID, "grade_updated", map[string]any{
+			"assignment_id":    assignmentID,
+			"assignment_title": r.AssignmentTitle,
+			"course_title":     r.CourseTitle,
+			"score":            r.Score,
+		})
+	}
+	return len(rows), nil
+}
@@ -0,0 +1,99 @@
+package release
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/services/notifier"
+)
+
+// Service — publish_at уақыты келген тапсырмаларды жариялап, студенттерге WS-хабарлама жібереді.
+// Бағаларды жариялау (grades.go) да осында: кейінге қалдырылған бағалар уақыты келгенде ашылады.
+type Service struct {
+	db  *gorm.DB
+	hub *notifier.Hub // optional
+}
+
+func NewService(db *gorm.DB, hub *notifier.Hub) *Service {
+	return &Service{db: db, hub: hub}
+}
+
+// Run — PublishDue және ReleaseDueGrades-ті interval сайын шақырады. Горутинада іске қосу керек.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.PublishDue(); err != nil {
+			log.Printf("[release] publish failed: %v", err)
+		}
+		if _, err := s.ReleaseDueGrades(); err != nil {
+			log.Printf("[release] grade release failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue — көрінетін, бірақ әлі жарияланбаған тапсырмаларды белгілейді.
+// Жарияланған тапсырмалар санын қайтарады.
+func (s *Service) PublishDue() (int, error) {
+	type dueRow struct {
+		ID          uint      `gorm:"column:id"`
+		Title       string    `gorm:"column:title"`
+		DueDate     time.Time `gorm:"column:due_date"`
+		CourseID    uint      `gorm:"column:course_id"`
+		CourseTitle string    `gorm:"column:course_title"`
+	}
+	var rows []dueRow
+	err := s.db.Raw(`
+		SELECT a.id, a.title, a.due_date, a.course_id, c.title AS course_title
+		FROM assignments a
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.deleted_at IS NULL
+		  AND a.published_at IS NULL
+		  AND a.is_draft = FALSE
+		  AND (a.publish_at IS NULL OR a.publish_at <= NOW())
+	`).Scan(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, row := range rows {
+		// Шартты UPDATE — параллель екі шақыру бір тапсырманы екі рет хабарламайды
+		res := s.db.Exec("UPDATE assignments SET published_at = NOW() WHERE id = ? AND published_at IS NULL", row.ID)
+		if res.Error != nil || res.RowsAffected == 0 {
+			continue
+		}
+		published++
+		s.notifyStudents(row.CourseID, map[string]any{
+			"assignment_id":    row.ID,
+			"assignment_title": row.Title,
+			"course_id":        row.CourseID,
+			"course_title":     row.CourseTitle,
+			"due_date":         row.DueDate,
+		})
+	}
+	return published, nil
+}
+
+func (s *Service) notifyStudents(courseID uint, payload map[string]any) {
+	if s.hub == nil {
+		return
+	}
+	var studentIDs []uint
+	if err := s.db.Table("course_students").
+		Where("course_id = ?", courseID).
+		Pluck("user_id", &studentIDs).Error; err != nil {
+		return
+	}
+	for _, id := range studentIDs {
+		s.hub.SendToUser(id, "assignment_published", payload)
+	}
+}
@@ -0,0 +1,407 @@
+package rubric
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+const (
+	maxCriteria    = 30
+	maxTitleLength = 200
+)
+
+// Service — рубрикалар кітапханасы: мұғалімнің рубрикалары және ұйымға ашылғандары,
+// нұсқалар, тапсырмаға тіркеу, жаңа нұсқаны бағаланбаған тапсырмаларға тарату.
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// RubricRequest — рубрика жасау/өзгерту. Критерийлер өзгерсе, жаңа нұсқа жасалады.
+type RubricRequest struct {
+	Title       string                  `json:"title" binding:"required"`
+	Description string                  `json:"description"`
+	IsPublic    bool                    `json:"is_public"`
+	Criteria    []models.EssayCriterion `json:"criteria" binding:"required"`
+	Note        string                  `json:"note"` // нұсқаға түсініктеме
+}
+
+// PushSkip — жаңа нұсқа қолданылмаған тапсырма және себебі
+type PushSkip struct {
+	AssignmentID uint   `json:"assignment_id"`
+	Reason       string `json:"reason"`
+}
+
+// PushResult — Push нәтижесі
+type PushResult struct {
+	Version int        `json:"version"`
+	Updated []uint     `json:"updated"`
+	Skipped []PushSkip `json:"skipped"`
+}
+
+// List — visibility: "mine" — өз рубрикалары, "public" — ұйымға ашылғандары, бос — екеуі де.
+func (s *Service) List(teacherID uint, visibility string) ([]models.RubricResponse, error) {
+	q := s.db.Model(&models.Rubric{})
+	switch visibility {
+	case "mine":
+		q = q.Where("owner_id = ?", teacherID)
+	case "public":
+		q = q.Where("is_public = ?", true)
+	default:
+		q = q.Where("owner_id = ? OR is_public = ?", teacherID, true)
+	}
+	var rubrics []models.Rubric
+	if err := q.Order("updated_at DESC, id DESC").Find(&rubrics).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.RubricResponse, 0, len(rubrics))
+	for i := range rubrics {
+		version, err := s.version(rubrics[i].ID, rubrics[i].CurrentVersion)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, toResponse(&rubrics[i], version, teacherID))
+	}
+	return out, nil
+}
+
+// Get — рубрика және оның нұсқасы (version 0 — ағымдағы).
+func (s *Service) Get(id, teacherID uint, version int) (*models.RubricResponse, error) {
+	rubric, err := s.getVisible(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if version <= 0 {
+		version = rubric.CurrentVersion
+	}
+	v, err := s.version(rubric.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(rubric, v, teacherID)
+	return &resp, nil
+}
+
+// Create — жаңа рубрика, 1-нұсқа.
+func (s *Service) Create(teacherID uint, req RubricRequest) (*models.RubricResponse, error) {
+	criteriaJSON, err := normalize(&req)
+	if err != nil {
+		return nil, err
+	}
+	rubric := &models.Rubric{
+		OwnerID:        teacherID,
+		Title:          req.Title,
+		Description:    req.Description,
+		IsPublic:       req.IsPublic,
+		CurrentVersion: 1,
+	}
+	version := &models.RubricVersion{Version: 1, Criteria: criteriaJSON, Note: req.Note, CreatedBy: &teacherID}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rubric).Error; err != nil {
+			return err
+		}
+		version.RubricID = rubric.ID
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(rubric, version, teacherID)
+	return &resp, nil
+}
+
+// Update — тек иесі. Критерийлер өзгерсе, жаңа нұсқа қосылады; бұрынғы нұсқалар
+// және оларға сілтейтін тапсырмалар өзгермейді.
+func (s *Service) Update(id, teacherID uint, req RubricRequest) (*models.RubricResponse, error) {
+	rubric, err := s.getOwned(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	criteriaJSON, err := normalize(&req)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.version(rubric.ID, rubric.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if current.Criteria != criteriaJSON {
+			current = &models.RubricVersion{
+				RubricID:  rubric.ID,
+				Version:   rubric.CurrentVersion + 1,
+				Criteria:  criteriaJSON,
+				Note:      req.Note,
+				CreatedBy: &teacherID,
+			}
+			if err := tx.Create(current).Error; err != nil {
+				return err
+			}
+			rubric.CurrentVersion = current.Version
+		}
+		rubric.Title = req.Title
+		rubric.Description = req.Description
+		rubric.IsPublic = req.IsPublic
+		return tx.Model(rubric).
+			Select("title", "description", "is_public", "current_version", "updated_at").
+			Updates(rubric).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(rubric, current, teacherID)
+	return &resp, nil
+}
+
+// Delete — рубриканы кітапханадан алып тастайды; тапсырмалардағы критерий көшірмелері қалады.
+func (s *Service) Delete(id, teacherID uint) error {
+	rubric, err := s.getOwned(id, teacherID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(rubric).Error
+}
+
+// Versions — нұсқалар тарихы, жаңасы бірінші.
+func (s *Service) Versions(id, teacherID uint) ([]models.RubricVersionResponse, error) {
+	if _, err := s.getVisible(id, teacherID); err != nil {
+		return nil, err
+	}
+	var versions []models.RubricVersion
+	if err := s.db.Where("rubric_id = ?", id).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.RubricVersionResponse, 0, len(versions))
+	for _, v := range versions {
+		out = append(out, models.RubricVersionResponse{
+			Version:   v.Version,
+			Note:      v.Note,
+			Criteria:  models.ParseEssayCriteria(v.Criteria),
+			CreatedBy: v.CreatedBy,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// Clone — өз немесе ашық рубриканың ағымдағы нұсқасын мұғалімнің жеке көшірмесіне айналдырады.
+func (s *Service) Clone(id, teacherID uint) (*models.RubricResponse, error) {
+	src, err := s.Get(id, teacherID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.Create(teacherID, RubricRequest{
+		Title:       src.Title + " (Copy)",
+		Description: src.Description,
+		Criteria:    src.Criteria,
+		Note:        "cloned from rubric",
+	})
+}
+
+// Export — рубрика нұсқасы JSON түрінде (version 0 — ағымдағы).
+func (s *Service) Export(id, teacherID uint, version int) (*models.RubricExport, error) {
+	rubric, err := s.Get(id, teacherID, version)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RubricExport{
+		Format:      models.RubricExportFormat,
+		Title:       rubric.Title,
+		Description: rubric.Description,
+		Version:     rubric.Version,
+		Criteria:    rubric.Criteria,
+	}, nil
+}
+
+// Import — экспортталған рубриканы мұғалімнің жеке рубрикасы ретінде жасайды.
+func (s *Service) Import(teacherID uint, data models.RubricExport) (*models.RubricResponse, error) {
+	if data.Format != "" && data.Format != models.RubricExportFormat {
+		return nil, errors.New("unsupported rubric format: " + data.Format)
+	}
+	return s.Create(teacherID, RubricRequest{
+		Title:       data.Title,
+		Description: data.Description,
+		Criteria:    data.Criteria,
+		Note:        "imported",
+	})
+}
+
+// Attach — рубрика нұсқасының критерийлерін тапсырмаға көшіреді (version 0 — ағымдағы).
+// Бағасы бар тапсырманың критерийлері өзгертілмейді.
+func (s *Service) Attach(assignmentID, teacherID, rubricID uint, version int) (*models.RubricResponse, error) {
+	rubric, err := s.Get(rubricID, teacherID, version)
+	if err != nil {
+		return nil, err
+	}
+	graded, err := s.hasGrades(assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if graded {
+		return nil, errors.New("assignment already has grades; rubric cannot be changed")
+	}
+	if err := s.apply(assignmentID, rubric.ID, rubric.Version, rubric.Criteria); err != nil {
+		return nil, err
+	}
+	return rubric, nil
+}
+
+// Detach — тапсырманы рубрикадан ажыратады; критерийлер тапсырмада қалады.
+func (s *Service) Detach(assignmentID uint) error {
+	return s.db.Model(&models.Assignment{}).
+		Where("id = ?", assignmentID).
+		Updates(map[string]interface{}{"rubric_id": nil, "rubric_version": nil}).Error
+}
+
+// Push — рубриканың ағымдағы нұсқасын мұғалімнің осы рубрикаға тіркелген тапсырмаларына
+// таратады. Бағасы бар тапсырмалар өткізіледі — оларда бағаланған нұсқа сақталады.
+func (s *Service) Push(rubricID, teacherID uint) (*PushResult, error) {
+	rubric, err := s.Get(rubricID, teacherID, 0)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	if err := s.db.Table("assignments a").
+		Joins("JOIN courses c ON c.id = a.course_id").
+		Where("c.teacher_id = ? AND a.deleted_at IS NULL", teacherID).
+		Where("a.rubric_id = ?", rubric.ID).
+		Where("a.rubric_version IS NULL OR a.rubric_version < ?", rubric.Version).
+		Order("a.id ASC").
+		Pluck("a.id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	result := &PushResult{Version: rubric.Version, Updated: []uint{}, Skipped: []PushSkip{}}
+	for _, id := range ids {
+		graded, err := s.hasGrades(id)
+		if err != nil {
+			return nil, err
+		}
+		if graded {
+			result.Skipped = append(result.Skipped, PushSkip{AssignmentID: id, Reason: "already graded"})
+			continue
+		}
+		if err := s.apply(id, rubric.ID, rubric.Version, rubric.Criteria); err != nil {
+			return nil, err
+		}
+		result.Updated = append(result.Updated, id)
+	}
+	return result, nil
+}
+
+func (s *Service) apply(assignmentID, rubricID uint, version int, criteria []models.EssayCriterion) error {
+	data, err := json.Marshal(criteria)
+	if err != nil {
+		return err
+	}
+	return s.db.Model(&models.Assignment{}).
+		Where("id = ?", assignmentID).
+		Updates(map[string]interface{}{
+			"criteria":       string(data),
+			"rubric_id":      rubricID,
+			"rubric_version": version,
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+func (s *Service) hasGrades(assignmentID uint) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.Grade{}).Where("assignment_id = ?", assignmentID).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *Service) version(rubricID uint, version int) (*models.RubricVersion, error) {
+	var v models.RubricVersion
+	if err := s.db.Where("rubric_id = ? AND version = ?", rubricID, version).First(&v).Error; err != nil {
+		return nil, errors.New("rubric version not found")
+	}
+	return &v, nil
+}
+
+func (s *Service) getVisible(id, teacherID uint) (*models.Rubric, error) {
+	var rubric models.Rubric
+	if err := s.db.First(&rubric, id).Error; err != nil {
+		return nil, errors.New("rubric not found")
+	}
+	if rubric.OwnerID != teacherID && !rubric.IsPublic {
+		return nil, errors.New("rubric not found")
+	}
+	return &rubric, nil
+}
+
+func (s *Service) getOwned(id, teacherID uint) (*models.Rubric, error) {
+	rubric, err := s.getVisible(id, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if rubric.OwnerID != teacherID {
+		return nil, errors.New("forbidden: rubric belongs to another teacher")
+	}
+	return rubric, nil
+}
+
+// normalize — атауы мен критерийлерді тексеріп, критерийлерді JSON түрінде қайтарады.
+// ID жоқ критерийлерге реттік нөмір беріледі.
+func normalize(req *RubricRequest) (string, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || len([]rune(req.Title)) > maxTitleLength {
+		return "", errors.New("rubric title must be 1-200 characters")
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Criteria) == 0 || len(req.Criteria) > maxCriteria {
+		return "", errors.New("rubric must have 1-30 criteria")
+	}
+	ids := map[int]bool{}
+	for i := range req.Criteria {
+		c := &req.Criteria[i]
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			return "", errors.New("criterion name is required")
+		}
+		if c.PointsMax() <= 0 {
+			return "", errors.New("criterion " + c.Name + ": maxPoints must be positive")
+		}
+		if c.ID == 0 {
+			c.ID = i + 1
+		}
+		if ids[c.ID] {
+			return "", errors.New("duplicate criterion id in rubric")
+		}
+		ids[c.ID] = true
+	}
+	if err := models.ValidateRubricLevels(req.Criteria); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(req.Criteria)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func toResponse(rubric *models.Rubric, version *models.RubricVersion, teacherID uint) models.RubricResponse {
+	return models.RubricResponse{
+		ID:             rubric.ID,
+		OwnerID:        rubric.OwnerID,
+		Title:          rubric.Title,
+		Description:    rubric.Description,
+		IsPublic:       rubric.IsPublic,
+		IsOwner:        rubric.OwnerID == teacherID,
+		CurrentVersion: rubric.CurrentVersion,
+		Version:        version.Version,
+		Criteria:       models.ParseEssayCriteria(version.Criteria),
+		CreatedAt:      rubric.CreatedAt,
+		UpdatedAt:      rubric.UpdatedAt,
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"rest-project/internal/models"
+)
+
+// maxCriterionCommentLen — критерий түсініктемесінің шегі (символ)
+const maxCriterionCommentLen = 2000
+
+// RubricView — тапсырма рубрикасы, жұмыстың ағымдағы бағасы (критерийлер бойынша)
+// және AI ұсынысы — мұғалім формасын алдын ала толтыру үшін.
+type RubricView struct {
+	SubmissionID uint                     `json:"submission_id"`
+	AssignmentID uint                     `json:"assignment_id"`
+	MaxScore     float64                  `json:"max_score"`
+	Criteria     []models.EssayCriterion  `json:"criteria"`
+	Grade        *models.Grade            `json:"grade,omitempty"`
+	AISuggestion *models.RubricSuggestion `json:"ai_suggestion,omitempty"`
+}
+
+// rubricBreakdown — мұғалімнің критерий бағаларын тексеріп, жалпы балды есептейді:
+// сумма / критерийлер максимумы * max_score. Әр критерий дәл бір рет бағалануы керек.
+func rubricBreakdown(assignment *models.Assignment, inputs []models.CriterionScoreInput) ([]models.GradeCriterionScore, float64, error) {
+	criteria := models.ParseEssayCriteria(assignment.Criteria)
+	if len(criteria) == 0 {
+		return nil, 0, errors.New("assignment has no rubric criteria")
+	}
+	if len(inputs) != len(criteria) {
+		return nil, 0, errors.New("every rubric criterion must be scored exactly once")
+	}
+
+	scored := map[int]bool{}
+	scores := make([]models.GradeCriterionScore, 0, len(criteria))
+	var sum, totalMax float64
+	for _, in := range inputs {
+		c, ok := matchCriterion(criteria, in)
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown criterion %d", in.CriterionID)
+		}
+		if scored[c.ID] {
+			return nil, 0, fmt.Errorf("criterion %q is scored twice", c.Name)
+		}
+		scored[c.ID] = true
+
+		score, err := criterionScore(c, in)
+		if err != nil {
+			return nil, 0, err
+		}
+		comment := strings.TrimSpace(in.Comment)
+		if len([]rune(comment)) > maxCriterionCommentLen {
+			return nil, 0, fmt.Errorf("criterion %q: comment is too long", c.Name)
+		}
+		scores = append(scores, models.GradeCriterionScore{
+			CriterionID:   c.ID,
+			AssignmentID:  assignment.ID,
+			CriterionName: c.Name,
+			Score:         score,
+			MaxScore:      c.PointsMax(),
+			Level:         strings.TrimSpace(in.Level),
+			Comment:       comment,
+		})
+		sum += score
+		totalMax += c.PointsMax()
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].CriterionID < scores[j].CriterionID })
+	return scores, rubricTotal(sum, totalMax, assignment.MaxScore), nil
+}
+
+// rubricSuggestion — AI ұсынысын рубрикаға келтіреді: белгісіз критерийлер өткізіледі,
+// балдар критерий шегіне дейін қысқартылады. Толық емес ұсынысқа да рұқсат бар.
+func rubricSuggestion(assignment *models.Assignment, inputs []models.CriterionScoreInput) ([]models.GradeCriterionScore, float64) {
+	criteria := models.ParseEssayCriteria(assignment.Criteria)
+	scored := map[int]bool{}
+	scores := []models.GradeCriterionScore{}
+	var sum, totalMax float64
+	for _, in := range inputs {
+		c, ok := matchCriterion(criteria, in)
+		if !ok || scored[c.ID] || in.Score == nil {
+			continue
+		}
+		scored[c.ID] = true
+		max := c.PointsMax()
+		score := math.Min(math.Max(*in.Score, 0), max)
+		scores = append(scores, models.GradeCriterionScore{
+			CriterionID:   c.ID,
+			AssignmentID:  assignment.ID,
+			CriterionName: c.Name,
+			Score:         score,
+			MaxScore:      max,
+			Level:         nearestLevel(c, score),
+			Comment:       strings.TrimSpace(in.Comment),
+		})
+		sum += score
+		totalMax += max
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].CriterionID < scores[j].CriterionID })
+	return scores, rubricTotal(sum, totalMax, assignment.MaxScore)
+}
+
+// SaveAIRubric — AI бағалаушының критерийлер бойынша нәтижесін жұмысқа сақтайды.
+// Баға қойылмайды: мұғалім ұсынысты өзі қарап, растайды немесе түзетеді.
+func (s *GradeService) SaveAIRubric(submissionID, teacherID uint, inputs []models.CriterionScoreInput, feedback string) error {
+	submission, assignment, err := s.teacherSubmission(submissionID, teacherID)
+	if err != nil {
+		return err
+	}
+	scores, total := rubricSuggestion(assignment, inputs)
+	if len(scores) == 0 {
+		return errors.New("ai result does not match assignment rubric")
+	}
+	data, err := json.Marshal(models.RubricSuggestion{
+		Criteria:  scores,
+		Score:     total,
+		Feedback:  strings.TrimSpace(feedback),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.submissionRepo.SetAIRubric(submission.ID, string(data))
+}
+
+// GetRubric — мұғалімге жұмыстың рубрикасы: критерийлер, қойылған баға және AI ұсынысы.
+func (s *GradeService) GetRubric(submissionID, teacherID uint) (*RubricView, error) {
+	submission, assignment, err := s.teacherSubmission(submissionID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	view := &RubricView{
+		SubmissionID: submission.ID,
+		AssignmentID: assignment.ID,
+		MaxScore:     assignment.MaxScore,
+		Criteria:     models.ParseEssayCriteria(assignment.Criteria),
+	}
+	if grade, err := s.repo.GetGradeByStudentAndAssignment(submission.StudentID, assignment.ID); err == nil {
+		view.Grade = grade
+	}
+	if submission.AIRubric != "" {
+		var suggestion models.RubricSuggestion
+		if err := json.Unmarshal([]byte(submission.AIRubric), &suggestion); err == nil {
+			view.AISuggestion = &suggestion
+		}
+	}
+	return view, nil
+}
+
+func (s *GradeService) teacherSubmission(submissionID, teacherID uint) (*models.AssignmentSubmission, *models.Assignment, error) {
+	submission, err := s.submissionRepo.GetByID(submissionID)
+	if err != nil {
+		return nil, nil, errors.New("submission not found")
+	}
+	assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID)
+	if err != nil {
+		return nil, nil, errors.New("assignment not found")
+	}
+	course, err := s.courseRepo.GetByID(assignment.CourseID)
+	if err != nil {
+		return nil, nil, errors.New("course not found")
+	}
+	if course.TeacherID != teacherID {
+		return nil, nil, errors.New("teacher is not assigned to this course")
+	}
+	return submission, assignment, nil
+}
+
+func matchCriterion(criteria []models.EssayCriterion, in models.CriterionScoreInput) (models.EssayCriterion, bool) {
+	for _, c := range criteria {
+		if in.CriterionID != 0 && c.ID == in.CriterionID {
+			return c, true
+		}
+	}
+	if in.CriterionID == 0 && in.Name != "" {
+		for _, c := range criteria {
+			if strings.EqualFold(strings.TrimSpace(c.Name), strings.TrimSpace(in.Name)) {
+				return c, true
+			}
+		}
+	}
+	return models.EssayCriterion{}, false
+}
+
+// criterionScore — берілген балл, болмаса таңдалған деңгейдің ұпайы.
+func criterionScore(c models.EssayCriterion, in models.CriterionScoreInput) (float64, error) {
+	if in.Level != "" && len(c.Levels) > 0 {
+		level, ok := c.FindLevel(in.Level)
+		if !ok {
+			return 0, fmt.Errorf("criterion %q: unknown level %q", c.Name, in.Level)
+		}
+		if in.Score == nil {
+			return level.Points, nil
+		}
+	}
+	if in.Score == nil {
+		return 0, fmt.Errorf("criterion %q: score or level is required", c.Name)
+	}
+	if *in.Score < 0 || *in.Score > c.PointsMax() {
+		return 0, fmt.Errorf("criterion %q: score must be between 0 and %g", c.Name, c.PointsMax())
+	}
+	return *in.Score, nil
+}
+
+// nearestLevel — балға ең жақын деңгей (деңгейлер болмаса бос).
+func nearestLevel(c models.EssayCriterion, score float64) string {
+	best := ""
+	diff := math.Inf(1)
+	for _, l := range c.Levels {
+		if d := math.Abs(l.Points - score); d < diff {
+			best, diff = l.Label, d
+		}
+	}
+	return best
+}
+
+func rubricTotal(sum, totalMax, maxScore float64) float64 {
+	if totalMax <= 0 {
+		return 0
+	}
+	return math.Round(sum/totalMax*maxScore*100) / 100
+}
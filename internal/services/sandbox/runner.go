@@ -0,0 +1,273 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Limits — ограничения одного запуска.
+type Limits struct {
+	Timeout     time.Duration // wall-clock
+	CPUSeconds  int
+	MemoryMB    int
+	OutputBytes int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		Timeout:     5 * time.Second,
+		CPUSeconds:  3,
+		MemoryMB:    256,
+		OutputBytes: 64 << 10,
+	}
+}
+
+// language — как запускать исходник. Аргументы могут содержать %d (лимит памяти в MB).
+type language struct {
+	fileName   string
+	command    []string
+	reservedMB int // виртуальная память сверх MemoryMB, которую рантайм резервирует при старте
+}
+
+var languages = map[string]language{
+	"python": {fileName: "main.py", command: []string{"python3", "-I", "main.py"}},
+	// node резервирует CodeRange и т.п.; кучу ограничивает --max-old-space-size
+	"javascript": {fileName: "main.js", command: []string{"node", "--max-old-space-size=%d", "main.js"}, reservedMB: 1024},
+}
+
+const (
+	defaultSandboxUID = 65534 // nobody
+	defaultMaxProcs   = 32
+	tmpfsSizeMB       = 16
+)
+
+// SupportedLanguages — список языков для валидации заданий.
+func SupportedLanguages() []string {
+	out := make([]string, 0, len(languages))
+	for name := range languages {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func IsSupported(lang string) bool {
+	_, ok := languages[lang]
+	return ok
+}
+
+// Result — результат одного запуска.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+}
+
+// Runner — запускает код студента отдельным процессом:
+// свои namespace (net, pid, mount, ipc, uts), корень только на чтение и приватный tmpfs в /tmp,
+// непривилегированный uid без capabilities, rlimit по CPU/памяти/процессам/файлам, таймаут
+// и пустое окружение.
+type Runner struct {
+	limits   Limits
+	unshare  string
+	setpriv  string
+	prlimit  string
+	uid      int
+	maxProcs int
+	userNS   bool // своя user namespace: сервер не root или у root нет CAP_SYS_ADMIN
+}
+
+// NewRunnerFromEnv — лимиты из SANDBOX_TIMEOUT_SEC / SANDBOX_CPU_SEC / SANDBOX_MEMORY_MB /
+// SANDBOX_MAX_PROCS, uid для запуска — SANDBOX_UID (65534).
+// Без unshare, setpriv и prlimit (util-linux) песочница недоступна.
+func NewRunnerFromEnv() (*Runner, error) {
+	limits := DefaultLimits()
+	if v, err := strconv.Atoi(os.Getenv("SANDBOX_TIMEOUT_SEC")); err == nil && v > 0 {
+		limits.Timeout = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("SANDBOX_CPU_SEC")); err == nil && v > 0 {
+		limits.CPUSeconds = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SANDBOX_MEMORY_MB")); err == nil && v > 0 {
+		limits.MemoryMB = v
+	}
+	r := &Runner{limits: limits, uid: defaultSandboxUID, maxProcs: defaultMaxProcs}
+	if v, err := strconv.Atoi(os.Getenv("SANDBOX_UID")); err == nil && v > 0 {
+		r.uid = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SANDBOX_MAX_PROCS")); err == nil && v > 0 {
+		r.maxProcs = v
+	}
+	for _, tool := range []struct {
+		name string
+		dst  *string
+	}{{"unshare", &r.unshare}, {"setpriv", &r.setpriv}, {"prlimit", &r.prlimit}} {
+		path, err := exec.LookPath(tool.name)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox: %s not found, isolation unavailable", tool.name)
+		}
+		*tool.dst = path
+	}
+
+	// Пробный запуск: в контейнере без CAP_SYS_ADMIN unshare --mount/--net от root
+	// падает с EPERM — тогда пробуем через user namespace, иначе автопроверку отключаем.
+	r.userNS = os.Geteuid() != 0
+	err := r.probe()
+	if err != nil && !r.userNS {
+		r.userNS = true
+		err = r.probe()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sandbox: isolation unavailable: %w", err)
+	}
+	return r, nil
+}
+
+// probe — запускает `true` с той же изоляцией, что и код студента.
+func (r *Runner) probe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := r.run(ctx, language{fileName: "probe", command: []string{"true"}}, "", "")
+	if err != nil {
+		return err
+	}
+	if res.ExitCode != 0 {
+		return fmt.Errorf("probe exited with %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+	}
+	return nil
+}
+
+func (r *Runner) Limits() Limits {
+	return r.limits
+}
+
+// Run — выполняет code на языке lang, подавая stdin.
+func (r *Runner) Run(ctx context.Context, lang, code, stdin string) (*Result, error) {
+	spec, ok := languages[lang]
+	if !ok {
+		return nil, fmt.Errorf("sandbox: unsupported language %q", lang)
+	}
+	return r.run(ctx, spec, code, stdin)
+}
+
+func (r *Runner) run(ctx context.Context, spec language, code, stdin string) (*Result, error) {
+	// Исходник передаётся через fd 3: внутри песочницы /tmp — свой tmpfs, файлов сервера не видно
+	src, err := os.CreateTemp("", "sandbox-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(src.Name())
+	defer src.Close()
+	if _, err := src.WriteString(code); err != nil {
+		return nil, err
+	}
+	if _, err := src.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	args := r.unshareArgs()
+	args = append(args, "sh", "-c", r.script(spec), "sandbox")
+	for _, a := range spec.command {
+		if strings.Contains(a, "%d") {
+			a = fmt.Sprintf(a, r.limits.MemoryMB)
+		}
+		args = append(args, a)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, r.limits.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, r.unshare, args...)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=/tmp", "LANG=C.UTF-8"}
+	cmd.ExtraFiles = []*os.File{src}
+	cmd.Stdin = bytes.NewBufferString(stdin)
+	stdout := &limitedBuffer{limit: r.limits.OutputBytes}
+	stderr := &limitedBuffer{limit: r.limits.OutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Своя группа процессов — по таймауту убиваем всё дерево
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	runErr := cmd.Run()
+	res := &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+		TimedOut: errors.Is(runCtx.Err(), context.DeadlineExceeded),
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) && !res.TimedOut {
+		return nil, runErr
+	}
+	return res, nil
+}
+
+// unshareArgs — namespace'ы запуска. Под root с CAP_SYS_ADMIN пользователь остаётся
+// хостовым и setpriv меняет uid на SANDBOX_UID; иначе нужна user namespace, а внутри неё
+// отображён только uid сервера — без capabilities он непривилегирован.
+func (r *Runner) unshareArgs() []string {
+	args := []string{"--mount", "--pid", "--fork", "--mount-proc", "--net", "--ipc", "--uts"}
+	if r.userNS {
+		args = append([]string{"--user", "--map-root-user"}, args...)
+	}
+	return args
+}
+
+// script — подготовка внутри namespace: корень read-only, /tmp — приватный tmpfs,
+// исходник из fd 3, затем rlimit'ы и сброс привилегий. Любая ошибка до exec прерывает запуск.
+func (r *Runner) script(spec language) string {
+	drop := fmt.Sprintf("%s --reuid=%d --regid=%d --clear-groups", r.setpriv, r.uid, r.uid)
+	if r.userNS {
+		drop = r.setpriv
+	}
+	drop += " --no-new-privs --inh-caps=-all --bounding-set=-all"
+	// RLIMIT_NPROC считается по реальному uid, поэтому ставится вместе со сменой uid
+	limits := fmt.Sprintf("%s --cpu=%d --fsize=%d --nproc=%d --as=%d --core=0",
+		r.prlimit, r.limits.CPUSeconds, r.limits.OutputBytes, r.maxProcs,
+		int64(r.limits.MemoryMB+spec.reservedMB)<<20)
+	return fmt.Sprintf("set -e; mount -o remount,bind,ro /; "+
+		"mount -t tmpfs -o size=%dm,mode=1777,nosuid,nodev tmpfs /tmp; cd /tmp; "+
+		"cat <&3 > %s; exec 3<&-; chmod 444 %s; "+
+		`exec %s -- %s "$@"`,
+		tmpfsSizeMB, spec.fileName, spec.fileName, limits, drop)
+}
+
+// limitedBuffer — пишет не больше limit байт, остальное отбрасывает.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if rest := b.limit - b.buf.Len(); rest > 0 {
+		if len(p) > rest {
+			b.buf.Write(p[:rest])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
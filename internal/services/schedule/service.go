@@ -162,6 +162,9 @@ func (s *Service) SyncDeadlines(teacherID uint) (int, error) {
 		WHERE c.teacher_id = ?
 		  AND a.due_date IS NOT NULL
 		  AND a.due_date > NOW()
+		  AND a.deleted_at IS NULL
+		  AND a.is_draft = FALSE
+		  AND (a.publish_at IS NULL OR a.publish_at <= NOW())
 	`, teacherID).Scan(&rows).Error
 	if err != nil {
 		return 0, err
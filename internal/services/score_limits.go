@@ -0,0 +1,27 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"rest-project/internal/models"
+)
+
+// validateMaxBonus — бонус балл теріс емес және max_score-тан аспайды.
+func validateMaxBonus(req *CreateAssignmentRequest) error {
+	if req.MaxBonus < 0 {
+		return errors.New("max_bonus cannot be negative")
+	}
+	if req.MaxBonus > req.MaxScore {
+		return errors.New("max_bonus cannot exceed max_score")
+	}
+	return nil
+}
+
+// validateScore — баға тапсырманың өз шкаласында: 0..max_score (+ max_bonus).
+func validateScore(assignment *models.Assignment, score float64) error {
+	if score < 0 || score > assignment.MaxAllowedScore() {
+		return fmt.Errorf("score must be between 0 and %g", assignment.MaxAllowedScore())
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 // StorageService — абстракция объектного хранилища (MinIO/S3-совместимое).
 type StorageService interface {
 	PutObject(ctx context.Context, objectName string, data io.Reader, size int64, contentType string) error
+	GetObject(ctx context.Context, objectName string) (io.ReadCloser, error)
 	PresignGet(ctx context.Context, objectName string, expiry time.Duration) (string, error)
 	RemoveObject(ctx context.Context, objectName string) error
 	GetBucket() string
@@ -99,6 +100,14 @@ func (s *MinioStorage) PutObject(ctx context.Context, objectName string, data io
 	return err
 }
 
+// GetObject — поток содержимого объекта; закрывает вызывающий.
+func (s *MinioStorage) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	if s == nil {
+		return nil, errors.New("storage disabled")
+	}
+	return s.client.GetObject(ctx, s.bucket, objectName, minio.GetObjectOptions{})
+}
+
 func (s *MinioStorage) PresignGet(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
 	if s == nil {
 		return "", errors.New("storage disabled")
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"rest-project/internal/models"
+)
+
+// AnnotationRequest — пікір: түбір үшін ауқым міндетті, жауапта тек мәтін
+type AnnotationRequest struct {
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+	Body        string `json:"body"`
+}
+
+// maxAnnotationLength — бір пікірдің ұзындығы (таңба)
+const maxAnnotationLength = 5000
+
+// ListAnnotationsForTeacher — жұмыстың барлық пікір тармақтары
+func (s *AssignmentSubmissionService) ListAnnotationsForTeacher(submissionID, teacherID uint) ([]models.SubmissionAnnotation, error) {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.annotationThreads(submissionID)
+}
+
+// CreateAnnotation — мұғалім мәтіннің [start, end) бөлігіне пікір қалдырады.
+// Хабарлама алатын студенттерді қайтарады (пікірлер әлі көрінбесе — бос).
+func (s *AssignmentSubmissionService) CreateAnnotation(submissionID, teacherID uint, req AnnotationRequest) (*models.SubmissionAnnotation, []uint, error) {
+	submission, err := s.getSubmissionForTeacher(submissionID, teacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := annotationBody(req.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	content := []rune(submission.Content)
+	if req.StartOffset < 0 || req.EndOffset <= req.StartOffset || req.EndOffset > len(content) {
+		return nil, nil, errors.New("invalid annotation range")
+	}
+
+	annotation := &models.SubmissionAnnotation{
+		SubmissionID:   submission.ID,
+		AuthorID:       teacherID,
+		RevisionNumber: s.latestRevisionNumber(submission.ID),
+		StartOffset:    req.StartOffset,
+		EndOffset:      req.EndOffset,
+		Quote:          string(content[req.StartOffset:req.EndOffset]),
+		Body:           body,
+	}
+	if err := s.annotationRepo.Create(annotation); err != nil {
+		return nil, nil, err
+	}
+
+	var recipients []uint
+	for _, studentID := range s.submissionStudentIDs(submission) {
+		if s.annotationsVisible(submission, studentID) {
+			recipients = append(recipients, studentID)
+		}
+	}
+	return s.reloadAnnotation(annotation), recipients, nil
+}
+
+// ReplyAsTeacher — мұғалімнің тармаққа жауабы
+func (s *AssignmentSubmissionService) ReplyAsTeacher(submissionID, teacherID, annotationID uint, body string) (*models.SubmissionAnnotation, []uint, error) {
+	submission, err := s.getSubmissionForTeacher(submissionID, teacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+	reply, err := s.createReply(submission, teacherID, annotationID, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var recipients []uint
+	for _, studentID := range s.submissionStudentIDs(submission) {
+		if s.annotationsVisible(submission, studentID) {
+			recipients = append(recipients, studentID)
+		}
+	}
+	return reply, recipients, nil
+}
+
+// DeleteAnnotation — мұғалім өз пікірін (түбір болса, бүкіл тармақты) өшіреді
+func (s *AssignmentSubmissionService) DeleteAnnotation(submissionID, teacherID, annotationID uint) error {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return err
+	}
+	annotation, err := s.annotationRepo.GetByID(annotationID)
+	if err != nil || annotation.SubmissionID != submissionID {
+		return errors.New("annotation not found")
+	}
+	if annotation.AuthorID != teacherID {
+		return errors.New("only the author can delete this comment")
+	}
+	return s.annotationRepo.Delete(annotation.ID)
+}
+
+// ListStudentAnnotations — баға жарияланғаннан кейін (немесе жұмыс қайтарылса) студентке көрінеді
+func (s *AssignmentSubmissionService) ListStudentAnnotations(assignmentID, studentID uint) ([]models.SubmissionAnnotation, error) {
+	submission, err := s.getStudentSubmissionRecord(assignmentID, studentID)
+	if err != nil || !s.annotationsVisible(submission, studentID) {
+		return []models.SubmissionAnnotation{}, nil
+	}
+	return s.annotationThreads(submission.ID)
+}
+
+// ReplyAsStudent — студенттің тармаққа жауабы; мұғалімге және топтастарға хабарланады
+func (s *AssignmentSubmissionService) ReplyAsStudent(assignmentID, studentID, annotationID uint, body string) (*models.SubmissionAnnotation, []uint, error) {
+	submission, err := s.getStudentSubmissionRecord(assignmentID, studentID)
+	if err != nil || !s.annotationsVisible(submission, studentID) {
+		return nil, nil, errors.New("annotation not found")
+	}
+	reply, err := s.createReply(submission, studentID, annotationID, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var recipients []uint
+	if assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID); err == nil {
+		if course, err := s.courseRepo.GetByID(assignment.CourseID); err == nil {
+			recipients = append(recipients, course.TeacherID)
+		}
+	}
+	for _, id := range s.submissionStudentIDs(submission) {
+		if id != studentID {
+			recipients = append(recipients, id)
+		}
+	}
+	return reply, recipients, nil
+}
+
+func (s *AssignmentSubmissionService) createReply(submission *models.AssignmentSubmission, authorID, annotationID uint, body string) (*models.SubmissionAnnotation, error) {
+	text, err := annotationBody(body)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.annotationRepo.GetByID(annotationID)
+	if err != nil || parent.SubmissionID != submission.ID {
+		return nil, errors.New("annotation not found")
+	}
+	// Жауапқа жауап — сол тармақтың түбіріне тіркеледі
+	rootID := parent.ID
+	if parent.ParentID != nil {
+		rootID = *parent.ParentID
+	}
+
+	reply := &models.SubmissionAnnotation{
+		SubmissionID: submission.ID,
+		ParentID:     &rootID,
+		AuthorID:     authorID,
+		Body:         text,
+	}
+	if err := s.annotationRepo.Create(reply); err != nil {
+		return nil, err
+	}
+	return s.reloadAnnotation(reply), nil
+}
+
+// annotationsVisible — студент пікірлерді баға жарияланғанда немесе жұмыс қайтарылғанда көреді
+func (s *AssignmentSubmissionService) annotationsVisible(submission *models.AssignmentSubmission, studentID uint) bool {
+	if submission.Status == models.SubmissionStatusReturned {
+		return true
+	}
+	assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID)
+	if err != nil {
+		return false
+	}
+	return s.visibleGrade(assignment, studentID) != nil
+}
+
+// annotationThreads — тегіс тізімді түбір + жауаптар құрылымына жинайды
+func (s *AssignmentSubmissionService) annotationThreads(submissionID uint) ([]models.SubmissionAnnotation, error) {
+	all, err := s.annotationRepo.GetBySubmissionID(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	replies := make(map[uint][]models.SubmissionAnnotation)
+	for _, a := range all {
+		if a.ParentID != nil {
+			replies[*a.ParentID] = append(replies[*a.ParentID], a)
+		}
+	}
+	threads := make([]models.SubmissionAnnotation, 0, len(all))
+	for _, a := range all {
+		if a.ParentID == nil {
+			a.Replies = replies[a.ID]
+			threads = append(threads, a)
+		}
+	}
+	return threads, nil
+}
+
+func (s *AssignmentSubmissionService) attachAnnotations(resp *models.AssignmentSubmissionResponse, submission *models.AssignmentSubmission, studentID uint) {
+	if !s.annotationsVisible(submission, studentID) {
+		return
+	}
+	if threads, err := s.annotationThreads(submission.ID); err == nil {
+		resp.Annotations = threads
+	}
+}
+
+// reloadAnnotation — автор атымен қайта оқу; сәтсіз болса, бастапқы жазба
+func (s *AssignmentSubmissionService) reloadAnnotation(annotation *models.SubmissionAnnotation) *models.SubmissionAnnotation {
+	if loaded, err := s.annotationRepo.GetByID(annotation.ID); err == nil {
+		return loaded
+	}
+	return annotation
+}
+
+func annotationBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("comment body is required")
+	}
+	if len([]rune(body)) > maxAnnotationLength {
+		return "", errors.New("comment is too long")
+	}
+	return body, nil
+}
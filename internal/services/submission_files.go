@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+// maxSubmissionFileSize — жүйелік шек, тапсырмада max_file_size_mb көрсетілмесе
+const maxSubmissionFileSize = 25 << 20 // 25 MB
+
+// AttachmentTargetSubmission — студент жұмысына тіркелген файл
+const AttachmentTargetSubmission = "submission"
+
+// normalizeFileSettings — файл баптауларын тексереді, кеңейтімдерді ".pdf,.docx" түріне келтіреді.
+func normalizeFileSettings(req *CreateAssignmentRequest) error {
+	if req.MaxFileSizeMB < 0 || int64(req.MaxFileSizeMB)<<20 > maxSubmissionFileSize {
+		return errors.New("max_file_size_mb must be between 0 and 25")
+	}
+	var exts []string
+	for _, ext := range strings.Split(req.AllowedExtensions, ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+	req.AllowedExtensions = strings.Join(exts, ",")
+	if req.Type != string(models.AssignmentTypeEssay) {
+		req.AllowAttachments = false
+	}
+	return nil
+}
+
+// PrepareFileUpload — файлды тексереді және ол тіркелетін черновикті қайтарады (жоқ болса жасайды).
+func (s *AssignmentSubmissionService) PrepareFileUpload(assignmentID, studentID uint, filename string, size int64) (*models.AssignmentSubmission, error) {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if !assignment.AcceptsFiles() {
+		return nil, errors.New("assignment does not accept files")
+	}
+	if s.getGrade(studentID, assignmentID) != nil {
+		return nil, errors.New("graded submission cannot be changed")
+	}
+	if err := validateSubmissionFile(assignment, filename, size); err != nil {
+		return nil, err
+	}
+
+	existing, groupID, lookupErr := s.lookupSubmission(assignment, studentID)
+	if lookupErr == nil {
+		if !isEditable(existing) {
+			return nil, errors.New("submitted assignment cannot be changed")
+		}
+		return existing, nil
+	}
+	if !errors.Is(lookupErr, gorm.ErrRecordNotFound) {
+		return nil, lookupErr
+	}
+
+	submission := &models.AssignmentSubmission{
+		StudentID:    studentID,
+		AssignmentID: assignmentID,
+		Status:       models.SubmissionStatusDraft,
+		GroupID:      groupID,
+	}
+	if err := s.repo.Create(submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// AddSubmissionFile — жүктелген файлды жұмысқа байланыстырады.
+func (s *AssignmentSubmissionService) AddSubmissionFile(submission *models.AssignmentSubmission, attachment *models.Attachment) error {
+	targetID := submission.ID
+	attachment.TargetType = AttachmentTargetSubmission
+	attachment.TargetID = &targetID
+	return s.attachmentRepo.Create(attachment)
+}
+
+// ListStudentFiles — студенттің (немесе тобының) жұмысына тіркелген файлдар
+func (s *AssignmentSubmissionService) ListStudentFiles(assignmentID, studentID uint) ([]models.Attachment, error) {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	submission, _, err := s.lookupSubmission(assignment, studentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errNoGroup) {
+		return []models.Attachment{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.GetByTarget(AttachmentTargetSubmission, submission.ID)
+}
+
+// RemoveStudentFile — черновиктен файлды өшіреді; storage-тан өшіру үшін жазбаны қайтарады.
+func (s *AssignmentSubmissionService) RemoveStudentFile(assignmentID, studentID, fileID uint) (*models.Attachment, error) {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	submission, _, err := s.lookupSubmission(assignment, studentID)
+	if err != nil {
+		return nil, errors.New("file not found")
+	}
+	if !isEditable(submission) || s.getGrade(studentID, assignmentID) != nil {
+		return nil, errors.New("submitted assignment cannot be changed")
+	}
+	attachment, err := s.attachmentRepo.GetByID(fileID)
+	if err != nil || attachment.TargetType != AttachmentTargetSubmission ||
+		attachment.TargetID == nil || *attachment.TargetID != submission.ID {
+		return nil, errors.New("file not found")
+	}
+	if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ListSubmissionFilesForTeacher — курс мұғаліміне ғана
+func (s *AssignmentSubmissionService) ListSubmissionFilesForTeacher(submissionID, teacherID uint) ([]models.Attachment, error) {
+	submission, err := s.getSubmissionForTeacher(submissionID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.GetByTarget(AttachmentTargetSubmission, submission.ID)
+}
+
+func (s *AssignmentSubmissionService) countFiles(submission *models.AssignmentSubmission) int {
+	if submission == nil || submission.ID == 0 {
+		return 0
+	}
+	files, err := s.attachmentRepo.GetByTarget(AttachmentTargetSubmission, submission.ID)
+	if err != nil {
+		return 0
+	}
+	return len(files)
+}
+
+func (s *AssignmentSubmissionService) attachFiles(resp *models.AssignmentSubmissionResponse, assignment *models.Assignment) {
+	if !assignment.AcceptsFiles() || resp.ID == 0 {
+		return
+	}
+	if files, err := s.attachmentRepo.GetByTarget(AttachmentTargetSubmission, resp.ID); err == nil {
+		resp.Files = files
+	}
+}
+
+// UploadSizeLimit — тапсырманың файл шегі (байт); handler multipart денесін осымен шектейді.
+func (s *AssignmentSubmissionService) UploadSizeLimit(assignmentID, studentID uint) (int64, error) {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
+		return 0, err
+	}
+	return fileSizeLimit(assignment), nil
+}
+
+func fileSizeLimit(assignment *models.Assignment) int64 {
+	if assignment.MaxFileSizeMB > 0 {
+		return int64(assignment.MaxFileSizeMB) << 20
+	}
+	return maxSubmissionFileSize
+}
+
+func validateSubmissionFile(assignment *models.Assignment, filename string, size int64) error {
+	limit := fileSizeLimit(assignment)
+	if size > limit {
+		return fmt.Errorf("file too large (max %d MB)", limit>>20)
+	}
+	if assignment.AllowedExtensions == "" {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, allowed := range strings.Split(assignment.AllowedExtensions, ",") {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("file type is not allowed (allowed: %s)", assignment.AllowedExtensions)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"rest-project/internal/models"
+)
+
+// ReturnSubmissionRequest — жұмысты қайта өңдеуге қайтару
+type ReturnSubmissionRequest struct {
+	Comment string    `json:"comment"`
+	DueDate time.Time `json:"due_date"`
+}
+
+// ReturnSubmission — мұғалім тапсырылған жұмысты пікірмен және жаңа мерзіммен қайтарады.
+// Хабарлама алатын студенттерді (топ мүшелерін) қайтарады.
+func (s *AssignmentSubmissionService) ReturnSubmission(submissionID, teacherID uint, req ReturnSubmissionRequest) (*models.SubmissionReturn, []uint, error) {
+	submission, err := s.getSubmissionForTeacher(submissionID, teacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID)
+	if err != nil {
+		return nil, nil, errors.New("assignment not found")
+	}
+	if assignment.Type == string(models.AssignmentTypeTest) {
+		return nil, nil, errors.New("test submissions cannot be returned")
+	}
+	switch submission.Status {
+	case models.SubmissionStatusSubmitted, models.SubmissionStatusLate:
+	case models.SubmissionStatusReturned:
+		return nil, nil, errors.New("submission has already been returned")
+	default:
+		return nil, nil, errors.New("only submitted work can be returned")
+	}
+	if !req.DueDate.After(time.Now()) {
+		return nil, nil, errors.New("due_date must be in the future")
+	}
+
+	studentIDs := s.submissionStudentIDs(submission)
+	for _, studentID := range studentIDs {
+		if s.getGrade(studentID, assignment.ID) != nil {
+			return nil, nil, errors.New("graded submission cannot be returned")
+		}
+	}
+
+	ret := &models.SubmissionReturn{
+		SubmissionID:   submission.ID,
+		Round:          submission.Round,
+		TeacherID:      teacherID,
+		Comment:        req.Comment,
+		DueDate:        req.DueDate,
+		RevisionNumber: s.latestRevisionNumber(submission.ID),
+	}
+	if err := s.returnRepo.Create(ret); err != nil {
+		return nil, nil, err
+	}
+
+	dueDate := req.DueDate
+	submission.Status = models.SubmissionStatusReturned
+	submission.ReturnDueDate = &dueDate
+	if err := s.repo.Update(submission.ID, submission); err != nil {
+		return nil, nil, err
+	}
+	return ret, studentIDs, nil
+}
+
+// ListReturnsForTeacher — жұмыстың барлық қайтару раундтары
+func (s *AssignmentSubmissionService) ListReturnsForTeacher(submissionID, teacherID uint) ([]models.SubmissionReturn, error) {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.returnRepo.GetBySubmissionID(submissionID)
+}
+
+func (s *AssignmentSubmissionService) attachReturns(resp *models.AssignmentSubmissionResponse) {
+	if resp.ID == 0 {
+		return
+	}
+	returns, err := s.returnRepo.GetBySubmissionID(resp.ID)
+	if err != nil {
+		return
+	}
+	resp.Returns = returns
+}
+
+// submissionStudentIDs — жұмыс иелері: жеке студент немесе топтың барлық мүшелері
+func (s *AssignmentSubmissionService) submissionStudentIDs(submission *models.AssignmentSubmission) []uint {
+	studentIDs := []uint{submission.StudentID}
+	if submission.GroupID != nil {
+		if group, err := s.groupRepo.GetByID(*submission.GroupID); err == nil {
+			studentIDs = studentIDs[:0]
+			for _, m := range group.Members {
+				studentIDs = append(studentIDs, m.StudentID)
+			}
+		}
+	}
+	return studentIDs
+}
+
+// isEditable — студент жұмысты өзгерте алады: черновик немесе қайтарылған
+func isEditable(submission *models.AssignmentSubmission) bool {
+	return submission.Status == models.SubmissionStatusDraft ||
+		submission.Status == models.SubmissionStatusReturned
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"rest-project/internal/models"
+)
+
+// maxDiffLines — Myers уақыты O((n+m)·D); өте ұзын мәтіндерді шектейміз
+const maxDiffLines = 3000
+
+// sessionGap — осыдан ұзақ үзіліс жаңа жазу сессиясы саналады
+const sessionGap = 30 * time.Minute
+
+// recordRevision — сақталған күйді өзгермейтін нұсқа ретінде жазады.
+func (s *AssignmentSubmissionService) recordRevision(submission *models.AssignmentSubmission, authorID uint, kind string) (*models.SubmissionRevision, error) {
+	revision := &models.SubmissionRevision{
+		SubmissionID: submission.ID,
+		AuthorID:     authorID,
+		Kind:         kind,
+		Content:      submission.Content,
+		Answers:      submission.Answers,
+		WordCount:    submission.WordCount,
+	}
+	if err := s.revisionRepo.Create(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// latestRevisionNumber — бағаға тіркелетін соңғы нұсқа нөмірі
+func (s *AssignmentSubmissionService) latestRevisionNumber(submissionID uint) *int {
+	revision, err := s.revisionRepo.GetLatest(submissionID)
+	if err != nil {
+		return nil
+	}
+	return &revision.Number
+}
+
+// ListStudentRevisions — студенттің (тобының) жұмыс нұсқалары, мәтінсіз
+func (s *AssignmentSubmissionService) ListStudentRevisions(assignmentID, studentID uint) ([]models.SubmissionRevision, error) {
+	submission, err := s.getStudentSubmissionRecord(assignmentID, studentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []models.SubmissionRevision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.listRevisions(submission.ID)
+}
+
+func (s *AssignmentSubmissionService) GetStudentRevision(assignmentID, studentID uint, number int) (*models.SubmissionRevision, error) {
+	submission, err := s.getStudentSubmissionRecord(assignmentID, studentID)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return s.getRevision(submission.ID, number)
+}
+
+func (s *AssignmentSubmissionService) DiffStudentRevisions(assignmentID, studentID uint, from, to int) (*models.RevisionDiff, error) {
+	submission, err := s.getStudentSubmissionRecord(assignmentID, studentID)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return s.diffRevisions(submission.ID, from, to)
+}
+
+// ListRevisionsForTeacher — курс мұғаліміне
+func (s *AssignmentSubmissionService) ListRevisionsForTeacher(submissionID, teacherID uint) ([]models.SubmissionRevision, error) {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.listRevisions(submissionID)
+}
+
+func (s *AssignmentSubmissionService) GetRevisionForTeacher(submissionID, teacherID uint, number int) (*models.SubmissionRevision, error) {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.getRevision(submissionID, number)
+}
+
+func (s *AssignmentSubmissionService) DiffRevisionsForTeacher(submissionID, teacherID uint, from, to int) (*models.RevisionDiff, error) {
+	if _, err := s.getSubmissionForTeacher(submissionID, teacherID); err != nil {
+		return nil, err
+	}
+	return s.diffRevisions(submissionID, from, to)
+}
+
+// WritingTimeline — уақыт бойынша сөз саны және күдікті «секірулер».
+func (s *AssignmentSubmissionService) WritingTimeline(submissionID, teacherID uint) (*models.WritingTimeline, error) {
+	submission, err := s.getSubmissionForTeacher(submissionID, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.revisionRepo.GetBySubmissionID(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	return buildTimeline(submission, revisions), nil
+}
+
+func (s *AssignmentSubmissionService) getStudentSubmissionRecord(assignmentID, studentID uint) (*models.AssignmentSubmission, error) {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	submission, _, err := s.lookupSubmission(assignment, studentID)
+	if errors.Is(err, errNoGroup) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return submission, err
+}
+
+func (s *AssignmentSubmissionService) getSubmissionForTeacher(submissionID, teacherID uint) (*models.AssignmentSubmission, error) {
+	submission, err := s.repo.GetByID(submissionID)
+	if err != nil {
+		return nil, errors.New("submission not found")
+	}
+	assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID)
+	if err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	course, err := s.courseRepo.GetByID(assignment.CourseID)
+	if err != nil {
+		return nil, errors.New("course not found")
+	}
+	if course.TeacherID != teacherID {
+		return nil, errors.New("teacher is not assigned to this course")
+	}
+	return submission, nil
+}
+
+func (s *AssignmentSubmissionService) listRevisions(submissionID uint) ([]models.SubmissionRevision, error) {
+	revisions, err := s.revisionRepo.GetBySubmissionID(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		revisions[i].Content = ""
+	}
+	return revisions, nil
+}
+
+func (s *AssignmentSubmissionService) getRevision(submissionID uint, number int) (*models.SubmissionRevision, error) {
+	revision, err := s.revisionRepo.GetByNumber(submissionID, number)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return revision, nil
+}
+
+func (s *AssignmentSubmissionService) diffRevisions(submissionID uint, from, to int) (*models.RevisionDiff, error) {
+	var oldText string
+	var oldWords int
+	if from > 0 {
+		older, err := s.getRevision(submissionID, from)
+		if err != nil {
+			return nil, err
+		}
+		oldText, oldWords = older.Content, older.WordCount
+	}
+	newer, err := s.getRevision(submissionID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := diffLines(oldText, newer.Content)
+	if err != nil {
+		return nil, err
+	}
+	diff := &models.RevisionDiff{
+		From:       from,
+		To:         to,
+		WordsDelta: newer.WordCount - oldWords,
+		Lines:      lines,
+	}
+	for _, l := range lines {
+		switch l.Op {
+		case "insert":
+			diff.LinesAdded++
+		case "delete":
+			diff.LinesRemoved++
+		}
+	}
+	return diff, nil
+}
+
+// diffLines — жолдар бойынша Myers diff (сызықтық жады, O((N+M)·D) уақыт).
+func diffLines(oldText, newText string) ([]models.DiffLine, error) {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return nil, errors.New("text is too long to diff")
+	}
+
+	// Жолдарды сандарға айналдырамыз — салыстыру арзандайды
+	ids := make(map[string]int, len(a)+len(b))
+	toIDs := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	d := &lineDiffer{a: a, b: b, ai: toIDs(a), bi: toIDs(b), out: make([]models.DiffLine, 0, len(a)+len(b))}
+	n := len(a) + len(b)
+	d.vf = make([]int, n+4)
+	d.vb = make([]int, n+4)
+	d.diff(0, len(a), 0, len(b))
+	return d.out, nil
+}
+
+type lineDiffer struct {
+	a, b   []string
+	ai, bi []int
+	vf, vb []int // алға/артқа жүрістің диагональдар бойынша шекаралары
+	out    []models.DiffLine
+}
+
+// diff — a[a0:a1] мен b[b0:b1]: ортақ басы/соңын алып, қалғанын ортаңғы snake бойынша бөледі.
+func (d *lineDiffer) diff(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.ai[a0] == d.bi[b0] {
+		d.out = append(d.out, models.DiffLine{Op: "equal", Text: d.a[a0]})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && d.ai[a1-suffix-1] == d.bi[b1-suffix-1] {
+		suffix++
+	}
+	a1 -= suffix
+	b1 -= suffix
+
+	switch {
+	case a0 == a1:
+		for j := b0; j < b1; j++ {
+			d.out = append(d.out, models.DiffLine{Op: "insert", Text: d.b[j]})
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			d.out = append(d.out, models.DiffLine{Op: "delete", Text: d.a[i]})
+		}
+	default:
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.diff(a0, x, b0, y)
+		for i := x; i < u; i++ {
+			d.out = append(d.out, models.DiffLine{Op: "equal", Text: d.a[i]})
+		}
+		d.diff(u, a1, v, b1)
+	}
+
+	for i := a1; i < a1+suffix; i++ {
+		d.out = append(d.out, models.DiffLine{Op: "equal", Text: d.a[i]})
+	}
+}
+
+// middleSnake — ең қысқа түзету жолының ортасындағы snake (x,y)→(u,v), абсолют индекстермен.
+func (d *lineDiffer) middleSnake(a0, a1, b0, b1 int) (int, int, int, int) {
+	a, b := d.ai[a0:a1], d.bi[b0:b1]
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	off := limit + 1
+	vf, vb := d.vf[:2*limit+3], d.vb[:2*limit+3]
+	vf[off+1], vb[off+1] = 0, 0
+
+	for k := 0; k <= limit; k++ {
+		for diag := -k; diag <= k; diag += 2 {
+			var x int
+			if diag == -k || (diag != k && vf[off+diag-1] < vf[off+diag+1]) {
+				x = vf[off+diag+1]
+			} else {
+				x = vf[off+diag-1] + 1
+			}
+			y := x - diag
+			sx, sy := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[off+diag] = x
+			if odd && diag >= delta-(k-1) && diag <= delta+(k-1) && x+vb[off+delta-diag] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		for diag := -k; diag <= k; diag += 2 {
+			var x int
+			if diag == -k || (diag != k && vb[off+diag-1] < vb[off+diag+1]) {
+				x = vb[off+diag+1]
+			} else {
+				x = vb[off+diag-1] + 1
+			}
+			y := x - diag
+			sx, sy := x, y
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			vb[off+diag] = x
+			if !odd && delta-diag >= -k && delta-diag <= k && x+vf[off+delta-diag] >= n {
+				return a0 + n - x, b0 + m - y, a0 + n - sx, b0 + m - sy
+			}
+		}
+	}
+	// Қол жетпейді: D ≤ n+m әрқашан табылады
+	return a0, b0, a1, b1
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+func buildTimeline(submission *models.AssignmentSubmission, revisions []models.SubmissionRevision) *models.WritingTimeline {
+	timeline := &models.WritingTimeline{
+		SubmissionID:   submission.ID,
+		Revisions:      len(revisions),
+		SubmittedAt:    submission.SubmittedAt,
+		FinalWordCount: submission.WordCount,
+		Points:         make([]models.TimelinePoint, 0, len(revisions)),
+	}
+	if len(revisions) == 0 {
+		return timeline
+	}
+	first := revisions[0].CreatedAt
+	timeline.FirstSavedAt = &first
+
+	end := revisions[len(revisions)-1].CreatedAt
+	if submission.SubmittedAt != nil {
+		end = *submission.SubmittedAt
+	}
+
+	prevWords := 0
+	var prevAt time.Time
+	for i, r := range revisions {
+		delta := r.WordCount - prevWords
+		point := models.TimelinePoint{
+			Number:    r.Number,
+			Kind:      r.Kind,
+			AuthorID:  r.AuthorID,
+			At:        r.CreatedAt,
+			WordCount: r.WordCount,
+		}
+		if delta > 0 {
+			point.WordsAdded = delta
+		} else {
+			point.WordsRemoved = -delta
+		}
+		timeline.Points = append(timeline.Points, point)
+
+		if point.WordsAdded > timeline.MaxJumpWords {
+			at := r.CreatedAt
+			timeline.MaxJumpWords = point.WordsAdded
+			timeline.MaxJumpAt = &at
+		}
+		if !r.CreatedAt.Before(end.Add(-time.Hour)) && !r.CreatedAt.After(end) {
+			timeline.WordsLastHour += point.WordsAdded
+		}
+		if i == 0 || r.CreatedAt.Sub(prevAt) > sessionGap {
+			timeline.WritingSessions++
+		}
+		prevWords = r.WordCount
+		prevAt = r.CreatedAt
+	}
+	if timeline.FinalWordCount > 0 {
+		timeline.MaxJumpShare = float64(timeline.MaxJumpWords) / float64(timeline.FinalWordCount)
+		timeline.MaxJumpShare = float64(int(timeline.MaxJumpShare*100+0.5)) / 100
+	}
+	return timeline
+}
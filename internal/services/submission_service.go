@@ -1,15 +1,18 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"strings"
 	"time"
 
 	"rest-project/internal/models"
 	"rest-project/internal/repository"
+	"rest-project/internal/services/queue"
 
 	"gorm.io/gorm"
 )
@@ -20,8 +23,26 @@ type AssignmentSubmissionService struct {
 	courseRepo     repository.CourseRepository
 	userRepo       repository.UserRepository
 	gradeRepo      repository.GradeRepository
+	extensionRepo  repository.AssignmentExtensionRepository
+	groupRepo      repository.AssignmentGroupRepository
+	attachmentRepo repository.AttachmentRepository
+	revisionRepo   repository.SubmissionRevisionRepository
+	returnRepo     repository.SubmissionReturnRepository
+	annotationRepo repository.SubmissionAnnotationRepository
+	queue          *queue.Queue // optional — автотексеру коды
 }
 
+// CodeGradeJobType — code тапсырмасын sandbox-та тексеру
+const CodeGradeJobType = "code_grade"
+
+// CodeGradePayload — code_grade тапсырмасының payload-ы
+type CodeGradePayload struct {
+	SubmissionID uint `json:"submission_id"`
+}
+
+// errNoGroup — топтық тапсырмада студент әлі ешбір топта емес.
+var errNoGroup = errors.New("student must join a group for this assignment")
+
 type AssignmentSubmissionRequest struct {
 	Content string              `json:"content"`
 	Answers []models.TestAnswer `json:"answers"`
@@ -33,6 +54,12 @@ func NewAssignmentSubmissionService(
 	courseRepo repository.CourseRepository,
 	userRepo repository.UserRepository,
 	gradeRepo repository.GradeRepository,
+	extensionRepo repository.AssignmentExtensionRepository,
+	groupRepo repository.AssignmentGroupRepository,
+	attachmentRepo repository.AttachmentRepository,
+	revisionRepo repository.SubmissionRevisionRepository,
+	returnRepo repository.SubmissionReturnRepository,
+	annotationRepo repository.SubmissionAnnotationRepository,
 ) *AssignmentSubmissionService {
 	return &AssignmentSubmissionService{
 		repo:           submissionRepo,
@@ -40,18 +67,29 @@ func NewAssignmentSubmissionService(
 		courseRepo:     courseRepo,
 		userRepo:       userRepo,
 		gradeRepo:      gradeRepo,
+		extensionRepo:  extensionRepo,
+		groupRepo:      groupRepo,
+		attachmentRepo: attachmentRepo,
+		revisionRepo:   revisionRepo,
+		returnRepo:     returnRepo,
+		annotationRepo: annotationRepo,
 	}
 }
 
+// SetQueue — code тапсырмаларын автотексеруге қосады (опционально).
+func (s *AssignmentSubmissionService) SetQueue(q *queue.Queue) {
+	s.queue = q
+}
+
 func (s *AssignmentSubmissionService) GetStudentSubmission(assignmentID, studentID uint) (*models.AssignmentSubmissionResponse, error) {
 	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
 	if err != nil {
 		return nil, err
 	}
 
-	grade := s.getGrade(studentID, assignmentID)
-	submission, err := s.repo.GetByStudentAndAssignment(studentID, assignmentID)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
+	grade := s.visibleGrade(assignment, studentID)
+	submission, _, err := s.lookupSubmission(assignment, studentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errNoGroup) {
 		resp := &models.AssignmentSubmissionResponse{
 			StudentID:    studentID,
 			AssignmentID: assignmentID,
@@ -66,23 +104,31 @@ func (s *AssignmentSubmissionService) GetStudentSubmission(assignmentID, student
 	}
 
 	resp := toSubmissionResponse(submission, grade)
+	if !assignment.GradesVisible(time.Now()) {
+		// автотест нәтижелері — баға сияқты, жарияланғанша жасырын
+		resp.TestResults = nil
+	}
 	s.attachTestReviewIfGraded(resp, assignment)
+	s.attachFiles(resp, assignment)
+	s.attachReturns(resp)
+	s.attachAnnotations(resp, submission, studentID)
 	return resp, nil
 }
 
 func (s *AssignmentSubmissionService) SaveDraft(assignmentID, studentID uint, req AssignmentSubmissionRequest) (*models.AssignmentSubmissionResponse, error) {
-	if _, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID); err != nil {
+	assignment, err := s.ensureStudentCanAccessAssignment(assignmentID, studentID)
+	if err != nil {
 		return nil, err
 	}
 	if s.getGrade(studentID, assignmentID) != nil {
 		return nil, errors.New("graded submission cannot be changed")
 	}
 
-	existing, lookupErr := s.repo.GetByStudentAndAssignment(studentID, assignmentID)
+	existing, groupID, lookupErr := s.lookupSubmission(assignment, studentID)
 	if lookupErr != nil && !errors.Is(lookupErr, gorm.ErrRecordNotFound) {
 		return nil, lookupErr
 	}
-	if lookupErr == nil && existing.Status != models.SubmissionStatusDraft {
+	if lookupErr == nil && !isEditable(existing) {
 		return nil, errors.New("submitted assignment cannot be changed")
 	}
 
@@ -100,12 +146,22 @@ func (s *AssignmentSubmissionService) SaveDraft(assignmentID, studentID uint, re
 		Answers:      string(answersJSON),
 		Status:       models.SubmissionStatusDraft,
 		WordCount:    wordCount,
+		GroupID:      groupID,
+	}
+	if lookupErr == nil {
+		// Қайтарылған жұмыс қайта тапсырылғанша returned күйінде қалады
+		submission.Status = existing.Status
+		submission.Round = existing.Round
+		submission.ReturnDueDate = existing.ReturnDueDate
 	}
 
 	if errors.Is(lookupErr, gorm.ErrRecordNotFound) {
 		if createErr := s.repo.Create(submission); createErr != nil {
 			return nil, createErr
 		}
+		if _, err := s.recordRevision(submission, studentID, models.RevisionKindDraft); err != nil {
+			return nil, err
+		}
 		submission.CreatedAt = now
 		submission.UpdatedAt = now
 		return toSubmissionResponse(submission, nil), nil
@@ -115,12 +171,18 @@ func (s *AssignmentSubmissionService) SaveDraft(assignmentID, studentID uint, re
 	if updateErr := s.repo.Update(existing.ID, submission); updateErr != nil {
 		return nil, updateErr
 	}
+	if _, err := s.recordRevision(submission, studentID, models.RevisionKindDraft); err != nil {
+		return nil, err
+	}
 
 	updated, err := s.repo.GetByID(existing.ID)
 	if err != nil {
 		return nil, err
 	}
-	return toSubmissionResponse(updated, nil), nil
+	resp := toSubmissionResponse(updated, nil)
+	s.attachFiles(resp, assignment)
+	s.attachReturns(resp)
+	return resp, nil
 }
 
 func (s *AssignmentSubmissionService) Submit(assignmentID, studentID uint, req AssignmentSubmissionRequest) (*models.AssignmentSubmissionResponse, error) {
@@ -132,21 +194,37 @@ func (s *AssignmentSubmissionService) Submit(assignmentID, studentID uint, req A
 		return nil, errors.New("graded submission cannot be changed")
 	}
 
-	existing, lookupErr := s.repo.GetByStudentAndAssignment(studentID, assignmentID)
+	existing, groupID, lookupErr := s.lookupSubmission(assignment, studentID)
 	if lookupErr != nil && !errors.Is(lookupErr, gorm.ErrRecordNotFound) {
 		return nil, lookupErr
 	}
-	if lookupErr == nil && existing.Status != models.SubmissionStatusDraft {
+	if lookupErr == nil && !isEditable(existing) {
 		return nil, errors.New("assignment has already been submitted")
 	}
+	resubmission := lookupErr == nil && existing.Status == models.SubmissionStatusReturned
 
-	if err := s.validateSubmission(assignment, req); err != nil {
+	fileCount := 0
+	if lookupErr == nil {
+		fileCount = s.countFiles(existing)
+	}
+	if err := s.validateSubmission(assignment, req, fileCount); err != nil {
 		return nil, err
 	}
 
 	now := time.Now()
+	extension := s.getExtension(studentID, assignmentID)
+	var dueDate time.Time
+	if resubmission {
+		// Мұғалім қайтарғанда жаңа мерзім береді; close_date бұл жерде қолданылмайды
+		dueDate = submissionDueDate(assignment, extension, existing)
+	} else {
+		if isSubmissionClosed(assignment, extension, now) {
+			return nil, errors.New("submission is closed for this assignment")
+		}
+		dueDate = effectiveDueDate(assignment, extension)
+	}
 	status := models.SubmissionStatusSubmitted
-	if now.After(assignment.DueDate) {
+	if now.After(dueDate) {
 		status = models.SubmissionStatusLate
 	}
 
@@ -163,6 +241,14 @@ func (s *AssignmentSubmissionService) Submit(assignmentID, studentID uint, req A
 		Status:       status,
 		WordCount:    countWords(req.Content),
 		SubmittedAt:  &now,
+		GroupID:      groupID,
+	}
+	if lookupErr == nil {
+		submission.Round = existing.Round
+		submission.ReturnDueDate = existing.ReturnDueDate
+	}
+	if resubmission {
+		submission.Round++
 	}
 
 	if assignment.Type == string(models.AssignmentTypeTest) {
@@ -180,24 +266,63 @@ func (s *AssignmentSubmissionService) Submit(assignmentID, studentID uint, req A
 			return nil, updateErr
 		}
 	}
+	revision, err := s.recordRevision(submission, studentID, models.RevisionKindSubmit)
+	if err != nil {
+		return nil, err
+	}
+	if resubmission {
+		if open, err := s.returnRepo.GetOpen(submission.ID); err == nil {
+			if err := s.returnRepo.MarkResubmitted(open.ID, revision.Number, now); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	var grade *models.Grade
 	if assignment.Type == string(models.AssignmentTypeTest) {
-		grade, err = s.autoGradeTest(assignment, studentID, req.Answers)
+		grade, err = s.autoGradeTest(assignment, studentID, req.Answers, dueDate, now, &revision.Number)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if !assignment.GradesVisible(now) {
+		// тест бағаланды, бірақ нәтиже мұғалім жариялағанда ғана көрінеді
+		grade = nil
+	}
 
-	saved, err := s.repo.GetByStudentAndAssignment(studentID, assignmentID)
+	saved, err := s.repo.GetByID(submission.ID)
 	if err != nil {
 		return nil, err
 	}
 	resp := toSubmissionResponse(saved, grade)
+	if !assignment.GradesVisible(now) {
+		resp.TestResults = nil
+	}
 	s.attachTestReviewIfGraded(resp, assignment)
+	s.attachFiles(resp, assignment)
+	s.attachReturns(resp)
+	if assignment.Type == string(models.AssignmentTypeCode) {
+		resp.GradingJobID = s.enqueueCodeGrade(saved.ID, studentID)
+	}
 	return resp, nil
 }
 
+// enqueueCodeGrade — кезек болмаса жұмыс submitted күйінде қалады (мұғалім қолмен бағалайды).
+func (s *AssignmentSubmissionService) enqueueCodeGrade(submissionID, studentID uint) string {
+	if s.queue == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(CodeGradePayload{SubmissionID: submissionID})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	jobID, err := s.queue.Enqueue(ctx, CodeGradeJobType, json.RawMessage(raw), studentID)
+	if err != nil {
+		log.Printf("[submission] code_grade enqueue failed: %v", err)
+		return ""
+	}
+	return jobID
+}
+
 func (s *AssignmentSubmissionService) ensureStudentCanAccessAssignment(assignmentID, studentID uint) (*models.Assignment, error) {
 	student, err := s.userRepo.GetByID(studentID)
 	if err != nil {
@@ -214,6 +339,9 @@ func (s *AssignmentSubmissionService) ensureStudentCanAccessAssignment(assignmen
 	if !assignment.DeletedAt.Time.IsZero() {
 		return nil, errors.New("assignment was deleted")
 	}
+	if !assignment.IsVisibleToStudents(time.Now()) {
+		return nil, errors.New("assignment not found")
+	}
 
 	courses, err := s.courseRepo.GetCoursesByStudentID(studentID)
 	if err != nil {
@@ -228,7 +356,7 @@ func (s *AssignmentSubmissionService) ensureStudentCanAccessAssignment(assignmen
 	return nil, errors.New("student is not enrolled in this course")
 }
 
-func (s *AssignmentSubmissionService) validateSubmission(assignment *models.Assignment, req AssignmentSubmissionRequest) error {
+func (s *AssignmentSubmissionService) validateSubmission(assignment *models.Assignment, req AssignmentSubmissionRequest, fileCount int) error {
 	switch assignment.Type {
 	case string(models.AssignmentTypeTest):
 		questions, err := parseTestQuestions(assignment.Questions)
@@ -260,6 +388,14 @@ func (s *AssignmentSubmissionService) validateSubmission(assignment *models.Assi
 			}
 			seen[answer.QuestionID] = true
 		}
+	case string(models.AssignmentTypeFile):
+		if fileCount == 0 {
+			return errors.New("at least one file must be uploaded")
+		}
+	case string(models.AssignmentTypeCode):
+		if strings.TrimSpace(req.Content) == "" {
+			return errors.New("source code is required")
+		}
 	default:
 		wordCount := countWords(req.Content)
 		if strings.TrimSpace(req.Content) == "" {
@@ -272,7 +408,7 @@ func (s *AssignmentSubmissionService) validateSubmission(assignment *models.Assi
 	return nil
 }
 
-func (s *AssignmentSubmissionService) autoGradeTest(assignment *models.Assignment, studentID uint, answers []models.TestAnswer) (*models.Grade, error) {
+func (s *AssignmentSubmissionService) autoGradeTest(assignment *models.Assignment, studentID uint, answers []models.TestAnswer, dueDate, submittedAt time.Time, revision *int) (*models.Grade, error) {
 	questions, err := parseTestQuestions(assignment.Questions)
 	if err != nil {
 		return nil, err
@@ -296,16 +432,62 @@ func (s *AssignmentSubmissionService) autoGradeTest(assignment *models.Assignmen
 	}
 	feedback := fmt.Sprintf("Тест автоматты түрде бағаланды: %d/%d дұрыс жауап.", correct, len(questions))
 
+	return s.saveAutoGrade(assignment, studentID, score, feedback, dueDate, submittedAt, revision)
+}
+
+// SaveAutoGrade — автотексеру нәтижесін (code) бағаға айналдырады.
+// Топтық жұмыста баға әр мүшеге беріледі; кешігу айыппұлы шегеріледі.
+func (s *AssignmentSubmissionService) SaveAutoGrade(submissionID uint, score float64, feedback string) error {
+	submission, err := s.repo.GetByID(submissionID)
+	if err != nil {
+		return errors.New("submission not found")
+	}
+	assignment, err := s.assignmentRepo.GetByID(submission.AssignmentID)
+	if err != nil {
+		return errors.New("assignment not found")
+	}
+	submittedAt := time.Now()
+	if submission.SubmittedAt != nil {
+		submittedAt = *submission.SubmittedAt
+	}
+
+	revision := s.latestRevisionNumber(submission.ID)
+	for _, studentID := range s.submissionStudentIDs(submission) {
+		dueDate := submissionDueDate(assignment, s.getExtension(studentID, assignment.ID), submission)
+		if _, err := s.saveAutoGrade(assignment, studentID, score, feedback, dueDate, submittedAt, revision); err != nil {
+			return err
+		}
+	}
+
+	submission.Status = models.SubmissionStatusGraded
+	return s.repo.Update(submission.ID, submission)
+}
+
+func (s *AssignmentSubmissionService) saveAutoGrade(assignment *models.Assignment, studentID uint, score float64, feedback string, dueDate, submittedAt time.Time, revision *int) (*models.Grade, error) {
+	penalty := calcLatePenalty(assignment, dueDate, submittedAt, score)
+	if penalty > 0 {
+		score = math.Round((score-penalty)*100) / 100
+		feedback += fmt.Sprintf(" Кешігу айыппұлы: -%.2f балл.", penalty)
+	}
+
 	existing, err := s.gradeRepo.GetGradeByStudentAndAssignment(studentID, assignment.ID)
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		grade := &models.Grade{
-			StudentID:    studentID,
-			AssignmentID: assignment.ID,
-			Score:        score,
-			Feedback:     feedback,
+			StudentID:      studentID,
+			AssignmentID:   assignment.ID,
+			Score:          score,
+			Feedback:       feedback,
+			LatePenalty:    penalty,
+			RevisionNumber: revision,
 		}
-		if createErr := s.gradeRepo.Create(grade); createErr != nil {
-			return nil, createErr
+		err := s.gradeRepo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+			if err := grades.Create(grade); err != nil {
+				return err
+			}
+			return recordGradeChange(history, nil, grade, autoGradeReason, nil)
+		})
+		if err != nil {
+			return nil, err
 		}
 		return grade, nil
 	}
@@ -313,14 +495,43 @@ func (s *AssignmentSubmissionService) autoGradeTest(assignment *models.Assignmen
 		return nil, err
 	}
 
+	before := gradeSnapshot(existing)
 	existing.Score = score
 	existing.Feedback = feedback
-	if err := s.gradeRepo.Update(existing.ID, existing); err != nil {
+	existing.LatePenalty = penalty
+	existing.RevisionNumber = revision
+	err = s.gradeRepo.Transaction(func(grades repository.GradeRepository, history repository.GradeHistoryRepository) error {
+		if err := grades.Update(existing.ID, existing); err != nil {
+			return err
+		}
+		return recordGradeChange(history, before, existing, autoGradeReason, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return s.gradeRepo.GetByID(existing.ID)
 }
 
+// lookupSubmission — жеке немесе топтың ортақ жұмысын табады.
+// Топтық тапсырмада жұмысты топтың кез келген мүшесі өңдей алады.
+func (s *AssignmentSubmissionService) lookupSubmission(assignment *models.Assignment, studentID uint) (*models.AssignmentSubmission, *uint, error) {
+	if !assignment.IsGroupAssignment() {
+		submission, err := s.repo.GetByStudentAndAssignment(studentID, assignment.ID)
+		return submission, nil, err
+	}
+
+	group, err := s.groupRepo.GetByStudentAndAssignment(studentID, assignment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, errNoGroup
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	groupID := group.ID
+	submission, err := s.repo.GetByGroupAndAssignment(groupID, assignment.ID)
+	return submission, &groupID, err
+}
+
 func (s *AssignmentSubmissionService) getGrade(studentID, assignmentID uint) *models.Grade {
 	grade, err := s.gradeRepo.GetGradeByStudentAndAssignment(studentID, assignmentID)
 	if err != nil {
@@ -329,6 +540,22 @@ func (s *AssignmentSubmissionService) getGrade(studentID, assignmentID uint) *mo
 	return grade
 }
 
+// visibleGrade — студентке көрінетін баға; бағалар әлі жарияланбаса nil.
+func (s *AssignmentSubmissionService) visibleGrade(assignment *models.Assignment, studentID uint) *models.Grade {
+	if !assignment.GradesVisible(time.Now()) {
+		return nil
+	}
+	return s.getGrade(studentID, assignment.ID)
+}
+
+func (s *AssignmentSubmissionService) getExtension(studentID, assignmentID uint) *models.AssignmentExtension {
+	extension, err := s.extensionRepo.GetByStudentAndAssignment(studentID, assignmentID)
+	if err != nil {
+		return nil
+	}
+	return extension
+}
+
 func parseTestQuestions(raw string) ([]models.TestQuestion, error) {
 	if raw == "" || raw == "null" {
 		return nil, nil
@@ -358,17 +585,21 @@ func parseTestAnswers(raw string) []models.TestAnswer {
 
 func toSubmissionResponse(submission *models.AssignmentSubmission, grade *models.Grade) *models.AssignmentSubmissionResponse {
 	return &models.AssignmentSubmissionResponse{
-		ID:           submission.ID,
-		StudentID:    submission.StudentID,
-		AssignmentID: submission.AssignmentID,
-		Content:      submission.Content,
-		Answers:      parseTestAnswers(submission.Answers),
-		Status:       responseStatus(submission.Status, grade),
-		WordCount:    submission.WordCount,
-		SubmittedAt:  submission.SubmittedAt,
-		CreatedAt:    submission.CreatedAt,
-		UpdatedAt:    submission.UpdatedAt,
-		Grade:        grade,
+		ID:            submission.ID,
+		StudentID:     submission.StudentID,
+		AssignmentID:  submission.AssignmentID,
+		Content:       submission.Content,
+		Answers:       parseTestAnswers(submission.Answers),
+		Status:        responseStatus(submission.Status, grade),
+		WordCount:     submission.WordCount,
+		SubmittedAt:   submission.SubmittedAt,
+		GroupID:       submission.GroupID,
+		Round:         submission.Round,
+		ReturnDueDate: submission.ReturnDueDate,
+		TestResults:   parseCodeTestResults(submission.TestResults),
+		CreatedAt:     submission.CreatedAt,
+		UpdatedAt:     submission.UpdatedAt,
+		Grade:         grade,
 	}
 }
 
@@ -376,6 +607,10 @@ func responseStatus(status string, grade *models.Grade) string {
 	if grade != nil {
 		return models.SubmissionStatusGraded
 	}
+	if status == models.SubmissionStatusGraded {
+		// баға жасырылған (жарияланбаған) — студент үшін жұмыс тек тапсырылған
+		return models.SubmissionStatusSubmitted
+	}
 	if status == "" {
 		return models.SubmissionStatusDraft
 	}
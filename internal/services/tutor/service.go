@@ -13,10 +13,10 @@ import (
 
 type Service struct {
 	db     *gorm.DB
-	client *ai.OpenRouterClient
+	client ai.Provider
 }
 
-func NewService(db *gorm.DB, client *ai.OpenRouterClient) *Service {
+func NewService(db *gorm.DB, client ai.Provider) *Service {
 	return &Service{db: db, client: client}
 }
 
@@ -113,11 +113,23 @@ func (s *Service) BuildMessages(assignmentID uint, history []models.TutorMessage
 	return msgs, nil
 }
 
-// Stream — OpenRouter SSE stream → ch арналы арқылы delta жіберу
-func (s *Service) Stream(ctx context.Context, msgs []ai.Message, ch chan<- string) error {
+// CheckQuota — SSE ашылмай тұрып студенттің AI лимитін тексеру
+func (s *Service) CheckQuota(studentID, assignmentID uint) error {
 	if s.client == nil {
-		ch <- "AI қызметі өшірілген (OPENROUTER_API_KEY жоқ)"
 		return nil
 	}
-	return s.client.ChatStream(ctx, msgs, ch)
+	return ai.CheckQuota(s.client, tutorCall(studentID, assignmentID))
+}
+
+// Stream — LLM провайдер stream → ch арналы арқылы delta жіберу
+func (s *Service) Stream(ctx context.Context, studentID, assignmentID uint, msgs []ai.Message, ch chan<- string) error {
+	if s.client == nil {
+		ch <- "AI қызметі өшірілген (LLM провайдері бапталмаған)"
+		return nil
+	}
+	return s.client.ChatStream(ai.WithCall(ctx, tutorCall(studentID, assignmentID)), msgs, ch)
+}
+
+func tutorCall(studentID, assignmentID uint) ai.CallInfo {
+	return ai.CallInfo{UserID: studentID, Feature: ai.FeatureTutor, AssignmentID: assignmentID}
 }
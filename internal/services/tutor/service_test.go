@@ -0,0 +1,58 @@
+package tutor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"rest-project/internal/services/ai"
+)
+
+// Stream деректер қорына жүгінбейді — mock провайдердің жауабы бөліктермен арнаға келуі керек.
+func TestStreamDeliversMockReply(t *testing.T) {
+	mock := ai.NewMockProvider(
+		ai.MockRule{Contains: "recursion", Reply: "What happens when the function calls itself with a smaller input?"},
+		ai.MockRule{Reply: "Could you tell me more?"},
+	)
+	svc := NewService(nil, mock)
+	if err := svc.CheckQuota(3, 5); err != nil {
+		t.Fatalf("CheckQuota: %v", err)
+	}
+
+	msgs := []ai.Message{
+		{Role: "system", Content: "You are a helpful AI tutor for students."},
+		{Role: "user", Content: "How does recursion work?"},
+	}
+	ch := make(chan string, 64)
+	if err := svc.Stream(context.Background(), 3, 5, msgs, ch); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	close(ch)
+
+	var parts []string
+	for part := range ch {
+		parts = append(parts, part)
+	}
+	if got := strings.Join(parts, ""); got != "What happens when the function calls itself with a smaller input?" {
+		t.Errorf("reply = %q", got)
+	}
+	if len(parts) < 2 {
+		t.Errorf("reply must be streamed in parts, got %d", len(parts))
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 1 || len(calls[0]) != 2 || calls[0][1].Content != "How does recursion work?" {
+		t.Errorf("unexpected provider calls: %+v", calls)
+	}
+}
+
+func TestStreamWithoutProvider(t *testing.T) {
+	svc := NewService(nil, nil)
+	ch := make(chan string, 1)
+	if err := svc.Stream(context.Background(), 3, 5, nil, ch); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if msg := <-ch; msg == "" {
+		t.Error("expected a notice that AI is disabled")
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "strings"
+
+// csvFormulaPrefixes — с этих символов Excel/LibreOffice начинают формулу
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// CSVSafe защищает ячейку CSV от инъекции формул: значение, начинающееся
+// с =, +, -, @, табуляции или CR, получает в начало апостроф.
+func CSVSafe(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// CSVUnescape снимает апостроф, добавленный CSVSafe (для импорта выгруженных файлов).
+func CSVUnescape(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}